@@ -2,65 +2,424 @@ package main
 
 import (
 	"context"
-	"fullcycle-auction_go/configuration/database/mongodb"
+	"errors"
+	"flag"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/telemetry"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
 	"fullcycle-auction_go/internal/infra/api/web/controller/auction_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/auth_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/bid_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/notification_controller"
+	"fullcycle-auction_go/internal/infra/api/web/controller/settlement_controller"
 	"fullcycle-auction_go/internal/infra/api/web/controller/user_controller"
-	"fullcycle-auction_go/internal/infra/database/auction"
-	"fullcycle-auction_go/internal/infra/database/bid"
-	"fullcycle-auction_go/internal/infra/database/user"
+	"fullcycle-auction_go/internal/infra/api/web/controller/webhook_controller"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/auth"
+	"fullcycle-auction_go/internal/infra/blobstore"
+	"fullcycle-auction_go/internal/infra/notification_channel"
+	"fullcycle-auction_go/internal/infra/wal"
+	"fullcycle-auction_go/internal/infra/webhook_dispatcher"
+	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"fullcycle-auction_go/internal/usecase/auth_usecase"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"fullcycle-auction_go/internal/usecase/notification_usecase"
+	"fullcycle-auction_go/internal/usecase/settlement_usecase"
 	"fullcycle-auction_go/internal/usecase/user_usecase"
+	"fullcycle-auction_go/internal/usecase/webhook_usecase"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.uber.org/zap"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
 )
 
 func main() {
 	ctx := context.Background()
 
-	if err := godotenv.Load("cmd/auction/.env"); err != nil {
-		log.Fatal("Error trying to load env variables")
+	cfg, args, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal("Invalid configuration:\n" + err.Error())
+		return
+	}
+
+	if len(args) > 0 && args[0] == "config" {
+		printConfig(cfg)
+		return
+	}
+
+	if len(args) > 0 && args[0] == "migrate" {
+		migrator, err := newMigrator(ctx, cfg)
+		if err != nil {
+			log.Fatal(err.Error())
+			return
+		}
+
+		runMigrateCommand(ctx, migrator, args[1:])
+		return
+	}
+
+	logConfig(cfg)
+
+	telemetryProvider, err := telemetry.NewProvider(ctx, cfg.Telemetry.ServiceName, cfg.Telemetry.OTLPEndpoint)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	repositories, err := newRepositories(ctx, cfg)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	tokenManager := auth.NewTokenManager(cfg.Auth.JWTSecret, cfg.Auth.JWTExpiration)
+
+	bidWAL, err := wal.NewBidWAL(cfg.Bid.WALPath, wal.SyncPolicy(cfg.Bid.WALSync), cfg.Bid.WALSyncInterval)
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	blobStore, err := blobstore.NewBlobStore(cfg.Attachment.BlobStore, cfg.Attachment.BlobStorePath)
+	if err != nil {
+		log.Fatal(err.Error())
 		return
 	}
 
-	databaseConnection, err := mongodb.NewMongoDBConnection(ctx)
+	notificationChannels, err := notification_channel.NewChannels(notification_channel.Options{
+		Names:          cfg.Notification.Channels,
+		SMTPAddr:       cfg.Notification.SMTPAddr,
+		SMTPFrom:       cfg.Notification.SMTPFrom,
+		SMTPUsername:   cfg.Notification.SMTPUsername,
+		SMTPPassword:   cfg.Notification.SMTPPassword,
+		WebhookURL:     cfg.Notification.WebhookURL,
+		WebhookSecret:  cfg.Notification.WebhookSecret,
+		WebhookTimeout: cfg.Webhook.Timeout,
+	})
 	if err != nil {
 		log.Fatal(err.Error())
 		return
 	}
 
+	deps := initDependencies(cfg, repositories, tokenManager, bidWAL, blobStore, notificationChannels)
+	if err := auction_usecase.ObserveActiveAuctions(repositories.auction); err != nil {
+		logger.Error("Error trying to register the active auctions metric", err)
+	}
+
+	repositories.start(ctx)
+	seedAdminUser(ctx, deps.userRepository, cfg.Auth.AdminEmail, cfg.Auth.AdminPassword)
+	deps.webhookDispatcher.Start(ctx)
+	deps.notificationUseCase.Start(ctx)
+
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler: newRouter(deps, tokenManager, telemetryProvider, cfg.Server.RequestTimeout),
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err.Error())
+		}
+	}()
+
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-signalCtx.Done()
+
+	shutdown(server, deps, repositories, telemetryProvider, cfg.Server.ShutdownTimeout)
+}
+
+// logConfig logs the effective value of every setting and where it came
+// from, with the secrets masked.
+func logConfig(cfg *config.Config) {
+	entries := cfg.Entries()
+	fields := make([]zap.Field, 0, len(entries))
+	for _, entry := range entries {
+		fields = append(fields, zap.String(entry.Key, fmt.Sprintf("%s (%s)", entry.Value, entry.Source)))
+	}
+
+	logger.Info("Effective configuration", fields...)
+}
+
+// printConfig handles the "config" command, which prints the effective
+// configuration without starting the server.
+func printConfig(cfg *config.Config) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, entry := range cfg.Entries() {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", entry.Key, entry.Value, entry.Source)
+	}
+	writer.Flush()
+}
+
+func newRouter(
+	deps *dependencies,
+	tokenManager *auth.TokenManager,
+	telemetryProvider *telemetry.Provider,
+	requestTimeout time.Duration) *gin.Engine {
 	router := gin.Default()
+	router.Use(
+		otelgin.Middleware(telemetryProvider.ServiceName),
+		middleware.RequestId(),
+		middleware.Timeout(requestTimeout))
+
+	router.GET("/metrics", gin.WrapH(telemetryProvider.MetricsHandler()))
+
+	authenticated := middleware.Authenticate(tokenManager, deps.userRepository)
+	sellerOrAdmin := middleware.RequireRoles(user_entity.Seller, user_entity.Admin)
+	adminOnly := middleware.RequireRoles(user_entity.Admin)
 
-	userController, bidController, auctionsController := initDependencies(databaseConnection)
+	router.POST("/login", deps.authController.Login)
 
-	router.GET("/auction", auctionsController.FindAuctions)
-	router.GET("/auction/:auctionId", auctionsController.FindAuctionById)
-	router.POST("/auction", auctionsController.CreateAuction)
-	router.GET("/auction/winner/:auctionId", auctionsController.FindWinningBidByAuctionId)
-	router.POST("/bid", bidController.CreateBid)
-	router.GET("/bid/:auctionId", bidController.FindBidByAuctionId)
-	router.GET("/user/:userId", userController.FindUserById)
+	router.GET("/auction", deps.auctionController.FindAuctions)
+	router.GET("/auction/search", deps.auctionController.SearchAuctions)
+	router.GET("/auction/:auctionId", deps.auctionController.FindAuctionById)
+	router.POST("/auction", authenticated, sellerOrAdmin, deps.auctionController.CreateAuction)
+	router.PATCH("/auction/:auctionId", authenticated, sellerOrAdmin, deps.auctionController.UpdateAuction)
+	router.POST("/auction/:auctionId/cancel", authenticated, sellerOrAdmin, deps.auctionController.CancelAuction)
+	router.POST("/auction/:auctionId/attachments", authenticated, sellerOrAdmin, deps.auctionController.UploadAttachment)
+	router.GET("/auction/:auctionId/attachments/:attachmentId", deps.auctionController.FindAttachment)
+	router.GET("/auction/:auctionId/attachments/:attachmentId/thumbnail", deps.auctionController.FindAttachmentThumbnail)
+	router.DELETE("/auction/:auctionId/attachments/:attachmentId",
+		authenticated, sellerOrAdmin, deps.auctionController.DeleteAttachment)
+	router.GET("/auction/:auctionId/result", deps.settlementController.FindAuctionResult)
+	router.GET("/auction/winner/:auctionId", deps.auctionController.FindWinningBidByAuctionId)
+	router.POST("/bid", authenticated, deps.bidController.CreateBid)
+	router.POST("/bid/proxy", authenticated, deps.bidController.CreateProxyBid)
+	router.GET("/bid/:auctionId", deps.bidController.FindBidByAuctionId)
+	router.GET("/user", authenticated, adminOnly, deps.userController.FindUsers)
+	router.POST("/user", middleware.OptionalAuthenticate(tokenManager, deps.userRepository), deps.userController.CreateUser)
+	router.GET("/user/:userId", authenticated, deps.userController.FindUserById)
+	router.PUT("/user/:userId", authenticated, deps.userController.UpdateUser)
+	router.POST("/user/:userId/deactivate", authenticated, deps.userController.DeactivateUser)
+	router.GET("/user/:userId/bids", authenticated, deps.bidController.FindBidsByUserId)
+	router.GET("/user/:userId/auctions/won", authenticated, deps.settlementController.FindAuctionsWon)
+	router.GET("/user/:userId/watchlist", authenticated, deps.notificationController.FindWatchlist)
+	router.POST("/user/:userId/watchlist", authenticated, deps.notificationController.AddToWatchlist)
+	router.DELETE("/user/:userId/watchlist/:auctionId", authenticated, deps.notificationController.RemoveFromWatchlist)
+	router.GET("/user/:userId/notifications", authenticated, deps.notificationController.FindNotifications)
+	router.GET("/webhook", authenticated, adminOnly, deps.webhookController.FindWebhooks)
+	router.POST("/webhook", authenticated, adminOnly, deps.webhookController.CreateWebhook)
+	router.DELETE("/webhook/:webhookId", authenticated, adminOnly, deps.webhookController.DeleteWebhook)
+	router.GET("/webhook/dead-letters", authenticated, adminOnly, deps.webhookController.FindDeadLetters)
+	router.POST("/webhook/deliveries/:deliveryId/retry", authenticated, adminOnly, deps.webhookController.RetryDelivery)
 
-	router.Run(":8080")
+	return router
 }
 
-func initDependencies(database *mongo.Database) (
-	userController *user_controller.UserController,
-	bidController *bid_controller.BidController,
-	auctionController *auction_controller.AuctionController) {
+// shutdown stops taking requests, waits for in-flight ones, flushes the bids
+// still buffered for batch insertion and stops the webhook dispatcher and the
+// notifications before disconnecting from the database. Telemetry is flushed
+// last so the spans of the shutdown itself are exported.
+func shutdown(server *http.Server,
+	deps *dependencies, repositories *repositories, telemetryProvider *telemetry.Provider,
+	timeout time.Duration) {
+	logger.Info("Shutting down")
 
-	auctionRepository := auction.NewAuctionRepository(database)
-	bidRepository := bid.NewBidRepository(database, auctionRepository)
-	userRepository := user.NewUserRepository(database)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	userController = user_controller.NewUserController(
-		user_usecase.NewUserUseCase(userRepository))
-	auctionController = auction_controller.NewAuctionController(
-		auction_usecase.NewAuctionUseCase(auctionRepository, bidRepository))
-	bidController = bid_controller.NewBidController(bid_usecase.NewBidUseCase(bidRepository))
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("Error trying to shut down the HTTP server", err)
+	}
+
+	if err := deps.bidUseCase.Shutdown(ctx); err != nil {
+		logger.Error("Error trying to flush pending bids", err)
+	}
+
+	if err := deps.bidWAL.Close(); err != nil {
+		logger.Error("Error trying to close the bid write-ahead log", err)
+	}
+
+	if err := deps.webhookDispatcher.Shutdown(ctx); err != nil {
+		logger.Error("Error trying to stop the webhook dispatcher", err)
+	}
+
+	if err := deps.notificationUseCase.Shutdown(ctx); err != nil {
+		logger.Error("Error trying to send pending notifications", err)
+	}
+
+	if err := repositories.close(ctx); err != nil {
+		logger.Error("Error trying to disconnect from the database", err)
+	}
+
+	if err := telemetryProvider.Shutdown(ctx); err != nil {
+		logger.Error("Error trying to flush telemetry", err)
+	}
+
+	logger.Info("Shutdown complete")
+}
+
+type dependencies struct {
+	userController         *user_controller.UserController
+	bidController          *bid_controller.BidController
+	auctionController      *auction_controller.AuctionController
+	authController         *auth_controller.AuthController
+	settlementController   *settlement_controller.SettlementController
+	webhookController      *webhook_controller.WebhookController
+	notificationController *notification_controller.NotificationController
+	webhookDispatcher      *webhook_dispatcher.Dispatcher
+	notificationUseCase    notification_usecase.NotificationUseCaseInterface
+	bidUseCase             bid_usecase.BidUseCaseInterface
+	bidWAL                 *wal.BidWAL
+	userRepository         user_entity.UserRepositoryInterface
+}
+
+func initDependencies(
+	cfg *config.Config,
+	repositories *repositories,
+	tokenManager *auth.TokenManager,
+	bidWAL *wal.BidWAL,
+	blobStore attachment_entity.BlobStore,
+	notificationChannels []notification_entity.Channel) *dependencies {
+	notificationUseCase := notification_usecase.NewNotificationUseCase(
+		repositories.auction, repositories.bid, repositories.user,
+		repositories.watchlist, repositories.notification, notificationChannels,
+		notification_usecase.Options{
+			ScanInterval:     cfg.Notification.ScanInterval,
+			EndingSoonWindow: cfg.Notification.EndingSoonWindow,
+			QueueSize:        cfg.Notification.QueueSize,
+		})
+
+	settlementUseCase := settlement_usecase.NewSettlementUseCase(
+		repositories.auction, repositories.settlement)
+	settlementUseCase.OnAuctionClosed(logAuctionClosed)
+	settlementUseCase.OnAuctionClosed(notificationUseCase.NotifyAuctionClosed)
+	repositories.auction.OnStatusChange(func(auctionId string, status auction_entity.AuctionStatus) {
+		if status != auction_entity.Completed {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(
+			logger.WithAuctionId(context.Background(), auctionId), cfg.Server.SettlementTimeout)
+		defer cancel()
+
+		if _, err := settlementUseCase.SettleAuction(ctx, auctionId); err != nil {
+			logger.ErrorContext(ctx, "Error trying to settle auction", err)
+		}
+	})
+
+	bidUseCase := bid_usecase.NewBidUseCase(
+		repositories.bid, repositories.auction, repositories.proxyBid, repositories.user, bidWAL,
+		bid_usecase.Options{
+			BatchInsertInterval: cfg.Bid.BatchInsertInterval,
+			MaxBatchSize:        cfg.Bid.MaxBatchSize,
+			BatchTimeout:        cfg.Bid.BatchTimeout,
+			MinBidIncrement:     cfg.Bid.MinIncrement,
+		})
+	bidUseCase.OnOutbid(notificationUseCase.NotifyOutbid)
+
+	return &dependencies{
+		userController: user_controller.NewUserController(
+			user_usecase.NewUserUseCase(repositories.user)),
+		auctionController: auction_controller.NewAuctionController(
+			auction_usecase.NewAuctionUseCase(
+				repositories.auction, repositories.bid, repositories.attachment, blobStore,
+				auction_usecase.AttachmentLimits{
+					MaxImageSize:    cfg.Attachment.MaxImageSize,
+					MaxDocumentSize: cfg.Attachment.MaxDocumentSize,
+					MaxPerAuction:   cfg.Attachment.MaxPerAuction,
+				})),
+		bidController: bid_controller.NewBidController(bidUseCase),
+		authController: auth_controller.NewAuthController(
+			auth_usecase.NewAuthUseCase(repositories.user, tokenManager)),
+		settlementController: settlement_controller.NewSettlementController(settlementUseCase),
+		webhookController: webhook_controller.NewWebhookController(
+			webhook_usecase.NewWebhookUseCase(repositories.subscription, repositories.delivery)),
+		notificationController: notification_controller.NewNotificationController(notificationUseCase),
+		webhookDispatcher: webhook_dispatcher.NewDispatcher(
+			repositories.outbox, repositories.subscription, repositories.delivery,
+			webhook_dispatcher.Options{
+				Timeout: cfg.Webhook.Timeout,
+				RetryPolicy: webhook_entity.RetryPolicy{
+					MaxAttempts: cfg.Webhook.MaxAttempts,
+					BaseDelay:   cfg.Webhook.RetryBaseDelay,
+					MaxDelay:    cfg.Webhook.RetryMaxDelay,
+				},
+				PollInterval: cfg.Webhook.PollInterval,
+			}),
+		notificationUseCase: notificationUseCase,
+		bidUseCase:          bidUseCase,
+		bidWAL:              bidWAL,
+		userRepository:      repositories.user,
+	}
+}
 
-	return
+func logAuctionClosed(event settlement_entity.AuctionClosedEvent) {
+	logger.Info("Auction closed",
+		zap.String("auction_id", event.AuctionId),
+		zap.String("outcome", string(event.Outcome)),
+		zap.String("winner_id", event.WinnerId),
+		zap.Float64("final_price", event.FinalPrice),
+		zap.Int64("bid_count", event.BidCount))
+}
+
+// seedAdminUser creates the first admin from ADMIN_EMAIL and ADMIN_PASSWORD so
+// a fresh database can be managed through the API.
+func seedAdminUser(
+	ctx context.Context, userRepository user_entity.UserRepositoryInterface, email, password string) {
+	if email == "" || password == "" {
+		return
+	}
+
+	if _, err := userRepository.FindUserByEmail(ctx, email); err == nil {
+		return
+	} else if err.Code != internal_error.NotFound {
+		logger.ErrorContext(ctx, "Error trying to find admin user", err)
+		return
+	}
+
+	adminUser, err := user_entity.CreateUser("Admin", email, password, user_entity.Admin)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create admin user", err)
+		return
+	}
+
+	if err := userRepository.CreateUser(ctx, adminUser); err != nil {
+		logger.ErrorContext(ctx, "Error trying to create admin user", err)
+		return
+	}
+
+	logger.InfoContext(ctx, "Admin user created", zap.String("email", adminUser.Email))
+}
+
+// runMigrateCommand handles "migrate" (apply pending migrations) and
+// "migrate status" (list every migration and whether it was applied).
+func runMigrateCommand(ctx context.Context, migrator migrator, args []string) {
+	if len(args) > 0 && args[0] == "status" {
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%3d  %-20s %s\n", status.Version, appliedAt, status.Description)
+		}
+		return
+	}
+
+	if err := migrator.Run(ctx); err != nil {
+		log.Fatal(err.Error())
+	}
+	fmt.Println("migrations applied")
 }
@@ -0,0 +1,630 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/telemetry"
+	"fullcycle-auction_go/internal/infra/auth"
+	"fullcycle-auction_go/internal/infra/blobstore"
+	"fullcycle-auction_go/internal/infra/wal"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testClient struct {
+	t          *testing.T
+	server     *httptest.Server
+	adminToken string
+}
+
+const (
+	testAdminEmail    = "admin@example.com"
+	testAdminPassword = "admin-password"
+)
+
+// newTestClient serves the whole API over the in-memory repositories, with
+// short auctions, every bid stored on its own and a seeded admin. configure
+// adjusts the configuration further.
+func newTestClient(t *testing.T, configure ...func(cfg *config.Config)) *testClient {
+	cfg := config.Default()
+	cfg.Database.Driver = "memory"
+	cfg.Auction.Interval = 2 * time.Second
+	cfg.Bid.BatchInsertInterval = 20 * time.Millisecond
+	cfg.Bid.MaxBatchSize = 1
+	for _, configure := range configure {
+		configure(cfg)
+	}
+	gin.SetMode(gin.TestMode)
+
+	repositories, err := newRepositories(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	bidWAL, err := wal.NewBidWAL(filepath.Join(t.TempDir(), "bids.wal"), wal.SyncNone, time.Second)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	telemetryProvider, err := telemetry.NewProvider(context.Background(), cfg.Telemetry.ServiceName, "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	blobStore, err := blobstore.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tokenManager := auth.NewTokenManager("test-secret-with-at-least-32-characters", time.Hour)
+	deps := initDependencies(cfg, repositories, tokenManager, bidWAL, blobStore, nil)
+	if err := auction_usecase.ObserveActiveAuctions(repositories.auction); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	repositories.start(context.Background())
+	seedAdminUser(context.Background(), deps.userRepository, testAdminEmail, testAdminPassword)
+	deps.notificationUseCase.Start(context.Background())
+	server := httptest.NewServer(newRouter(deps, tokenManager, telemetryProvider, cfg.Server.RequestTimeout))
+
+	t.Cleanup(func() {
+		server.Close()
+		deps.bidUseCase.Shutdown(context.Background())
+		bidWAL.Close()
+		deps.notificationUseCase.Shutdown(context.Background())
+		repositories.close(context.Background())
+		telemetryProvider.Shutdown(context.Background())
+	})
+
+	client := &testClient{t: t, server: server}
+	client.adminToken = client.login(testAdminEmail, testAdminPassword)
+
+	return client
+}
+
+func (tc *testClient) do(method, path, token string, body interface{}, out interface{}) int {
+	tc.t.Helper()
+
+	var reader bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&reader).Encode(body)
+	}
+
+	request, _ := http.NewRequest(method, tc.server.URL+path, &reader)
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		tc.t.Fatalf("Unexpected error calling %s %s: %v", method, path, err)
+	}
+	defer response.Body.Close()
+
+	if out != nil {
+		json.NewDecoder(response.Body).Decode(out)
+	}
+	return response.StatusCode
+}
+
+// upload sends content as the "file" field of a multipart form.
+func (tc *testClient) upload(path, token, fileName string, content []byte, out interface{}) int {
+	tc.t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write(content)
+	writer.Close()
+
+	request, _ := http.NewRequest(http.MethodPost, tc.server.URL+path, &body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		tc.t.Fatalf("Unexpected error uploading to %s: %v", path, err)
+	}
+	defer response.Body.Close()
+
+	if out != nil {
+		json.NewDecoder(response.Body).Decode(out)
+	}
+	return response.StatusCode
+}
+
+// createUser registers a user and returns its id and access token. Bidders
+// sign up on their own; other roles are created by the seeded admin.
+func (tc *testClient) createUser(name, email, role string) (string, string) {
+	tc.t.Helper()
+
+	var token string
+	if role != "bidder" {
+		token = tc.adminToken
+	}
+
+	var user struct {
+		Id string `json:"id"`
+	}
+	input := map[string]string{"name": name, "email": email, "password": "password123", "role": role}
+	if status := tc.do(http.MethodPost, "/user", token, input, &user); status != http.StatusCreated {
+		tc.t.Fatalf("Expected user %s to be created, got status %d", email, status)
+	}
+
+	return user.Id, tc.login(email, "password123")
+}
+
+// login returns an access token for the given credentials.
+func (tc *testClient) login(email, password string) string {
+	tc.t.Helper()
+
+	var login struct {
+		AccessToken string `json:"access_token"`
+	}
+	credentials := map[string]string{"email": email, "password": password}
+	if status := tc.do(http.MethodPost, "/login", "", credentials, &login); status != http.StatusOK {
+		tc.t.Fatalf("Expected user %s to log in, got status %d", email, status)
+	}
+
+	return login.AccessToken
+}
+
+func TestAuctionLifecycleInMemory(t *testing.T) {
+	client := newTestClient(t)
+
+	_, sellerToken := client.createUser("Seller", "seller@example.com", "seller")
+	aliceId, aliceToken := client.createUser("Alice", "alice@example.com", "bidder")
+	bobId, bobToken := client.createUser("Bob", "bob@example.com", "bidder")
+
+	for _, token := range []string{"", aliceToken} {
+		input := map[string]string{"name": "Mallory", "email": "mallory@example.com", "password": "password123", "role": "seller"}
+		if status := client.do(http.MethodPost, "/user", token, input, nil); status != http.StatusForbidden {
+			t.Errorf("Expected only admins to create sellers, got status %d", status)
+		}
+	}
+
+	status := client.do(http.MethodPost, "/auction", sellerToken, map[string]interface{}{
+		"product_name": "Guitar",
+		"category":     "Music",
+		"description":  "Vintage electric guitar",
+		"condition":    1,
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected the auction to be created, got status %d", status)
+	}
+
+	var page struct {
+		Items []struct {
+			Id string `json:"id"`
+		} `json:"items"`
+	}
+	client.do(http.MethodGet, "/auction", "", nil, &page)
+	if len(page.Items) != 1 {
+		t.Fatalf("Expected one auction, got %d", len(page.Items))
+	}
+	auctionId := page.Items[0].Id
+
+	bids := []struct {
+		token  string
+		amount float64
+	}{
+		{aliceToken, 100},
+		{bobToken, 150},
+		{aliceToken, 120},
+	}
+	for _, bid := range bids {
+		status := client.do(http.MethodPost, "/bid", bid.token, map[string]interface{}{
+			"auction_id": auctionId, "amount": bid.amount,
+		}, nil)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected the bid of %.2f to be accepted, got status %d", bid.amount, status)
+		}
+	}
+
+	var result struct {
+		Outcome    string  `json:"outcome"`
+		WinnerId   string  `json:"winner_id"`
+		FinalPrice float64 `json:"final_price"`
+		BidCount   int64   `json:"bid_count"`
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.do(http.MethodGet, "/auction/"+auctionId+"/result", "", nil, &result) != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the auction to close and be settled")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if result.Outcome != "sold" || result.WinnerId != bobId || result.FinalPrice != 150 || result.BidCount != 2 {
+		t.Errorf("Expected Bob to win at 150 with 2 bids, got %+v", result)
+	}
+
+	var winner struct {
+		Bid struct {
+			UserId string  `json:"user_id"`
+			Amount float64 `json:"amount"`
+		} `json:"bid"`
+	}
+	client.do(http.MethodGet, "/auction/winner/"+auctionId, "", nil, &winner)
+	if winner.Bid.UserId != bobId || winner.Bid.Amount != 150 {
+		t.Errorf("Expected the winning bid to be Bob's 150, got %+v", winner.Bid)
+	}
+
+	type bidPage struct {
+		Items []struct {
+			UserId   string  `json:"user_id"`
+			UserName string  `json:"user_name"`
+			Amount   float64 `json:"amount"`
+		} `json:"items"`
+		Stats struct {
+			Count         int64   `json:"count"`
+			Bidders       int64   `json:"bidders"`
+			HighestAmount float64 `json:"highest_amount"`
+		} `json:"stats"`
+		NextCursor string `json:"next_cursor"`
+	}
+
+	var history bidPage
+	client.do(http.MethodGet, "/bid/"+auctionId+"?order=asc&limit=1", "", nil, &history)
+	if len(history.Items) != 1 || history.NextCursor == "" {
+		t.Fatalf("Expected a first page of 1 bid with a cursor, got %+v", history)
+	}
+	if history.Stats.Count != 2 || history.Stats.Bidders != 2 || history.Stats.HighestAmount != 150 {
+		t.Errorf("Expected stats over the 2 accepted bids of 2 bidders, got %+v", history.Stats)
+	}
+	for _, item := range history.Items {
+		if (item.UserId == aliceId) != (item.UserName == "Alice") {
+			t.Errorf("Expected each bid to carry the name of its bidder, got %+v", item)
+		}
+	}
+
+	var nextHistory bidPage
+	client.do(http.MethodGet, "/bid/"+auctionId+"?order=asc&limit=1&cursor="+history.NextCursor, "", nil, &nextHistory)
+	if len(nextHistory.Items) != 1 || nextHistory.NextCursor != "" || nextHistory.Items[0].UserId == history.Items[0].UserId {
+		t.Errorf("Expected the last page to hold the other bid, got %+v", nextHistory)
+	}
+	for _, item := range nextHistory.Items {
+		if (item.UserId == aliceId) != (item.UserName == "Alice") {
+			t.Errorf("Expected each bid to carry the name of its bidder, got %+v", item)
+		}
+	}
+
+	var aliceBids bidPage
+	client.do(http.MethodGet, "/user/"+aliceId+"/bids", aliceToken, nil, &aliceBids)
+	if len(aliceBids.Items) != 1 || aliceBids.Stats.Count != 1 || aliceBids.Stats.HighestAmount != 100 {
+		t.Errorf("Expected the accepted bid of Alice, got %+v", aliceBids)
+	}
+	if status := client.do(http.MethodGet, "/user/"+aliceId+"/bids", bobToken, nil, nil); status != http.StatusForbidden {
+		t.Errorf("Expected Bob not to read the bids of Alice, got status %d", status)
+	}
+
+	var won struct {
+		Items []struct {
+			AuctionId   string `json:"auction_id"`
+			ProductName string `json:"product_name"`
+		} `json:"items"`
+		Stats struct {
+			Count      int64   `json:"count"`
+			TotalSpent float64 `json:"total_spent"`
+		} `json:"stats"`
+	}
+	client.do(http.MethodGet, "/user/"+bobId+"/auctions/won", bobToken, nil, &won)
+	if len(won.Items) != 1 || won.Items[0].AuctionId != auctionId || won.Items[0].ProductName != "Guitar" ||
+		won.Stats.Count != 1 || won.Stats.TotalSpent != 150 {
+		t.Errorf("Expected Bob to have won the guitar for 150, got %+v", won)
+	}
+
+	status = client.do(http.MethodPost, "/bid", aliceToken, map[string]interface{}{
+		"auction_id": auctionId, "amount": 500,
+	}, nil)
+	time.Sleep(100 * time.Millisecond)
+
+	client.do(http.MethodGet, "/auction/winner/"+auctionId, "", nil, &winner)
+	if winner.Bid.UserId == aliceId {
+		t.Errorf("Expected a bid placed after closing to be rejected, got status %d and winner %+v", status, winner.Bid)
+	}
+
+	response, err := http.Get(client.server.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer response.Body.Close()
+	metrics, _ := io.ReadAll(response.Body)
+
+	for _, expected := range []string{
+		"auction_bids_accepted_total",
+		`auction_bids_rejected_total{otel_scope_name="fullcycle-auction_go/internal/usecase/bid_usecase",otel_scope_version="",reason="auction_closed"}`,
+		"auction_bid_batch_duration_seconds_count",
+		"auction_active",
+	} {
+		if !strings.Contains(string(metrics), expected) {
+			t.Errorf("Expected the metrics to contain %s, got:\n%s", expected, metrics)
+		}
+	}
+}
+
+func TestAuctionFormatsInMemory(t *testing.T) {
+	client := newTestClient(t)
+
+	_, sellerToken := client.createUser("Seller", "seller@example.com", "seller")
+	_, aliceToken := client.createUser("Alice", "alice@example.com", "bidder")
+	bobId, bobToken := client.createUser("Bob", "bob@example.com", "bidder")
+
+	createAuction := func(productName string, format map[string]interface{}) string {
+		input := map[string]interface{}{
+			"product_name": productName,
+			"category":     "Music",
+			"description":  "Vintage electric guitar",
+			"condition":    1,
+		}
+		for key, value := range format {
+			input[key] = value
+		}
+		if status := client.do(http.MethodPost, "/auction", sellerToken, input, nil); status != http.StatusCreated {
+			t.Fatalf("Expected the %s auction to be created, got status %d", productName, status)
+		}
+
+		var page struct {
+			Items []struct {
+				Id          string `json:"id"`
+				ProductName string `json:"product_name"`
+			} `json:"items"`
+		}
+		client.do(http.MethodGet, "/auction?product_name="+productName, "", nil, &page)
+		if len(page.Items) != 1 {
+			t.Fatalf("Expected one %s auction, got %d", productName, len(page.Items))
+		}
+		return page.Items[0].Id
+	}
+	placeBid := func(token, auctionId string, amount float64) {
+		status := client.do(http.MethodPost, "/bid", token, map[string]interface{}{
+			"auction_id": auctionId, "amount": amount,
+		}, nil)
+		if status != http.StatusCreated {
+			t.Fatalf("Expected the bid of %.2f to be accepted, got status %d", amount, status)
+		}
+	}
+
+	type settlementResult struct {
+		Outcome    string  `json:"outcome"`
+		WinnerId   string  `json:"winner_id"`
+		FinalPrice float64 `json:"final_price"`
+	}
+	waitResult := func(auctionId string, timeout time.Duration) settlementResult {
+		var result settlementResult
+		deadline := time.Now().Add(timeout)
+		for client.do(http.MethodGet, "/auction/"+auctionId+"/result", "", nil, &result) != http.StatusOK {
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected auction %s to close and be settled", auctionId)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return result
+	}
+
+	buyItNowId := createAuction("Piano", map[string]interface{}{"buy_it_now_price": 300})
+	placeBid(aliceToken, buyItNowId, 100)
+	placeBid(bobToken, buyItNowId, 350)
+
+	if result := waitResult(buyItNowId, time.Second); result.WinnerId != bobId || result.FinalPrice != 300 {
+		t.Errorf("Expected Bob to buy it now at 300 before the auction interval, got %+v", result)
+	}
+
+	sealedId := createAuction("Violin", map[string]interface{}{"format": "sealed_second_price"})
+	placeBid(aliceToken, sealedId, 100)
+	placeBid(bobToken, sealedId, 150)
+	time.Sleep(100 * time.Millisecond)
+
+	var auction struct {
+		CurrentPrice    float64 `json:"current_price"`
+		HighestBidderId string  `json:"highest_bidder_id"`
+		BidCount        int64   `json:"bid_count"`
+	}
+	client.do(http.MethodGet, "/auction/"+sealedId, "", nil, &auction)
+	if auction.CurrentPrice != 0 || auction.HighestBidderId != "" || auction.BidCount != 2 {
+		t.Errorf("Expected the sealed auction to hide its leading bid, got %+v", auction)
+	}
+	if status := client.do(http.MethodGet, "/bid/"+sealedId, "", nil, nil); status != http.StatusConflict {
+		t.Errorf("Expected the bids of an active sealed auction to be hidden, got status %d", status)
+	}
+
+	if result := waitResult(sealedId, 5*time.Second); result.WinnerId != bobId || result.FinalPrice != 100 {
+		t.Errorf("Expected Bob to win paying the second-highest bid of 100, got %+v", result)
+	}
+}
+
+func TestAuctionAttachmentsInMemory(t *testing.T) {
+	client := newTestClient(t)
+
+	_, sellerToken := client.createUser("Seller", "seller@example.com", "seller")
+	_, bidderToken := client.createUser("Alice", "alice@example.com", "bidder")
+
+	status := client.do(http.MethodPost, "/auction", sellerToken, map[string]interface{}{
+		"product_name": "Guitar",
+		"category":     "Music",
+		"description":  "Vintage electric guitar",
+		"condition":    1,
+	}, nil)
+	if status != http.StatusCreated {
+		t.Fatalf("Expected the auction to be created, got status %d", status)
+	}
+
+	var page struct {
+		Items []struct {
+			Id string `json:"id"`
+		} `json:"items"`
+	}
+	client.do(http.MethodGet, "/auction", "", nil, &page)
+	if len(page.Items) != 1 {
+		t.Fatalf("Expected one auction, got %d", len(page.Items))
+	}
+	attachmentsPath := "/auction/" + page.Items[0].Id + "/attachments"
+
+	var photo bytes.Buffer
+	png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 1024, 512)))
+
+	var uploaded struct {
+		URL          string `json:"url"`
+		ThumbnailURL string `json:"thumbnail_url"`
+	}
+	if status := client.upload(attachmentsPath, sellerToken, "photo.png", photo.Bytes(), &uploaded); status != http.StatusCreated {
+		t.Fatalf("Expected the photo to be uploaded, got status %d", status)
+	}
+	if status := client.upload(attachmentsPath, sellerToken, "manual.txt", []byte("Strings changed in 2023."), nil); status != http.StatusCreated {
+		t.Fatalf("Expected the manual to be uploaded, got status %d", status)
+	}
+	if status := client.upload(attachmentsPath, bidderToken, "photo.png", photo.Bytes(), nil); status != http.StatusForbidden {
+		t.Errorf("Expected bidders not to upload attachments, got status %d", status)
+	}
+	if status := client.upload(attachmentsPath, sellerToken, "run.exe", []byte("MZ\x90\x00\x03"), nil); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected executables to be refused, got status %d", status)
+	}
+
+	var auction struct {
+		Images    []struct{ Id, URL string } `json:"images"`
+		Documents []struct{ Id, URL string } `json:"documents"`
+	}
+	client.do(http.MethodGet, "/auction/"+page.Items[0].Id, "", nil, &auction)
+	if len(auction.Images) != 1 || len(auction.Documents) != 1 || auction.Images[0].URL != uploaded.URL {
+		t.Fatalf("Expected the auction to list one image and one document, got %+v", auction)
+	}
+
+	response, err := http.Get(client.server.URL + uploaded.ThumbnailURL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	thumbnail, err := jpeg.DecodeConfig(response.Body)
+	response.Body.Close()
+	if err != nil || thumbnail.Width != 256 || thumbnail.Height != 128 {
+		t.Errorf("Expected a 256x128 JPEG thumbnail, got %+v (%v)", thumbnail, err)
+	}
+
+	if status := client.do(http.MethodDelete, uploaded.URL, sellerToken, nil, nil); status != http.StatusNoContent {
+		t.Fatalf("Expected the photo to be deleted, got status %d", status)
+	}
+	if status := client.do(http.MethodGet, uploaded.URL, "", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected the deleted photo to be gone, got status %d", status)
+	}
+}
+
+func TestWatchlistAndNotificationsInMemory(t *testing.T) {
+	client := newTestClient(t, func(cfg *config.Config) {
+		cfg.Notification.ScanInterval = 100 * time.Millisecond
+		cfg.Notification.EndingSoonWindow = time.Hour
+	})
+
+	_, sellerToken := client.createUser("Seller", "seller@example.com", "seller")
+	aliceId, aliceToken := client.createUser("Alice", "alice@example.com", "bidder")
+	bobId, bobToken := client.createUser("Bob", "bob@example.com", "bidder")
+
+	client.do(http.MethodPost, "/auction", sellerToken, map[string]interface{}{
+		"product_name": "Guitar",
+		"category":     "Music",
+		"description":  "Vintage electric guitar",
+		"condition":    1,
+	}, nil)
+
+	var page struct {
+		Items []struct {
+			Id string `json:"id"`
+		} `json:"items"`
+	}
+	client.do(http.MethodGet, "/auction", "", nil, &page)
+	if len(page.Items) != 1 {
+		t.Fatalf("Expected one auction, got %d", len(page.Items))
+	}
+	auction := page.Items[0]
+
+	watchlistPath := "/user/" + aliceId + "/watchlist"
+	for i := 0; i < 2; i++ {
+		if status := client.do(http.MethodPost, watchlistPath, aliceToken,
+			map[string]string{"auction_id": auction.Id}, nil); status != http.StatusCreated {
+			t.Fatalf("Expected the auction to be added to the watchlist, got status %d", status)
+		}
+	}
+
+	if status := client.do(http.MethodPost, watchlistPath, aliceToken,
+		map[string]string{"auction_id": aliceId}, nil); status != http.StatusNotFound {
+		t.Errorf("Expected an unknown auction to be refused with 404, got %d", status)
+	}
+
+	if status := client.do(http.MethodGet, watchlistPath, bobToken, nil, nil); status != http.StatusForbidden {
+		t.Errorf("Expected Bob not to read the watchlist of Alice, got status %d", status)
+	}
+
+	var watchlist []struct {
+		AuctionId string `json:"auction_id"`
+	}
+	client.do(http.MethodGet, watchlistPath, aliceToken, nil, &watchlist)
+	if len(watchlist) != 1 || watchlist[0].AuctionId != auction.Id {
+		t.Errorf("Expected the watchlist to hold the auction once, got %+v", watchlist)
+	}
+
+	for _, bid := range []struct {
+		token  string
+		amount float64
+	}{{aliceToken, 100}, {bobToken, 150}} {
+		client.do(http.MethodPost, "/bid", bid.token, map[string]interface{}{
+			"auction_id": auction.Id, "amount": bid.amount,
+		}, nil)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	type notification struct {
+		Type      string `json:"type"`
+		AuctionId string `json:"auction_id"`
+	}
+	waitForNotifications := func(userId, token string, expected ...string) {
+		t.Helper()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			var notifications []notification
+			client.do(http.MethodGet, "/user/"+userId+"/notifications", token, nil, &notifications)
+
+			types := make(map[string]int)
+			for _, notification := range notifications {
+				types[notification.Type]++
+			}
+
+			missing := false
+			for _, notificationType := range expected {
+				if types[notificationType] != 1 {
+					missing = true
+				}
+			}
+			if !missing {
+				return
+			}
+
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected notifications %v once each, got %+v", expected, notifications)
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}
+
+	waitForNotifications(aliceId, aliceToken, "outbid", "ending_soon", "auction_lost")
+	waitForNotifications(bobId, bobToken, "ending_soon", "auction_won")
+
+	if status := client.do(http.MethodDelete, watchlistPath+"/"+auction.Id, aliceToken, nil, nil); status != http.StatusNoContent {
+		t.Errorf("Expected the auction to be removed from the watchlist, got status %d", status)
+	}
+	if status := client.do(http.MethodDelete, watchlistPath+"/"+auction.Id, aliceToken, nil, nil); status != http.StatusNotFound {
+		t.Errorf("Expected removing it again to answer 404, got status %d", status)
+	}
+}
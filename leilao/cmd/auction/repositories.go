@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/config"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/entity/watchlist_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/infra/database/attachment"
+	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/infra/database/bid"
+	"fullcycle-auction_go/internal/infra/database/memory"
+	"fullcycle-auction_go/internal/infra/database/migration"
+	"fullcycle-auction_go/internal/infra/database/notification"
+	"fullcycle-auction_go/internal/infra/database/outbox"
+	"fullcycle-auction_go/internal/infra/database/postgres"
+	"fullcycle-auction_go/internal/infra/database/proxy_bid"
+	"fullcycle-auction_go/internal/infra/database/settlement"
+	"fullcycle-auction_go/internal/infra/database/user"
+	"fullcycle-auction_go/internal/infra/database/webhook"
+)
+
+// auctionRepository also lets main settle auctions as soon as they complete.
+type auctionRepository interface {
+	auction_entity.AuctionRepositoryInterface
+
+	OnStatusChange(listener func(auctionId string, status auction_entity.AuctionStatus))
+}
+
+type repositories struct {
+	auction      auctionRepository
+	bid          bid_entity.BidEntityRepository
+	user         user_entity.UserRepositoryInterface
+	proxyBid     proxy_bid_entity.ProxyBidRepositoryInterface
+	settlement   settlement_entity.SettlementRepositoryInterface
+	outbox       event_entity.OutboxRepositoryInterface
+	subscription webhook_entity.SubscriptionRepositoryInterface
+	delivery     webhook_entity.DeliveryRepositoryInterface
+	attachment   attachment_entity.AttachmentRepositoryInterface
+	watchlist    watchlist_entity.WatchlistRepositoryInterface
+	notification notification_entity.NotificationRepositoryInterface
+
+	// start runs the background work of the backend that must wait for the
+	// auction status listeners to be registered.
+	start func(ctx context.Context)
+
+	// close stops that work and releases the connection to the database, if
+	// any.
+	close func(ctx context.Context) error
+}
+
+// newRepositories builds the repositories of the configured backend:
+// "mongodb", "postgres" or "memory", which keeps everything in the process and
+// loses it on restart.
+func newRepositories(ctx context.Context, cfg *config.Config) (*repositories, error) {
+	switch driver := cfg.Database.Driver; driver {
+	case "mongodb":
+		return newMongoRepositories(ctx, cfg)
+	case "postgres":
+		return newPostgresRepositories(ctx, cfg)
+	case "memory":
+		return newMemoryRepositories(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", driver)
+	}
+}
+
+func softClosePolicy(cfg *config.Config) auction_entity.SoftClosePolicy {
+	return auction_entity.SoftClosePolicy{
+		Window:       cfg.Auction.SoftCloseWindow,
+		Extension:    cfg.Auction.SoftCloseExtension,
+		MaxExtension: cfg.Auction.SoftCloseMaxExtension,
+	}
+}
+
+func newMongoRepositories(ctx context.Context, cfg *config.Config) (*repositories, error) {
+	database, err := mongodb.NewMongoDBConnection(ctx, cfg.Database.MongoURL, cfg.Database.MongoDatabase)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Database.MigrateOnStartup {
+		if err := migration.NewMigrator(database, cfg.Auction.Interval).Run(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	auctionRepository := auction.NewAuctionRepository(
+		database, cfg.Auction.Interval, cfg.Auction.CloseSweepInterval)
+	if err := auctionRepository.EnsureSearchIndex(ctx, cfg.Auction.SearchLanguage); err != nil {
+		return nil, err
+	}
+
+	bidRepository := bid.NewBidRepository(
+		database, auctionRepository, cfg.Auction.Interval, softClosePolicy(cfg),
+		bid.AuctionCacheOptions{Size: cfg.Auction.CacheSize, TTL: cfg.Auction.CacheTTL})
+
+	start := auctionRepository.StartClosingSweep
+	if cfg.Auction.CacheChangeStream {
+		start = func(ctx context.Context) {
+			auctionRepository.StartClosingSweep(ctx)
+			bidRepository.WatchAuctionChanges(ctx)
+		}
+	}
+
+	return &repositories{
+		auction:      auctionRepository,
+		bid:          bidRepository,
+		user:         user.NewUserRepository(database),
+		proxyBid:     proxy_bid.NewProxyBidRepository(database),
+		settlement:   settlement.NewSettlementRepository(database),
+		outbox:       outbox.NewOutboxRepository(database),
+		subscription: webhook.NewSubscriptionRepository(database),
+		delivery:     webhook.NewDeliveryRepository(database),
+		attachment:   attachment.NewAttachmentRepository(database),
+		watchlist:    notification.NewWatchlistRepository(database),
+		notification: notification.NewNotificationRepository(database),
+		start:        start,
+		close: func(ctx context.Context) error {
+			auctionRepository.StopClosingSweep()
+			bidRepository.StopWatchingAuctionChanges()
+			return database.Client().Disconnect(ctx)
+		},
+	}, nil
+}
+
+func newPostgresRepositories(ctx context.Context, cfg *config.Config) (*repositories, error) {
+	database, err := postgres_connection.NewPostgresConnection(
+		ctx, cfg.Database.PostgresURL, cfg.Database.PostgresMaxOpenConns)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Database.MigrateOnStartup {
+		if err := postgres.NewMigrator(database).Run(ctx); err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+
+	auctionRepository := postgres.NewAuctionRepository(
+		database, cfg.Auction.Interval, cfg.Auction.CloseSweepInterval)
+	bidRepository := postgres.NewBidRepository(
+		database, auctionRepository, cfg.Auction.Interval, softClosePolicy(cfg))
+
+	return &repositories{
+		auction:      auctionRepository,
+		bid:          bidRepository,
+		user:         postgres.NewUserRepository(database),
+		proxyBid:     postgres.NewProxyBidRepository(database),
+		settlement:   postgres.NewSettlementRepository(database),
+		outbox:       postgres.NewOutboxRepository(database),
+		subscription: postgres.NewSubscriptionRepository(database),
+		delivery:     postgres.NewDeliveryRepository(database),
+		attachment:   postgres.NewAttachmentRepository(database),
+		watchlist:    postgres.NewWatchlistRepository(database),
+		notification: postgres.NewNotificationRepository(database),
+		start:        auctionRepository.StartClosingSweep,
+		close: func(ctx context.Context) error {
+			auctionRepository.StopClosingSweep()
+			return database.Close()
+		},
+	}, nil
+}
+
+func newMemoryRepositories(cfg *config.Config) *repositories {
+	outboxRepository := memory.NewOutboxRepository()
+	auctionRepository := memory.NewAuctionRepository(outboxRepository, cfg.Auction.Interval)
+
+	return &repositories{
+		auction:      auctionRepository,
+		bid:          memory.NewBidRepository(auctionRepository, cfg.Auction.Interval, softClosePolicy(cfg)),
+		user:         memory.NewUserRepository(),
+		proxyBid:     memory.NewProxyBidRepository(),
+		settlement:   memory.NewSettlementRepository(outboxRepository),
+		outbox:       outboxRepository,
+		subscription: memory.NewSubscriptionRepository(),
+		delivery:     memory.NewDeliveryRepository(),
+		attachment:   memory.NewAttachmentRepository(),
+		watchlist:    memory.NewWatchlistRepository(),
+		notification: memory.NewNotificationRepository(),
+		start:        func(ctx context.Context) {},
+		close:        func(ctx context.Context) error { return nil },
+	}
+}
+
+// migrator applies and lists the schema migrations of a backend.
+type migrator interface {
+	Run(ctx context.Context) error
+	Status(ctx context.Context) ([]migration.MigrationStatus, error)
+}
+
+// newMigrator connects to the configured backend for the migrate command. The
+// memory backend has no schema to migrate.
+func newMigrator(ctx context.Context, cfg *config.Config) (migrator, error) {
+	switch driver := cfg.Database.Driver; driver {
+	case "mongodb":
+		database, err := mongodb.NewMongoDBConnection(ctx, cfg.Database.MongoURL, cfg.Database.MongoDatabase)
+		if err != nil {
+			return nil, err
+		}
+		return migration.NewMigrator(database, cfg.Auction.Interval), nil
+	case "postgres":
+		database, err := postgres_connection.NewPostgresConnection(
+			ctx, cfg.Database.PostgresURL, cfg.Database.PostgresMaxOpenConns)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewMigrator(database), nil
+	default:
+		return nil, fmt.Errorf("DATABASE_DRIVER must be mongodb or postgres to run migrations, got %q", driver)
+	}
+}
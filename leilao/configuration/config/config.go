@@ -0,0 +1,228 @@
+package config
+
+import (
+	"time"
+)
+
+// Config holds every setting of the auction service. Load fills it from the
+// defaults, an optional dotenv file, the environment and the command line
+// flags, each source overriding the previous one.
+type Config struct {
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Auction      AuctionConfig
+	Bid          BidConfig
+	Auth         AuthConfig
+	Telemetry    TelemetryConfig
+	Attachment   AttachmentConfig
+	Notification NotificationConfig
+	Webhook      WebhookConfig
+
+	sources map[string]Source
+}
+
+type ServerConfig struct {
+	Port              int
+	RequestTimeout    time.Duration
+	ShutdownTimeout   time.Duration
+	SettlementTimeout time.Duration
+}
+
+type DatabaseConfig struct {
+	Driver               string
+	MigrateOnStartup     bool
+	MongoURL             string
+	MongoDatabase        string
+	PostgresURL          string
+	PostgresMaxOpenConns int
+}
+
+type AuctionConfig struct {
+	Interval              time.Duration
+	CloseSweepInterval    time.Duration
+	SearchLanguage        string
+	SoftCloseWindow       time.Duration
+	SoftCloseExtension    time.Duration
+	SoftCloseMaxExtension time.Duration
+	CacheSize             int
+	CacheTTL              time.Duration
+	CacheChangeStream     bool
+}
+
+type BidConfig struct {
+	BatchInsertInterval time.Duration
+	MaxBatchSize        int
+	BatchTimeout        time.Duration
+	MinIncrement        float64
+	WALPath             string
+	WALSync             string
+	WALSyncInterval     time.Duration
+}
+
+type AuthConfig struct {
+	JWTSecret     string
+	JWTExpiration time.Duration
+	AdminEmail    string
+	AdminPassword string
+}
+
+type TelemetryConfig struct {
+	ServiceName  string
+	OTLPEndpoint string
+}
+
+type AttachmentConfig struct {
+	BlobStore       string
+	BlobStorePath   string
+	MaxImageSize    int64
+	MaxDocumentSize int64
+	MaxPerAuction   int
+}
+
+type NotificationConfig struct {
+	Channels         []string
+	ScanInterval     time.Duration
+	EndingSoonWindow time.Duration
+	QueueSize        int
+	SMTPAddr         string
+	SMTPFrom         string
+	SMTPUsername     string
+	SMTPPassword     string
+	WebhookURL       string
+	WebhookSecret    string
+}
+
+type WebhookConfig struct {
+	Timeout        time.Duration
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	PollInterval   time.Duration
+}
+
+// Default returns the settings used when no source sets them.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			RequestTimeout:    10 * time.Second,
+			ShutdownTimeout:   30 * time.Second,
+			SettlementTimeout: 10 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:           "mongodb",
+			MigrateOnStartup: true,
+			MongoDatabase:    "auctions",
+		},
+		Auction: AuctionConfig{
+			Interval:           5 * time.Minute,
+			CloseSweepInterval: time.Second,
+			SearchLanguage:     "portuguese",
+			CacheSize:          10000,
+			CacheTTL:           30 * time.Second,
+		},
+		Bid: BidConfig{
+			BatchInsertInterval: 3 * time.Minute,
+			MaxBatchSize:        5,
+			BatchTimeout:        30 * time.Second,
+			MinIncrement:        1,
+			WALPath:             "data/bids.wal",
+			WALSync:             "always",
+			WALSyncInterval:     time.Second,
+		},
+		Auth: AuthConfig{
+			JWTExpiration: time.Hour,
+		},
+		Telemetry: TelemetryConfig{
+			ServiceName: "auction",
+		},
+		Attachment: AttachmentConfig{
+			BlobStore:       "local",
+			BlobStorePath:   "data/blobs",
+			MaxImageSize:    5 << 20,
+			MaxDocumentSize: 10 << 20,
+			MaxPerAuction:   10,
+		},
+		Notification: NotificationConfig{
+			Channels:         []string{"log"},
+			ScanInterval:     30 * time.Second,
+			EndingSoonWindow: 10 * time.Minute,
+			QueueSize:        1000,
+			SMTPFrom:         "leilao@localhost",
+		},
+		Webhook: WebhookConfig{
+			Timeout:        10 * time.Second,
+			MaxAttempts:    8,
+			RetryBaseDelay: 10 * time.Second,
+			RetryMaxDelay:  time.Hour,
+			PollInterval:   2 * time.Second,
+		},
+	}
+}
+
+// settings binds every environment variable to its field. The flag of a
+// setting is its key in lower case with dashes, e.g. -auction-interval.
+func (c *Config) settings() []setting {
+	return []setting{
+		{key: "PORT", usage: "HTTP port", value: (*intValue)(&c.Server.Port)},
+		{key: "REQUEST_TIMEOUT", usage: "deadline of each HTTP request", value: (*durationValue)(&c.Server.RequestTimeout)},
+		{key: "SHUTDOWN_TIMEOUT", usage: "deadline of the graceful shutdown", value: (*durationValue)(&c.Server.ShutdownTimeout)},
+		{key: "SETTLEMENT_TIMEOUT", usage: "deadline of the settlement of a closed auction", value: (*durationValue)(&c.Server.SettlementTimeout)},
+
+		{key: "DATABASE_DRIVER", usage: "mongodb, postgres or memory", value: (*stringValue)(&c.Database.Driver)},
+		{key: "MIGRATE_ON_STARTUP", usage: "apply pending migrations on startup", value: (*boolValue)(&c.Database.MigrateOnStartup)},
+		{key: "MONGODB_URL", usage: "MongoDB connection string", value: (*stringValue)(&c.Database.MongoURL), secret: true},
+		{key: "MONGODB_DB", usage: "MongoDB database name", value: (*stringValue)(&c.Database.MongoDatabase)},
+		{key: "POSTGRES_URL", usage: "PostgreSQL connection string", value: (*stringValue)(&c.Database.PostgresURL), secret: true},
+		{key: "POSTGRES_MAX_OPEN_CONNS", usage: "PostgreSQL pool size, 0 for the driver default", value: (*intValue)(&c.Database.PostgresMaxOpenConns)},
+
+		{key: "AUCTION_INTERVAL", usage: "duration of an auction", value: (*durationValue)(&c.Auction.Interval)},
+		{key: "AUCTION_CLOSE_SWEEP_INTERVAL", usage: "interval of the sweep closing ended auctions", value: (*durationValue)(&c.Auction.CloseSweepInterval)},
+		{key: "AUCTION_SEARCH_LANGUAGE", usage: "language of the MongoDB text index", value: (*stringValue)(&c.Auction.SearchLanguage)},
+		{key: "AUCTION_SOFT_CLOSE_WINDOW", usage: "final window in which a bid extends the auction", value: (*durationValue)(&c.Auction.SoftCloseWindow)},
+		{key: "AUCTION_SOFT_CLOSE_EXTENSION", usage: "extension of each bid in the soft-close window", value: (*durationValue)(&c.Auction.SoftCloseExtension)},
+		{key: "AUCTION_SOFT_CLOSE_MAX_EXTENSION", usage: "maximum extension over the original end", value: (*durationValue)(&c.Auction.SoftCloseMaxExtension)},
+		{key: "AUCTION_CACHE_SIZE", usage: "auctions whose state the MongoDB bid repository caches", value: (*intValue)(&c.Auction.CacheSize)},
+		{key: "AUCTION_CACHE_TTL", usage: "how long the MongoDB bid repository caches an auction state", value: (*durationValue)(&c.Auction.CacheTTL)},
+		{key: "AUCTION_CACHE_CHANGE_STREAM", usage: "invalidate the cached auction states from a MongoDB change stream", value: (*boolValue)(&c.Auction.CacheChangeStream)},
+
+		{key: "BATCH_INSERT_INTERVAL", usage: "longest wait before a bid batch is stored", value: (*durationValue)(&c.Bid.BatchInsertInterval)},
+		{key: "MAX_BATCH_SIZE", usage: "bids stored per batch", value: (*intValue)(&c.Bid.MaxBatchSize)},
+		{key: "BID_BATCH_TIMEOUT", usage: "deadline of each bid batch", value: (*durationValue)(&c.Bid.BatchTimeout)},
+		{key: "MIN_BID_INCREMENT", usage: "minimum raise of automatic bids", value: (*floatValue)(&c.Bid.MinIncrement)},
+		{key: "BID_WAL_PATH", usage: "bid write-ahead log file", value: (*stringValue)(&c.Bid.WALPath)},
+		{key: "BID_WAL_SYNC", usage: "always, interval or none", value: (*stringValue)(&c.Bid.WALSync)},
+		{key: "BID_WAL_SYNC_INTERVAL", usage: "fsync interval with BID_WAL_SYNC=interval", value: (*durationValue)(&c.Bid.WALSyncInterval)},
+
+		{key: "JWT_SECRET", usage: "token signing key, at least 32 characters", value: (*stringValue)(&c.Auth.JWTSecret), secret: true},
+		{key: "JWT_EXPIRATION", usage: "validity of the issued tokens", value: (*durationValue)(&c.Auth.JWTExpiration)},
+		{key: "ADMIN_EMAIL", usage: "email of the first admin", value: (*stringValue)(&c.Auth.AdminEmail)},
+		{key: "ADMIN_PASSWORD", usage: "password of the first admin", value: (*stringValue)(&c.Auth.AdminPassword), secret: true},
+
+		{key: "OTEL_SERVICE_NAME", usage: "service name of traces and metrics", value: (*stringValue)(&c.Telemetry.ServiceName)},
+		{key: "OTEL_EXPORTER_OTLP_ENDPOINT", usage: "OTLP/HTTP collector, empty to disable", value: (*stringValue)(&c.Telemetry.OTLPEndpoint)},
+
+		{key: "BLOB_STORE", usage: "attachment storage, local", value: (*stringValue)(&c.Attachment.BlobStore)},
+		{key: "BLOB_STORE_PATH", usage: "directory of the local blob store", value: (*stringValue)(&c.Attachment.BlobStorePath)},
+		{key: "MAX_IMAGE_SIZE", usage: "largest image in bytes", value: (*int64Value)(&c.Attachment.MaxImageSize)},
+		{key: "MAX_DOCUMENT_SIZE", usage: "largest document in bytes", value: (*int64Value)(&c.Attachment.MaxDocumentSize)},
+		{key: "MAX_ATTACHMENTS_PER_AUCTION", usage: "attachments per auction", value: (*intValue)(&c.Attachment.MaxPerAuction)},
+
+		{key: "NOTIFICATION_CHANNELS", usage: "comma separated log, email and webhook", value: (*listValue)(&c.Notification.Channels)},
+		{key: "NOTIFICATION_SCAN_INTERVAL", usage: "interval of the ending soon scan", value: (*durationValue)(&c.Notification.ScanInterval)},
+		{key: "ENDING_SOON_WINDOW", usage: "notice of the ending soon notification", value: (*durationValue)(&c.Notification.EndingSoonWindow)},
+		{key: "NOTIFICATION_QUEUE_SIZE", usage: "notifications waiting to be sent", value: (*intValue)(&c.Notification.QueueSize)},
+		{key: "SMTP_ADDR", usage: "SMTP server host:port", value: (*stringValue)(&c.Notification.SMTPAddr)},
+		{key: "SMTP_FROM", usage: "sender of the emails", value: (*stringValue)(&c.Notification.SMTPFrom)},
+		{key: "SMTP_USERNAME", usage: "SMTP username", value: (*stringValue)(&c.Notification.SMTPUsername)},
+		{key: "SMTP_PASSWORD", usage: "SMTP password", value: (*stringValue)(&c.Notification.SMTPPassword), secret: true},
+		{key: "NOTIFICATION_WEBHOOK_URL", usage: "URL of the webhook channel", value: (*stringValue)(&c.Notification.WebhookURL)},
+		{key: "NOTIFICATION_WEBHOOK_SECRET", usage: "signing key of the webhook channel", value: (*stringValue)(&c.Notification.WebhookSecret), secret: true},
+
+		{key: "WEBHOOK_TIMEOUT", usage: "deadline of each webhook request", value: (*durationValue)(&c.Webhook.Timeout)},
+		{key: "WEBHOOK_MAX_ATTEMPTS", usage: "attempts before a delivery is dead-lettered", value: (*intValue)(&c.Webhook.MaxAttempts)},
+		{key: "WEBHOOK_RETRY_BASE_DELAY", usage: "delay before the first retry", value: (*durationValue)(&c.Webhook.RetryBaseDelay)},
+		{key: "WEBHOOK_RETRY_MAX_DELAY", usage: "longest delay between retries", value: (*durationValue)(&c.Webhook.RetryMaxDelay)},
+		{key: "WEBHOOK_POLL_INTERVAL", usage: "interval of the webhook dispatcher", value: (*durationValue)(&c.Webhook.PollInterval)},
+	}
+}
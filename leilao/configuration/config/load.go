@@ -0,0 +1,372 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	CONFIG_FILE = "CONFIG_FILE"
+
+	// defaultConfigFile is read only if it exists, so deployments can rely on
+	// the environment alone.
+	defaultConfigFile = "cmd/auction/.env"
+
+	maskedValue = "******"
+)
+
+type Source string
+
+const (
+	DefaultSource Source = "default"
+	FileSource    Source = "file"
+	EnvSource     Source = "env"
+	FlagSource    Source = "flag"
+)
+
+type setting struct {
+	key    string
+	usage  string
+	secret bool
+	value  flag.Value
+}
+
+// Entry is the effective value of a setting and the source it came from.
+// Secrets are masked.
+type Entry struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// Load reads the configuration from the defaults, the dotenv file given by
+// -config or CONFIG_FILE (cmd/auction/.env if present otherwise), the
+// environment and the flags in args, in increasing order of precedence. Empty
+// values are ignored outside the flags. Every invalid setting is reported in
+// the returned error. The arguments left after the flags, such as the migrate
+// command, are returned as well.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	config := Default()
+	config.sources = make(map[string]Source)
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("auction", flag.ContinueOnError)
+	configFile := flags.String("config", "", "dotenv file read before the environment (env CONFIG_FILE)")
+	flagValues := make(map[string]string)
+	for _, setting := range settings {
+		key := setting.key
+		flags.Func(flagName(key), fmt.Sprintf("%s (env %s, default %q)", setting.usage, key, setting.value.String()),
+			func(value string) error {
+				flagValues[key] = value
+				return nil
+			})
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	fileValues, err := readConfigFile(*configFile, lookupEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs []error
+	apply := func(setting setting, source Source, value string) {
+		if err := setting.value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", setting.key, source, err))
+			return
+		}
+		config.sources[setting.key] = source
+	}
+
+	for _, setting := range settings {
+		if value := fileValues[setting.key]; value != "" {
+			apply(setting, FileSource, value)
+		}
+		if value, _ := lookupEnv(setting.key); value != "" {
+			apply(setting, EnvSource, value)
+		}
+		if value, ok := flagValues[setting.key]; ok {
+			apply(setting, FlagSource, value)
+		}
+	}
+
+	if err := errors.Join(append(errs, config.Validate())...); err != nil {
+		return nil, nil, err
+	}
+
+	return config, flags.Args(), nil
+}
+
+func readConfigFile(path string, lookupEnv func(string) (string, bool)) (map[string]string, error) {
+	explicit := path != ""
+	if !explicit {
+		if value, _ := lookupEnv(CONFIG_FILE); value != "" {
+			path, explicit = value, true
+		} else {
+			path = defaultConfigFile
+		}
+	}
+
+	values, err := godotenv.Read(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error trying to read config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// Validate reports every setting out of its range, and the settings required
+// by the chosen database driver and notification channels.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		invalid("PORT", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+
+	for _, duration := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"REQUEST_TIMEOUT", c.Server.RequestTimeout},
+		{"SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"SETTLEMENT_TIMEOUT", c.Server.SettlementTimeout},
+		{"AUCTION_INTERVAL", c.Auction.Interval},
+		{"AUCTION_CLOSE_SWEEP_INTERVAL", c.Auction.CloseSweepInterval},
+		{"AUCTION_CACHE_TTL", c.Auction.CacheTTL},
+		{"BATCH_INSERT_INTERVAL", c.Bid.BatchInsertInterval},
+		{"BID_BATCH_TIMEOUT", c.Bid.BatchTimeout},
+		{"BID_WAL_SYNC_INTERVAL", c.Bid.WALSyncInterval},
+		{"JWT_EXPIRATION", c.Auth.JWTExpiration},
+		{"NOTIFICATION_SCAN_INTERVAL", c.Notification.ScanInterval},
+		{"ENDING_SOON_WINDOW", c.Notification.EndingSoonWindow},
+		{"WEBHOOK_TIMEOUT", c.Webhook.Timeout},
+		{"WEBHOOK_RETRY_BASE_DELAY", c.Webhook.RetryBaseDelay},
+		{"WEBHOOK_RETRY_MAX_DELAY", c.Webhook.RetryMaxDelay},
+		{"WEBHOOK_POLL_INTERVAL", c.Webhook.PollInterval},
+	} {
+		if duration.value <= 0 {
+			invalid(duration.key, "must be positive, got %s", duration.value)
+		}
+	}
+
+	for _, duration := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"AUCTION_SOFT_CLOSE_WINDOW", c.Auction.SoftCloseWindow},
+		{"AUCTION_SOFT_CLOSE_EXTENSION", c.Auction.SoftCloseExtension},
+		{"AUCTION_SOFT_CLOSE_MAX_EXTENSION", c.Auction.SoftCloseMaxExtension},
+	} {
+		if duration.value < 0 {
+			invalid(duration.key, "must not be negative, got %s", duration.value)
+		}
+	}
+
+	for _, number := range []struct {
+		key   string
+		value int64
+	}{
+		{"MAX_BATCH_SIZE", int64(c.Bid.MaxBatchSize)},
+		{"AUCTION_CACHE_SIZE", int64(c.Auction.CacheSize)},
+		{"MAX_IMAGE_SIZE", c.Attachment.MaxImageSize},
+		{"MAX_DOCUMENT_SIZE", c.Attachment.MaxDocumentSize},
+		{"MAX_ATTACHMENTS_PER_AUCTION", int64(c.Attachment.MaxPerAuction)},
+		{"NOTIFICATION_QUEUE_SIZE", int64(c.Notification.QueueSize)},
+		{"WEBHOOK_MAX_ATTEMPTS", int64(c.Webhook.MaxAttempts)},
+	} {
+		if number.value <= 0 {
+			invalid(number.key, "must be positive, got %d", number.value)
+		}
+	}
+
+	if c.Bid.MinIncrement <= 0 {
+		invalid("MIN_BID_INCREMENT", "must be positive, got %g", c.Bid.MinIncrement)
+	}
+	if c.Database.PostgresMaxOpenConns < 0 {
+		invalid("POSTGRES_MAX_OPEN_CONNS", "must not be negative, got %d", c.Database.PostgresMaxOpenConns)
+	}
+
+	switch c.Database.Driver {
+	case "mongodb":
+		if c.Database.MongoURL == "" {
+			invalid("MONGODB_URL", "must be set with DATABASE_DRIVER=mongodb")
+		}
+		if c.Database.MongoDatabase == "" {
+			invalid("MONGODB_DB", "must be set with DATABASE_DRIVER=mongodb")
+		}
+	case "postgres":
+		if c.Database.PostgresURL == "" {
+			invalid("POSTGRES_URL", "must be set with DATABASE_DRIVER=postgres")
+		}
+	case "memory":
+	default:
+		invalid("DATABASE_DRIVER", "must be mongodb, postgres or memory, got %q", c.Database.Driver)
+	}
+
+	switch c.Bid.WALSync {
+	case "always", "interval", "none":
+	default:
+		invalid("BID_WAL_SYNC", "must be always, interval or none, got %q", c.Bid.WALSync)
+	}
+
+	if len(c.Auth.JWTSecret) < 32 {
+		invalid("JWT_SECRET", "must be set with at least 32 characters")
+	}
+	if (c.Auth.AdminEmail == "") != (c.Auth.AdminPassword == "") {
+		invalid("ADMIN_EMAIL", "must be set together with ADMIN_PASSWORD")
+	}
+
+	if c.Attachment.BlobStore != "local" {
+		invalid("BLOB_STORE", "must be local, got %q", c.Attachment.BlobStore)
+	}
+
+	for _, channel := range c.Notification.Channels {
+		switch channel {
+		case "log":
+		case "email":
+			if _, _, err := net.SplitHostPort(c.Notification.SMTPAddr); err != nil {
+				invalid("SMTP_ADDR", "must be host:port to send notifications by email, got %q", c.Notification.SMTPAddr)
+			}
+		case "webhook":
+			if c.Notification.WebhookURL == "" {
+				invalid("NOTIFICATION_WEBHOOK_URL", "must be set to send notifications by webhook")
+			}
+		default:
+			invalid("NOTIFICATION_CHANNELS", "must list log, email or webhook, got %q", channel)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Entries lists every setting with its effective value, in declaration order.
+func (c *Config) Entries() []Entry {
+	settings := c.settings()
+	entries := make([]Entry, 0, len(settings))
+	for _, setting := range settings {
+		value := setting.value.String()
+		if setting.secret && value != "" {
+			value = maskedValue
+		}
+
+		source := c.sources[setting.key]
+		if source == "" {
+			source = DefaultSource
+		}
+
+		entries = append(entries, Entry{Key: setting.key, Value: value, Source: source})
+	}
+
+	return entries
+}
+
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+type stringValue string
+
+func (v *stringValue) Set(value string) error {
+	*v = stringValue(value)
+	return nil
+}
+
+func (v *stringValue) String() string { return string(*v) }
+
+type intValue int
+
+func (v *intValue) Set(value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("must be an integer, got %q", value)
+	}
+	*v = intValue(parsed)
+	return nil
+}
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type int64Value int64
+
+func (v *int64Value) Set(value string) error {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("must be an integer, got %q", value)
+	}
+	*v = int64Value(parsed)
+	return nil
+}
+
+func (v *int64Value) String() string { return strconv.FormatInt(int64(*v), 10) }
+
+type floatValue float64
+
+func (v *floatValue) Set(value string) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("must be a number, got %q", value)
+	}
+	*v = floatValue(parsed)
+	return nil
+}
+
+func (v *floatValue) String() string { return strconv.FormatFloat(float64(*v), 'g', -1, 64) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("must be a duration such as 30s or 5m, got %q", value)
+	}
+	*v = durationValue(parsed)
+	return nil
+}
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+type boolValue bool
+
+func (v *boolValue) Set(value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("must be true or false, got %q", value)
+	}
+	*v = boolValue(parsed)
+	return nil
+}
+
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+// listValue is a comma separated list; blank items are dropped.
+type listValue []string
+
+func (v *listValue) Set(value string) error {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*v = items
+	return nil
+}
+
+func (v *listValue) String() string { return strings.Join(*v, ",") }
@@ -0,0 +1,22 @@
+package mongodb
+
+import (
+	"errors"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NewDatabaseError classifies a driver error for the repositories: lost
+// connections and timeouts are unavailable, duplicate keys are conflicts and
+// anything else is an internal error. The driver error stays wrapped.
+func NewDatabaseError(message string, err error) *internal_error.InternalError {
+	switch {
+	case mongo.IsNetworkError(err), mongo.IsTimeout(err), errors.Is(err, mongo.ErrClientDisconnected):
+		return internal_error.NewUnavailableError(message).Wrap(err)
+	case mongo.IsDuplicateKeyError(err):
+		return internal_error.NewConflictError(message).Wrap(err)
+	default:
+		return internal_error.NewInternalServerError(message).Wrap(err)
+	}
+}
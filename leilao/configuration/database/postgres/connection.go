@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fullcycle-auction_go/configuration/logger"
+
+	_ "github.com/lib/pq"
+)
+
+// NewPostgresConnection opens a pool of at most maxOpenConns connections, or
+// the driver default when maxOpenConns is 0.
+func NewPostgresConnection(ctx context.Context, url string, maxOpenConns int) (*sql.DB, error) {
+	database, err := sql.Open("postgres", url)
+	if err != nil {
+		logger.Error("Error trying to connect to postgres database", err)
+		return nil, err
+	}
+
+	if maxOpenConns > 0 {
+		database.SetMaxOpenConns(maxOpenConns)
+		database.SetMaxIdleConns(maxOpenConns)
+	}
+
+	if err := database.PingContext(ctx); err != nil {
+		logger.Error("Error trying to ping postgres database", err)
+		database.Close()
+		return nil, err
+	}
+
+	return database, nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fullcycle-auction_go/internal/internal_error"
+	"net"
+
+	"github.com/lib/pq"
+)
+
+// NewDatabaseError classifies a driver error for the repositories: lost
+// connections, timeouts and the SQLSTATE classes for connection exceptions
+// (08), exhausted resources (53) and operator intervention such as a server
+// shutdown (57) are unavailable; anything else is an internal error. The
+// driver error stays wrapped.
+func NewDatabaseError(message string, err error) *internal_error.InternalError {
+	if isUnavailable(err) {
+		return internal_error.NewUnavailableError(message).Wrap(err)
+	}
+
+	return internal_error.NewInternalServerError(message).Wrap(err)
+}
+
+func isUnavailable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "53", "57":
+			return true
+		}
+	}
+
+	return false
+}
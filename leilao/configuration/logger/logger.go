@@ -1,10 +1,20 @@
 package logger
 
 import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+type contextKey string
+
+const (
+	requestIdKey contextKey = "request_id"
+	auctionIdKey contextKey = "auction_id"
+)
+
 var (
 	log *zap.Logger
 )
@@ -36,3 +46,61 @@ func Error(message string, err error, tags ...zap.Field) {
 	log.Error(message, tags...)
 	log.Sync()
 }
+
+// InfoContext logs like Info and adds the request and auction ids carried by
+// ctx, so every line of a request can be correlated.
+func InfoContext(ctx context.Context, message string, tags ...zap.Field) {
+	Info(message, withContextFields(ctx, tags)...)
+}
+
+// ErrorContext logs like Error and adds the request and auction ids carried by
+// ctx.
+func ErrorContext(ctx context.Context, message string, err error, tags ...zap.Field) {
+	Error(message, err, withContextFields(ctx, tags)...)
+}
+
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdKey, requestId)
+}
+
+func RequestIdFromContext(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey).(string)
+	return requestId
+}
+
+func WithAuctionId(ctx context.Context, auctionId string) context.Context {
+	return context.WithValue(ctx, auctionIdKey, auctionId)
+}
+
+func AuctionIdFromContext(ctx context.Context) string {
+	auctionId, _ := ctx.Value(auctionIdKey).(string)
+	return auctionId
+}
+
+// withContextFields prepends the ids carried by ctx, and the id of its trace,
+// to tags, unless the caller already logs a field with the same key.
+func withContextFields(ctx context.Context, tags []zap.Field) []zap.Field {
+	fields := make([]zap.Field, 0, len(tags)+3)
+	for _, key := range []contextKey{requestIdKey, auctionIdKey} {
+		value, _ := ctx.Value(key).(string)
+		if value != "" && !hasField(tags, string(key)) {
+			fields = append(fields, zap.String(string(key), value))
+		}
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		fields = append(fields, zap.String("trace_id", spanContext.TraceID().String()))
+	}
+
+	return append(fields, tags...)
+}
+
+func hasField(tags []zap.Field, key string) bool {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -21,30 +21,61 @@ func (r *RestErr) Error() string {
 	return r.Message
 }
 
+// statusCodes maps each internal error code to its HTTP status. Codes not
+// listed are answered with 500.
+var statusCodes = map[internal_error.ErrorCode]int{
+	internal_error.BadRequest:   http.StatusBadRequest,
+	internal_error.Validation:   http.StatusUnprocessableEntity,
+	internal_error.Unauthorized: http.StatusUnauthorized,
+	internal_error.Forbidden:    http.StatusForbidden,
+	internal_error.NotFound:     http.StatusNotFound,
+	internal_error.Conflict:     http.StatusConflict,
+	internal_error.Unavailable:  http.StatusServiceUnavailable,
+}
+
+// ConvertError keeps the code of the internal error as the machine-readable
+// "err" field and carries its field causes over.
 func ConvertError(internalError *internal_error.InternalError) *RestErr {
-	switch internalError.Err {
-	case "bad_request":
-		return NewBadRequestError(internalError.Error())
-	case "not_found":
-		return NewNotFoundError(internalError.Error())
-	default:
+	status, ok := statusCodes[internalError.Code]
+	if !ok {
 		return NewInternalServerError(internalError.Error())
 	}
+
+	restErr := &RestErr{
+		Message: internalError.Error(),
+		Err:     string(internalError.Code),
+		Code:    status,
+	}
+	for _, cause := range internalError.Causes {
+		restErr.Causes = append(restErr.Causes, Causes{Field: cause.Field, Message: cause.Message})
+	}
+
+	return restErr
 }
 
 func NewBadRequestError(message string, causes ...Causes) *RestErr {
 	return &RestErr{
 		Message: message,
-		Err:     "bad_request",
+		Err:     string(internal_error.BadRequest),
 		Code:    http.StatusBadRequest,
 		Causes:  causes,
 	}
 }
 
+// NewValidationError reports request fields that were parsed but are invalid.
+func NewValidationError(message string, causes ...Causes) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     string(internal_error.Validation),
+		Code:    http.StatusUnprocessableEntity,
+		Causes:  causes,
+	}
+}
+
 func NewInternalServerError(message string) *RestErr {
 	return &RestErr{
 		Message: message,
-		Err:     "internal_server",
+		Err:     string(internal_error.Internal),
 		Code:    http.StatusInternalServerError,
 		Causes:  nil,
 	}
@@ -53,8 +84,26 @@ func NewInternalServerError(message string) *RestErr {
 func NewNotFoundError(message string) *RestErr {
 	return &RestErr{
 		Message: message,
-		Err:     "not_found",
+		Err:     string(internal_error.NotFound),
 		Code:    http.StatusNotFound,
 		Causes:  nil,
 	}
 }
+
+func NewUnauthorizedError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     string(internal_error.Unauthorized),
+		Code:    http.StatusUnauthorized,
+		Causes:  nil,
+	}
+}
+
+func NewForbiddenError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     string(internal_error.Forbidden),
+		Code:    http.StatusForbidden,
+		Causes:  nil,
+	}
+}
@@ -0,0 +1,52 @@
+package rest_err
+
+import (
+	"errors"
+	"fullcycle-auction_go/internal/internal_error"
+	"net/http"
+	"testing"
+)
+
+func TestConvertErrorMapsCodesToStatus(t *testing.T) {
+	tests := []struct {
+		internalError  *internal_error.InternalError
+		expectedStatus int
+	}{
+		{internal_error.NewBadRequestError("bad"), http.StatusBadRequest},
+		{internal_error.NewValidationError("invalid"), http.StatusUnprocessableEntity},
+		{internal_error.NewUnauthorizedError("unauthorized"), http.StatusUnauthorized},
+		{internal_error.NewForbiddenError("forbidden"), http.StatusForbidden},
+		{internal_error.NewNotFoundError("missing"), http.StatusNotFound},
+		{internal_error.NewConflictError("conflict"), http.StatusConflict},
+		{internal_error.NewUnavailableError("unavailable"), http.StatusServiceUnavailable},
+		{internal_error.NewInternalServerError("internal"), http.StatusInternalServerError},
+		{&internal_error.InternalError{Message: "unknown", Code: "unknown"}, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		restErr := ConvertError(test.internalError)
+		if restErr.Code != test.expectedStatus {
+			t.Errorf("Expected %s to map to %d, got %d", test.internalError.Code, test.expectedStatus, restErr.Code)
+		}
+	}
+}
+
+func TestConvertErrorKeepsCodeAndCauses(t *testing.T) {
+	driverErr := errors.New("connection refused")
+	internalError := internal_error.Wrap(
+		internal_error.NewValidationError("invalid auction object",
+			internal_error.Cause{Field: "category", Message: "must be longer than 2 characters"}).Wrap(driverErr),
+		"Error trying to create auction")
+
+	restErr := ConvertError(internalError)
+
+	if restErr.Err != "validation" || restErr.Message != "Error trying to create auction" {
+		t.Errorf("Unexpected error %+v", restErr)
+	}
+	if len(restErr.Causes) != 1 || restErr.Causes[0].Field != "category" {
+		t.Errorf("Expected the category cause, got %+v", restErr.Causes)
+	}
+	if !errors.Is(internalError, driverErr) || !internal_error.Is(internalError, internal_error.Validation) {
+		t.Errorf("Expected the wrapped error to be reachable")
+	}
+}
@@ -0,0 +1,92 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otel_prometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Provider owns the global tracer and meter providers. Metrics are always
+// served for Prometheus scraping; traces and metrics are also pushed over
+// OTLP/HTTP when an endpoint is given.
+type Provider struct {
+	ServiceName string
+
+	tracerProvider *sdktrace.TracerProvider
+	meterProvider  *sdkmetric.MeterProvider
+	metricsHandler http.Handler
+}
+
+func NewProvider(ctx context.Context, serviceName, endpoint string) (*Provider, error) {
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	registry := prometheus.NewRegistry()
+	prometheusExporter, err := otel_prometheus.New(otel_prometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, err
+	}
+
+	traceOptions := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	metricOptions := []sdkmetric.Option{sdkmetric.WithResource(res), sdkmetric.WithReader(prometheusExporter)}
+
+	if endpoint != "" {
+		traceExporter, err := otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithInsecure(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		metricExporter, err := otlpmetrichttp.New(ctx,
+			otlpmetrichttp.WithEndpoint(endpoint),
+			otlpmetrichttp.WithInsecure(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		traceOptions = append(traceOptions, sdktrace.WithBatcher(traceExporter))
+		metricOptions = append(metricOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+	}
+
+	provider := &Provider{
+		ServiceName:    serviceName,
+		tracerProvider: sdktrace.NewTracerProvider(traceOptions...),
+		meterProvider:  sdkmetric.NewMeterProvider(metricOptions...),
+		metricsHandler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}
+
+	otel.SetTracerProvider(provider.tracerProvider)
+	otel.SetMeterProvider(provider.meterProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider, nil
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format.
+func (p *Provider) MetricsHandler() http.Handler {
+	return p.metricsHandler
+}
+
+// Shutdown flushes the spans and metrics still buffered for OTLP.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	return errors.Join(p.tracerProvider.Shutdown(ctx), p.meterProvider.Shutdown(ctx))
+}
@@ -0,0 +1,125 @@
+package attachment_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type AttachmentKind string
+
+const (
+	// Image attachments are product photos; each one gets a thumbnail.
+	Image AttachmentKind = "image"
+	// Document attachments are any other file shown with the auction, like a
+	// manual or a certificate.
+	Document AttachmentKind = "document"
+)
+
+// contentTypeKinds lists the accepted content types. They are sniffed from the
+// file contents, never taken from the client.
+var contentTypeKinds = map[string]AttachmentKind{
+	"image/jpeg":      Image,
+	"image/png":       Image,
+	"image/gif":       Image,
+	"application/pdf": Document,
+	"text/plain":      Document,
+}
+
+const maxFileNameLength = 255
+
+type Attachment struct {
+	Id          string
+	AuctionId   string
+	Kind        AttachmentKind
+	FileName    string
+	ContentType string
+	Size        int64
+	Timestamp   time.Time
+}
+
+func CreateAttachment(
+	auctionId, fileName, contentType string, size int64) (*Attachment, *internal_error.InternalError) {
+	attachment := &Attachment{
+		Id:          uuid.New().String(),
+		AuctionId:   auctionId,
+		Kind:        contentTypeKinds[contentType],
+		FileName:    filepath.Base(strings.ReplaceAll(fileName, "\\", "/")),
+		ContentType: contentType,
+		Size:        size,
+		Timestamp:   time.Now(),
+	}
+
+	if err := attachment.Validate(); err != nil {
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+func (a *Attachment) Validate() *internal_error.InternalError {
+	var causes []internal_error.Cause
+	if a.Kind == "" {
+		causes = append(causes, internal_error.Cause{
+			Field:   "file",
+			Message: "must be a JPEG, PNG or GIF image, a PDF or a plain text file"})
+	}
+	if a.Size <= 0 {
+		causes = append(causes, internal_error.Cause{Field: "file", Message: "must not be empty"})
+	}
+	if a.FileName == "" || a.FileName == "." || a.FileName == "/" ||
+		utf8.RuneCountInString(a.FileName) > maxFileNameLength {
+		causes = append(causes, internal_error.Cause{
+			Field: "file", Message: "file name must have between 1 and 255 characters"})
+	}
+
+	if len(causes) > 0 {
+		return internal_error.NewValidationError("invalid attachment", causes...)
+	}
+
+	return nil
+}
+
+// BlobKey is where the contents of the attachment are stored.
+func (a *Attachment) BlobKey() string {
+	return "auctions/" + a.AuctionId + "/" + a.Id
+}
+
+// ThumbnailKey is where the thumbnail of an image attachment is stored.
+func (a *Attachment) ThumbnailKey() string {
+	return a.BlobKey() + "_thumbnail"
+}
+
+func (a *Attachment) HasThumbnail() bool {
+	return a.Kind == Image
+}
+
+type AttachmentRepositoryInterface interface {
+	CreateAttachment(ctx context.Context, attachment *Attachment) *internal_error.InternalError
+
+	FindAttachmentById(
+		ctx context.Context, attachmentId string) (*Attachment, *internal_error.InternalError)
+
+	// FindAttachmentsByAuctionIds returns the attachments of every given
+	// auction, oldest first, so a page of auctions needs a single lookup.
+	FindAttachmentsByAuctionIds(
+		ctx context.Context, auctionIds []string) ([]Attachment, *internal_error.InternalError)
+
+	DeleteAttachment(ctx context.Context, attachmentId string) *internal_error.InternalError
+}
+
+// BlobStore keeps the contents of the attachments by key. Open returns a not
+// found error for keys that were never stored or were deleted.
+type BlobStore interface {
+	Put(ctx context.Context, key string, content io.Reader) *internal_error.InternalError
+
+	Open(ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError)
+
+	Delete(ctx context.Context, key string) *internal_error.InternalError
+}
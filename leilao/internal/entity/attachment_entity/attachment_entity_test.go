@@ -0,0 +1,51 @@
+package attachment_entity
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateAttachment(t *testing.T) {
+	tests := []struct {
+		name             string
+		fileName         string
+		contentType      string
+		size             int64
+		expectedKind     AttachmentKind
+		expectedFileName string
+		expectedErr      bool
+	}{
+		{name: "image", fileName: "photo.png", contentType: "image/png", size: 10,
+			expectedKind: Image, expectedFileName: "photo.png"},
+		{name: "document", fileName: "manual.pdf", contentType: "application/pdf", size: 10,
+			expectedKind: Document, expectedFileName: "manual.pdf"},
+		{name: "directories are dropped from the name", fileName: `C:\Users\me\photo.jpg`,
+			contentType: "image/jpeg", size: 10, expectedKind: Image, expectedFileName: "photo.jpg"},
+		{name: "unknown content type", fileName: "run.exe", contentType: "application/octet-stream",
+			size: 10, expectedErr: true},
+		{name: "empty file", fileName: "photo.png", contentType: "image/png", expectedErr: true},
+		{name: "missing name", contentType: "image/png", size: 10, expectedErr: true},
+		{name: "long name", fileName: strings.Repeat("a", 256), contentType: "image/png", size: 10,
+			expectedErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attachment, err := CreateAttachment("auction-id", tt.fileName, tt.contentType, tt.size)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("Expected a validation error, got %+v", attachment)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if attachment.Kind != tt.expectedKind || attachment.FileName != tt.expectedFileName {
+				t.Errorf("Expected %s named %q, got %s named %q",
+					tt.expectedKind, tt.expectedFileName, attachment.Kind, attachment.FileName)
+			}
+		})
+	}
+}
@@ -8,16 +8,26 @@ import (
 )
 
 func CreateAuction(
-	productName, category, description string,
-	condition ProductCondition) (*Auction, *internal_error.InternalError) {
+	sellerId, productName, category, description string,
+	condition ProductCondition, reservePrice float64,
+	format AuctionFormat, buyItNowPrice float64, dutchSchedule DutchSchedule) (*Auction, *internal_error.InternalError) {
+	if format == "" {
+		format = English
+	}
+
 	auction := &Auction{
-		Id:          uuid.New().String(),
-		ProductName: productName,
-		Category:    category,
-		Description: description,
-		Condition:   condition,
-		Status:      Active,
-		Timestamp:   time.Now(),
+		Id:            uuid.New().String(),
+		SellerId:      sellerId,
+		ProductName:   productName,
+		Category:      category,
+		Description:   description,
+		Condition:     condition,
+		ReservePrice:  reservePrice,
+		Format:        format,
+		BuyItNowPrice: buyItNowPrice,
+		DutchSchedule: dutchSchedule,
+		Status:        Active,
+		Timestamp:     time.Now(),
 	}
 
 	if err := auction.Validate(); err != nil {
@@ -28,25 +38,85 @@ func CreateAuction(
 }
 
 func (au *Auction) Validate() *internal_error.InternalError {
-	if len(au.ProductName) <= 1 ||
-		len(au.Category) <= 2 ||
-		len(au.Description) <= 10 && (au.Condition != New &&
-			au.Condition != Refurbished &&
-			au.Condition != Used) {
-		return internal_error.NewBadRequestError("invalid auction object")
+	var causes []internal_error.Cause
+	if len(au.ProductName) <= 1 {
+		causes = append(causes, internal_error.Cause{Field: "product_name", Message: "must be longer than 1 character"})
+	}
+	if len(au.Category) <= 2 {
+		causes = append(causes, internal_error.Cause{Field: "category", Message: "must be longer than 2 characters"})
+	}
+	if len(au.Description) <= 10 {
+		causes = append(causes, internal_error.Cause{Field: "description", Message: "must be longer than 10 characters"})
+	}
+	if au.Condition != New && au.Condition != Refurbished && au.Condition != Used {
+		causes = append(causes, internal_error.Cause{Field: "condition", Message: "is not a valid condition"})
+	}
+	if au.ReservePrice < 0 {
+		causes = append(causes, internal_error.Cause{Field: "reserve_price", Message: "must not be negative"})
+	}
+	causes = append(causes, au.validateFormat()...)
+
+	if len(causes) > 0 {
+		return internal_error.NewValidationError("invalid auction object", causes...)
 	}
 
 	return nil
 }
 
+func (au *Auction) Update(
+	productName, category, description *string,
+	condition *ProductCondition) *internal_error.InternalError {
+	if au.Status != Active {
+		return internal_error.NewConflictError("Only active auctions can be edited")
+	}
+	if au.BidCount > 0 {
+		return internal_error.NewConflictError("Auctions can only be edited before the first bid")
+	}
+
+	if productName != nil {
+		au.ProductName = *productName
+	}
+	if category != nil {
+		au.Category = *category
+	}
+	if description != nil {
+		au.Description = *description
+	}
+	if condition != nil {
+		au.Condition = *condition
+	}
+
+	return au.Validate()
+}
+
+// CanBeManagedBy reports whether the user may edit or cancel the auction.
+// Auctions created before sellers were recorded can only be managed by admins.
+func (au *Auction) CanBeManagedBy(userId string, isAdmin bool) bool {
+	return isAdmin || (au.SellerId != "" && au.SellerId == userId)
+}
+
 type Auction struct {
-	Id          string
-	ProductName string
-	Category    string
-	Description string
-	Condition   ProductCondition
-	Status      AuctionStatus
-	Timestamp   time.Time
+	Id              string
+	SellerId        string
+	ProductName     string
+	Category        string
+	Description     string
+	Condition       ProductCondition
+	Status          AuctionStatus
+	Timestamp       time.Time
+	EndTime         time.Time
+	CurrentPrice    float64
+	ReservePrice    float64
+	HighestBidId    string
+	HighestBidderId string
+	BidCount        int64
+
+	Format        AuctionFormat
+	BuyItNowPrice float64
+	DutchSchedule DutchSchedule
+	// SecondPrice is the highest amount bid by anyone other than the highest
+	// bidder of a sealed auction.
+	SecondPrice float64
 }
 
 type ProductCondition int
@@ -55,6 +125,7 @@ type AuctionStatus int
 const (
 	Active AuctionStatus = iota
 	Completed
+	Cancelled
 )
 
 const (
@@ -63,6 +134,65 @@ const (
 	Refurbished
 )
 
+type AuctionSortField string
+
+const (
+	SortByCreated      AuctionSortField = "created"
+	SortByEndTime      AuctionSortField = "end_time"
+	SortByCurrentPrice AuctionSortField = "current_price"
+)
+
+type AuctionFilter struct {
+	Status         *AuctionStatus
+	Category       string
+	ProductName    string
+	Condition      *ProductCondition
+	MinPrice       *float64
+	MaxPrice       *float64
+	EndingBefore   *time.Time
+	SortBy         AuctionSortField
+	SortDescending bool
+	Limit          int
+	Cursor         string
+}
+
+// UsesPrice reports whether the filter selects or orders auctions by their
+// current price. Active sealed auctions are left out of such listings, since
+// their price is their hidden leading bid.
+func (f AuctionFilter) UsesPrice() bool {
+	return f.MinPrice != nil || f.MaxPrice != nil || f.SortBy == SortByCurrentPrice
+}
+
+type AuctionPage struct {
+	Auctions   []Auction
+	Total      int64
+	NextCursor string
+}
+
+type AuctionSearch struct {
+	Query    string
+	Category string
+	Status   *AuctionStatus
+	Limit    int
+	Offset   int
+}
+
+type AuctionSearchResult struct {
+	Auction Auction
+	Score   float64
+}
+
+type CategoryFacet struct {
+	Category string
+	Count    int64
+}
+
+type AuctionSearchPage struct {
+	Results []AuctionSearchResult
+	Total   int64
+	Facets  []CategoryFacet
+}
+
 type AuctionRepositoryInterface interface {
 	CreateAuction(
 		ctx context.Context,
@@ -70,9 +200,23 @@ type AuctionRepositoryInterface interface {
 
 	FindAuctions(
 		ctx context.Context,
-		status AuctionStatus,
-		category, productName string) ([]Auction, *internal_error.InternalError)
+		filter AuctionFilter) (*AuctionPage, *internal_error.InternalError)
 
 	FindAuctionById(
 		ctx context.Context, id string) (*Auction, *internal_error.InternalError)
+
+	SearchAuctions(
+		ctx context.Context,
+		search AuctionSearch) (*AuctionSearchPage, *internal_error.InternalError)
+
+	UpdateAuction(
+		ctx context.Context,
+		auctionEntity *Auction) *internal_error.InternalError
+
+	CancelAuction(
+		ctx context.Context, id string) *internal_error.InternalError
+
+	ExtendAuctionEndTime(
+		ctx context.Context,
+		id string, endTime time.Time) *internal_error.InternalError
 }
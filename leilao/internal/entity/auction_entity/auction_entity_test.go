@@ -0,0 +1,115 @@
+package auction_entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"testing"
+)
+
+func TestValidateReportsEveryInvalidField(t *testing.T) {
+	auction := &Auction{
+		ProductName:  "G",
+		Category:     "Music",
+		Description:  "short",
+		Condition:    ProductCondition(9),
+		ReservePrice: -1,
+	}
+
+	err := auction.Validate()
+	if err == nil || err.Code != internal_error.Validation {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, cause := range err.Causes {
+		fields[cause.Field] = true
+	}
+	for _, field := range []string{"product_name", "description", "condition", "reserve_price"} {
+		if !fields[field] {
+			t.Errorf("Expected a cause for %s, got %+v", field, err.Causes)
+		}
+	}
+	if fields["category"] {
+		t.Errorf("Expected no cause for a valid category, got %+v", err.Causes)
+	}
+}
+
+func TestUpdateOnlyBeforeTheFirstBid(t *testing.T) {
+	productName, description := "Bass", "Vintage electric bass"
+	used := Used
+
+	tests := []struct {
+		name     string
+		status   AuctionStatus
+		bidCount int64
+		code     internal_error.ErrorCode
+	}{
+		{"active auction without bids", Active, 0, ""},
+		{"active auction with bids", Active, 1, internal_error.Conflict},
+		{"completed auction", Completed, 0, internal_error.Conflict},
+		{"cancelled auction", Cancelled, 0, internal_error.Conflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{
+				ProductName: "Guitar",
+				Category:    "Music",
+				Description: "Vintage electric guitar",
+				Condition:   New,
+				Format:      English,
+				Status:      tt.status,
+				BidCount:    tt.bidCount,
+			}
+
+			err := auction.Update(&productName, nil, &description, &used)
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if auction.ProductName != productName || auction.Category != "Music" ||
+					auction.Description != description || auction.Condition != Used {
+					t.Errorf("Expected only the given fields to change, got %+v", auction)
+				}
+				return
+			}
+
+			if err == nil || err.Code != tt.code {
+				t.Fatalf("Expected a %s error, got %v", tt.code, err)
+			}
+			if auction.ProductName != "Guitar" {
+				t.Errorf("Expected a refused update to keep the auction, got %q", auction.ProductName)
+			}
+		})
+	}
+
+	auction := &Auction{ProductName: "Guitar", Category: "Music", Description: "Vintage electric guitar", Format: English}
+	empty := ""
+	if err := auction.Update(&empty, nil, nil, nil); err == nil || err.Code != internal_error.Validation {
+		t.Errorf("Expected an invalid product name to be rejected, got %v", err)
+	}
+}
+
+func TestCanBeManagedBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		sellerId string
+		userId   string
+		isAdmin  bool
+		expected bool
+	}{
+		{"seller", "seller", "seller", false, true},
+		{"other user", "seller", "bidder", false, false},
+		{"admin", "seller", "admin", true, true},
+		{"auction without seller", "", "", false, false},
+		{"admin on auction without seller", "", "admin", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{SellerId: tt.sellerId}
+			if got := auction.CanBeManagedBy(tt.userId, tt.isAdmin); got != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package auction_entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// AuctionFormat decides which bids an auction accepts, who wins it and what
+// the winner pays.
+type AuctionFormat string
+
+const (
+	// English auctions take ascending open bids; the highest one wins and
+	// pays its amount. A buy-it-now price closes the auction as soon as a
+	// bid reaches it.
+	English AuctionFormat = "english"
+	// SealedFirstPrice auctions hide the bids until the auction ends; the
+	// highest one wins and pays its amount.
+	SealedFirstPrice AuctionFormat = "sealed_first_price"
+	// SealedSecondPrice (Vickrey) auctions hide the bids until the auction
+	// ends; the highest one wins and pays the second-highest amount.
+	SealedSecondPrice AuctionFormat = "sealed_second_price"
+	// Dutch auctions lower the asking price over time; the first bid that
+	// meets it wins at that price and closes the auction.
+	Dutch AuctionFormat = "dutch"
+)
+
+func (f AuctionFormat) Valid() bool {
+	switch f {
+	case English, SealedFirstPrice, SealedSecondPrice, Dutch:
+		return true
+	}
+	return false
+}
+
+func (f AuctionFormat) Sealed() bool {
+	return f == SealedFirstPrice || f == SealedSecondPrice
+}
+
+// DutchSchedule lowers the asking price of a Dutch auction by Decrement every
+// Interval, starting at StartPrice and never going below FloorPrice.
+type DutchSchedule struct {
+	StartPrice float64
+	Decrement  float64
+	Interval   time.Duration
+	FloorPrice float64
+}
+
+// PriceAt returns the asking price at the given time of an auction started
+// at start.
+func (ds DutchSchedule) PriceAt(start, at time.Time) float64 {
+	var steps int64
+	if ds.Interval > 0 && at.After(start) {
+		steps = int64(at.Sub(start) / ds.Interval)
+	}
+
+	price := ds.StartPrice - float64(steps)*ds.Decrement
+	if price < ds.FloorPrice {
+		return ds.FloorPrice
+	}
+
+	return price
+}
+
+func (au *Auction) validateFormat() []internal_error.Cause {
+	var causes []internal_error.Cause
+	if !au.Format.Valid() {
+		return append(causes, internal_error.Cause{Field: "format", Message: "is not a valid auction format"})
+	}
+
+	if au.BuyItNowPrice < 0 {
+		causes = append(causes, internal_error.Cause{Field: "buy_it_now_price", Message: "must not be negative"})
+	} else if au.BuyItNowPrice > 0 && au.Format != English {
+		causes = append(causes, internal_error.Cause{Field: "buy_it_now_price", Message: "is only allowed on english auctions"})
+	} else if au.BuyItNowPrice > 0 && au.BuyItNowPrice < au.ReservePrice {
+		causes = append(causes, internal_error.Cause{Field: "buy_it_now_price", Message: "must not be lower than reserve_price"})
+	}
+
+	if au.Format != Dutch {
+		if au.DutchSchedule != (DutchSchedule{}) {
+			causes = append(causes, internal_error.Cause{Field: "dutch", Message: "is only allowed on dutch auctions"})
+		}
+		return causes
+	}
+
+	schedule := au.DutchSchedule
+	if schedule.StartPrice <= 0 {
+		causes = append(causes, internal_error.Cause{Field: "dutch.start_price", Message: "must be greater than 0"})
+	}
+	if schedule.Decrement <= 0 {
+		causes = append(causes, internal_error.Cause{Field: "dutch.decrement", Message: "must be greater than 0"})
+	}
+	if schedule.Interval < time.Second {
+		causes = append(causes, internal_error.Cause{Field: "dutch.interval_seconds", Message: "must be at least 1"})
+	}
+	if schedule.FloorPrice < 0 || schedule.FloorPrice >= schedule.StartPrice {
+		causes = append(causes, internal_error.Cause{Field: "dutch.floor_price", Message: "must be between 0 and start_price"})
+	}
+	if au.ReservePrice > 0 {
+		causes = append(causes, internal_error.Cause{Field: "reserve_price", Message: "must be 0 on dutch auctions, use dutch.floor_price"})
+	}
+
+	return causes
+}
+
+// IsClassicEnglish reports whether accepting a bid only takes beating the
+// current price, so repositories can place it with a single conditional
+// update instead of going through ApplyBid.
+func (au *Auction) IsClassicEnglish() bool {
+	return au.Format == English && au.BuyItNowPrice == 0
+}
+
+// BidsSealed reports whether the bids of the auction must stay hidden.
+func (au *Auction) BidsSealed() bool {
+	return au.Format.Sealed() && au.Status == Active
+}
+
+// AskingPrice is the price shown for the auction at the given time: the
+// scheduled price of a Dutch auction still waiting for its bid, and the
+// current price otherwise.
+func (au *Auction) AskingPrice(now time.Time) float64 {
+	if au.Format == Dutch && au.Status == Active && au.HighestBidId == "" {
+		return au.DutchSchedule.PriceAt(au.Timestamp, now)
+	}
+
+	return au.CurrentPrice
+}
+
+// BidPlacement is the outcome of ApplyBid.
+type BidPlacement struct {
+	Accepted bool
+	// Closes is set when the bid ends the auction on the spot, like a bid
+	// reaching the buy-it-now price or the asking price of a Dutch auction.
+	Closes bool
+}
+
+// ApplyBid applies a bid to the auction following the rules of its format
+// and reports whether it was accepted. A rejected bid leaves the auction
+// untouched.
+func (au *Auction) ApplyBid(
+	bidId, bidderId string, amount float64, bidTime time.Time) BidPlacement {
+	if au.Status != Active || bidTime.Unix() > au.EndTime.Unix() {
+		return BidPlacement{}
+	}
+
+	switch au.Format {
+	case SealedFirstPrice, SealedSecondPrice:
+		au.applySealedBid(bidId, bidderId, amount)
+		return BidPlacement{Accepted: true}
+
+	case Dutch:
+		price := au.DutchSchedule.PriceAt(au.Timestamp, bidTime)
+		if au.HighestBidId != "" || amount < price {
+			return BidPlacement{}
+		}
+
+		au.placeHighestBid(bidId, bidderId, price)
+		au.closeOnBid(bidTime)
+		return BidPlacement{Accepted: true, Closes: true}
+
+	default:
+		if amount <= au.CurrentPrice ||
+			(au.BuyItNowPrice > 0 && au.CurrentPrice >= au.BuyItNowPrice) {
+			return BidPlacement{}
+		}
+
+		if au.BuyItNowPrice > 0 && amount >= au.BuyItNowPrice {
+			au.placeHighestBid(bidId, bidderId, au.BuyItNowPrice)
+			au.closeOnBid(bidTime)
+			return BidPlacement{Accepted: true, Closes: true}
+		}
+
+		au.placeHighestBid(bidId, bidderId, amount)
+		return BidPlacement{Accepted: true}
+	}
+}
+
+// applySealedBid keeps the highest bid and, in SecondPrice, the highest
+// amount bid by anyone other than its bidder. Ties go to the earlier bid.
+func (au *Auction) applySealedBid(bidId, bidderId string, amount float64) {
+	au.BidCount++
+
+	if amount > au.CurrentPrice {
+		if au.HighestBidderId != "" && au.HighestBidderId != bidderId && au.CurrentPrice > au.SecondPrice {
+			au.SecondPrice = au.CurrentPrice
+		}
+		au.CurrentPrice = amount
+		au.HighestBidId = bidId
+		au.HighestBidderId = bidderId
+		return
+	}
+
+	if bidderId != au.HighestBidderId && amount > au.SecondPrice {
+		au.SecondPrice = amount
+	}
+}
+
+func (au *Auction) placeHighestBid(bidId, bidderId string, price float64) {
+	au.CurrentPrice = price
+	au.HighestBidId = bidId
+	au.HighestBidderId = bidderId
+	au.BidCount++
+}
+
+func (au *Auction) closeOnBid(bidTime time.Time) {
+	au.Status = Completed
+	au.EndTime = bidTime
+}
+
+// Payment is what the winner of the auction pays. Second-price auctions
+// charge the second-highest bid, or the reserve price when it is higher; a
+// lone bid without a reserve price pays its own amount.
+func (au *Auction) Payment() float64 {
+	if au.Format != SealedSecondPrice {
+		return au.CurrentPrice
+	}
+
+	payment := au.SecondPrice
+	if au.ReservePrice > payment {
+		payment = au.ReservePrice
+	}
+	if payment == 0 {
+		return au.CurrentPrice
+	}
+
+	return payment
+}
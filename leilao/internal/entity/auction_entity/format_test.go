@@ -0,0 +1,202 @@
+package auction_entity
+
+import (
+	"testing"
+	"time"
+)
+
+type testBid struct {
+	id       string
+	bidderId string
+	amount   float64
+}
+
+func activeAuction(format AuctionFormat) *Auction {
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	return &Auction{
+		Format:    format,
+		Status:    Active,
+		Timestamp: start,
+		EndTime:   start.Add(time.Hour),
+	}
+}
+
+func TestApplyBidWinnerAndPaymentPerFormat(t *testing.T) {
+	tests := []struct {
+		name             string
+		auction          *Auction
+		bids             []testBid
+		expectedRejected []string
+		expectedWinner   string
+		expectedPayment  float64
+		expectedStatus   AuctionStatus
+	}{
+		{
+			name:             "english takes ascending bids and charges the highest",
+			auction:          activeAuction(English),
+			bids:             []testBid{{"b1", "u1", 100}, {"b2", "u2", 90}, {"b3", "u2", 150}},
+			expectedRejected: []string{"b2"},
+			expectedWinner:   "u2",
+			expectedPayment:  150,
+			expectedStatus:   Active,
+		},
+		{
+			name: "buy-it-now closes the auction at its price",
+			auction: func() *Auction {
+				auction := activeAuction(English)
+				auction.BuyItNowPrice = 200
+				return auction
+			}(),
+			bids:             []testBid{{"b1", "u1", 100}, {"b2", "u2", 250}, {"b3", "u1", 300}},
+			expectedRejected: []string{"b3"},
+			expectedWinner:   "u2",
+			expectedPayment:  200,
+			expectedStatus:   Completed,
+		},
+		{
+			name:            "sealed first-price accepts every bid and charges the highest",
+			auction:         activeAuction(SealedFirstPrice),
+			bids:            []testBid{{"b1", "u1", 100}, {"b2", "u2", 300}, {"b3", "u3", 200}},
+			expectedWinner:  "u2",
+			expectedPayment: 300,
+			expectedStatus:  Active,
+		},
+		{
+			name:            "vickrey charges the second-highest bid",
+			auction:         activeAuction(SealedSecondPrice),
+			bids:            []testBid{{"b1", "u1", 100}, {"b2", "u2", 300}, {"b3", "u3", 200}},
+			expectedWinner:  "u2",
+			expectedPayment: 200,
+			expectedStatus:  Active,
+		},
+		{
+			name:            "vickrey ignores the winner's own lower bids",
+			auction:         activeAuction(SealedSecondPrice),
+			bids:            []testBid{{"b1", "u1", 100}, {"b2", "u2", 250}, {"b3", "u2", 300}},
+			expectedWinner:  "u2",
+			expectedPayment: 100,
+			expectedStatus:  Active,
+		},
+		{
+			name:            "vickrey tie goes to the earlier bid at the tied amount",
+			auction:         activeAuction(SealedSecondPrice),
+			bids:            []testBid{{"b1", "u1", 200}, {"b2", "u2", 200}},
+			expectedWinner:  "u1",
+			expectedPayment: 200,
+			expectedStatus:  Active,
+		},
+		{
+			name: "vickrey lone bid pays the reserve price",
+			auction: func() *Auction {
+				auction := activeAuction(SealedSecondPrice)
+				auction.ReservePrice = 50
+				return auction
+			}(),
+			bids:            []testBid{{"b1", "u1", 120}},
+			expectedWinner:  "u1",
+			expectedPayment: 50,
+			expectedStatus:  Active,
+		},
+		{
+			name: "dutch sells to the first bid meeting the asking price",
+			auction: func() *Auction {
+				auction := activeAuction(Dutch)
+				auction.DutchSchedule = DutchSchedule{
+					StartPrice: 1000, Decrement: 100, Interval: time.Minute, FloorPrice: 500}
+				return auction
+			}(),
+			bids:             []testBid{{"b1", "u1", 1000}, {"b2", "u2", 1000}},
+			expectedRejected: []string{"b2"},
+			expectedWinner:   "u1",
+			expectedPayment:  1000,
+			expectedStatus:   Completed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rejected := map[string]bool{}
+			for _, bid := range tt.bids {
+				placement := tt.auction.ApplyBid(bid.id, bid.bidderId, bid.amount, tt.auction.Timestamp)
+				if !placement.Accepted {
+					rejected[bid.id] = true
+				}
+			}
+
+			if len(rejected) != len(tt.expectedRejected) {
+				t.Errorf("Expected rejected bids %v, got %v", tt.expectedRejected, rejected)
+			}
+			for _, id := range tt.expectedRejected {
+				if !rejected[id] {
+					t.Errorf("Expected bid %s to be rejected", id)
+				}
+			}
+
+			if tt.auction.HighestBidderId != tt.expectedWinner {
+				t.Errorf("Expected winner %s, got %s", tt.expectedWinner, tt.auction.HighestBidderId)
+			}
+			if payment := tt.auction.Payment(); payment != tt.expectedPayment {
+				t.Errorf("Expected payment %v, got %v", tt.expectedPayment, payment)
+			}
+			if tt.auction.Status != tt.expectedStatus {
+				t.Errorf("Expected status %v, got %v", tt.expectedStatus, tt.auction.Status)
+			}
+		})
+	}
+}
+
+func TestApplyBidDutchFollowsTheSchedule(t *testing.T) {
+	auction := activeAuction(Dutch)
+	auction.DutchSchedule = DutchSchedule{
+		StartPrice: 1000, Decrement: 100, Interval: time.Minute, FloorPrice: 500}
+
+	bidTime := auction.Timestamp.Add(150 * time.Second)
+	if placement := auction.ApplyBid("b1", "u1", 750, bidTime); placement.Accepted {
+		t.Fatal("Expected a bid below the asking price to be rejected")
+	}
+
+	placement := auction.ApplyBid("b2", "u2", 900, bidTime)
+	if !placement.Accepted || !placement.Closes {
+		t.Fatalf("Expected the bid to be accepted and close the auction, got %+v", placement)
+	}
+	if auction.CurrentPrice != 800 || !auction.EndTime.Equal(bidTime) {
+		t.Errorf("Expected to sell at 800 at %v, got %v at %v", bidTime, auction.CurrentPrice, auction.EndTime)
+	}
+
+	if price := auction.DutchSchedule.PriceAt(auction.Timestamp, auction.Timestamp.Add(time.Hour)); price != 500 {
+		t.Errorf("Expected the price to stop at the floor, got %v", price)
+	}
+}
+
+func TestApplyBidRejectsBidsAfterTheEnd(t *testing.T) {
+	auction := activeAuction(SealedFirstPrice)
+
+	if placement := auction.ApplyBid("b1", "u1", 100, auction.EndTime.Add(time.Second)); placement.Accepted {
+		t.Error("Expected a bid after the end time to be rejected")
+	}
+	if auction.BidCount != 0 {
+		t.Errorf("Expected the auction to be untouched, got %d bids", auction.BidCount)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	auction := activeAuction(Dutch)
+	auction.BuyItNowPrice = 100
+	auction.ReservePrice = 10
+	auction.DutchSchedule = DutchSchedule{StartPrice: 100, FloorPrice: 200}
+
+	fields := map[string]bool{}
+	for _, cause := range auction.validateFormat() {
+		fields[cause.Field] = true
+	}
+
+	for _, field := range []string{
+		"buy_it_now_price", "reserve_price", "dutch.decrement", "dutch.interval_seconds", "dutch.floor_price"} {
+		if !fields[field] {
+			t.Errorf("Expected a cause for %s, got %v", field, fields)
+		}
+	}
+	if fields["dutch.start_price"] {
+		t.Errorf("Expected no cause for a valid start price, got %v", fields)
+	}
+}
@@ -0,0 +1,38 @@
+package auction_entity
+
+import "time"
+
+// SoftClosePolicy extends an auction when a bid lands in its final Window,
+// pushing the end time by Extension without going past MaxExtension over
+// the originally scheduled end.
+type SoftClosePolicy struct {
+	Window       time.Duration
+	Extension    time.Duration
+	MaxExtension time.Duration
+}
+
+func (p SoftClosePolicy) Enabled() bool {
+	return p.Window > 0 && p.Extension > 0 && p.MaxExtension > 0
+}
+
+func (p SoftClosePolicy) InWindow(endTime, bidTime time.Time) bool {
+	return p.Enabled() && !bidTime.After(endTime) && endTime.Sub(bidTime) <= p.Window
+}
+
+func (p SoftClosePolicy) NextEndTime(
+	originalEndTime, endTime, bidTime time.Time) (time.Time, bool) {
+	if !p.InWindow(endTime, bidTime) {
+		return endTime, false
+	}
+
+	nextEndTime := endTime.Add(p.Extension)
+	if maxEndTime := originalEndTime.Add(p.MaxExtension); nextEndTime.After(maxEndTime) {
+		nextEndTime = maxEndTime
+	}
+
+	if !nextEndTime.After(endTime) {
+		return endTime, false
+	}
+
+	return nextEndTime, true
+}
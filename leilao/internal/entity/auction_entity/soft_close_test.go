@@ -0,0 +1,81 @@
+package auction_entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSoftClosePolicyNextEndTime(t *testing.T) {
+	policy := SoftClosePolicy{
+		Window:       30 * time.Second,
+		Extension:    time.Minute,
+		MaxExtension: 90 * time.Second,
+	}
+	originalEndTime := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		policy          SoftClosePolicy
+		endTime         time.Time
+		bidTime         time.Time
+		expectedEndTime time.Time
+		expectedOk      bool
+	}{
+		{
+			name:            "bid before the window keeps the end time",
+			policy:          policy,
+			endTime:         originalEndTime,
+			bidTime:         originalEndTime.Add(-time.Minute),
+			expectedEndTime: originalEndTime,
+		},
+		{
+			name:            "bid inside the window extends the end time",
+			policy:          policy,
+			endTime:         originalEndTime,
+			bidTime:         originalEndTime.Add(-10 * time.Second),
+			expectedEndTime: originalEndTime.Add(time.Minute),
+			expectedOk:      true,
+		},
+		{
+			name:            "extension is capped by the maximum",
+			policy:          policy,
+			endTime:         originalEndTime.Add(time.Minute),
+			bidTime:         originalEndTime.Add(50 * time.Second),
+			expectedEndTime: originalEndTime.Add(90 * time.Second),
+			expectedOk:      true,
+		},
+		{
+			name:            "maximum already reached",
+			policy:          policy,
+			endTime:         originalEndTime.Add(90 * time.Second),
+			bidTime:         originalEndTime.Add(80 * time.Second),
+			expectedEndTime: originalEndTime.Add(90 * time.Second),
+		},
+		{
+			name:            "bid after the end time",
+			policy:          policy,
+			endTime:         originalEndTime,
+			bidTime:         originalEndTime.Add(time.Second),
+			expectedEndTime: originalEndTime,
+		},
+		{
+			name:            "disabled policy",
+			policy:          SoftClosePolicy{},
+			endTime:         originalEndTime,
+			bidTime:         originalEndTime.Add(-time.Second),
+			expectedEndTime: originalEndTime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endTime, ok := tt.policy.NextEndTime(originalEndTime, tt.endTime, tt.bidTime)
+			if ok != tt.expectedOk {
+				t.Errorf("Expected ok %v, got %v", tt.expectedOk, ok)
+			}
+			if !endTime.Equal(tt.expectedEndTime) {
+				t.Errorf("Expected %v, got %v", tt.expectedEndTime, endTime)
+			}
+		})
+	}
+}
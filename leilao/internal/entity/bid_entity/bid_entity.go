@@ -12,6 +12,7 @@ type Bid struct {
 	UserId    string
 	AuctionId string
 	Amount    float64
+	Automatic bool
 	Timestamp time.Time
 }
 
@@ -31,26 +32,108 @@ func CreateBid(userId, auctionId string, amount float64) (*Bid, *internal_error.
 	return bid, nil
 }
 
+func CreateAutomaticBid(userId, auctionId string, amount float64) (*Bid, *internal_error.InternalError) {
+	bid, err := CreateBid(userId, auctionId, amount)
+	if err != nil {
+		return nil, err
+	}
+
+	bid.Automatic = true
+	return bid, nil
+}
+
 func (b *Bid) Validate() *internal_error.InternalError {
 	if err := uuid.Validate(b.UserId); err != nil {
-		return internal_error.NewBadRequestError("UserId is not a valid id")
+		return internal_error.NewValidationError("UserId is not a valid id",
+			internal_error.Cause{Field: "user_id", Message: "must be a valid id"})
 	} else if err := uuid.Validate(b.AuctionId); err != nil {
-		return internal_error.NewBadRequestError("AuctionId is not a valid id")
+		return internal_error.NewValidationError("AuctionId is not a valid id",
+			internal_error.Cause{Field: "auction_id", Message: "must be a valid id"})
 	} else if b.Amount <= 0 {
-		return internal_error.NewBadRequestError("Amount is not a valid value")
+		return internal_error.NewValidationError("Amount is not a valid value",
+			internal_error.Cause{Field: "amount", Message: "must be greater than 0"})
 	}
 
 	return nil
 }
 
+type BidFailureReason string
+
+const (
+	AuctionNotFound    BidFailureReason = "auction_not_found"
+	AuctionUnavailable BidFailureReason = "auction_unavailable"
+	AuctionClosed      BidFailureReason = "auction_closed"
+	// BidRejected means the bid did not beat the highest bid, or the auction
+	// closed before it could be placed.
+	BidRejected  BidFailureReason = "bid_rejected"
+	InsertFailed BidFailureReason = "insert_failed"
+)
+
+// BidFailure reports a bid of a batch that was not stored. Index is the
+// position of the bid in the batch.
+type BidFailure struct {
+	Index  int
+	BidId  string
+	Reason BidFailureReason
+}
+
+// Retryable tells whether the bid failed for a transient reason and may be
+// stored by submitting it again, as opposed to being refused by the auction.
+func (f BidFailure) Retryable() bool {
+	return f.Reason == AuctionUnavailable || f.Reason == InsertFailed
+}
+
+// OutbidEvent is emitted when a user who led an english auction, or placed a
+// bid in the same batch, is no longer its highest bidder.
+type OutbidEvent struct {
+	AuctionId    string
+	UserId       string
+	LeadingBidId string
+	CurrentPrice float64
+}
+
+// BidFilter selects the bids of an auction, of a user or of both, ordered by
+// time. Cursor continues the page that returned it.
+type BidFilter struct {
+	AuctionId      string
+	UserId         string
+	SortDescending bool
+	Limit          int
+	Cursor         string
+}
+
+// BidStats summarizes every bid matched by a filter, not only one page.
+type BidStats struct {
+	Count         int64
+	Bidders       int64
+	Auctions      int64
+	HighestAmount float64
+	AverageAmount float64
+	FirstBidAt    time.Time
+	LastBidAt     time.Time
+}
+
+type BidPage struct {
+	Bids       []Bid
+	Stats      BidStats
+	NextCursor string
+}
+
 type BidEntityRepository interface {
+	// CreateBid stores a batch of bids and returns the ones that were not
+	// stored, in batch order. The error is set only when the batch could not
+	// be written at all.
 	CreateBid(
 		ctx context.Context,
-		bidEntities []Bid) *internal_error.InternalError
+		bidEntities []Bid) ([]BidFailure, *internal_error.InternalError)
 
+	// FindBidByAuctionId returns every bid of the auction, oldest first.
 	FindBidByAuctionId(
 		ctx context.Context, auctionId string) ([]Bid, *internal_error.InternalError)
 
+	FindBids(
+		ctx context.Context, filter BidFilter) (*BidPage, *internal_error.InternalError)
+
 	FindWinningBidByAuctionId(
 		ctx context.Context, auctionId string) (*Bid, *internal_error.InternalError)
 }
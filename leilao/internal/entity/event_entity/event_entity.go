@@ -0,0 +1,87 @@
+package event_entity
+
+import (
+	"context"
+	"encoding/json"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	AuctionCreated EventType = "auction.created"
+	BidAccepted    EventType = "bid.accepted"
+	AuctionClosed  EventType = "auction.closed"
+)
+
+var EventTypes = []EventType{AuctionCreated, BidAccepted, AuctionClosed}
+
+// Event is a domain event stored in the outbox. Payload holds the JSON
+// envelope delivered to webhooks, so every delivery of an event sends the
+// same bytes.
+type Event struct {
+	Id          string
+	Type        EventType
+	AggregateId string
+	Payload     []byte
+	Timestamp   time.Time
+}
+
+type eventEnvelope struct {
+	Id        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func CreateEvent(eventType EventType, aggregateId string, data interface{}) (*Event, *internal_error.InternalError) {
+	event := &Event{
+		Id:          uuid.New().String(),
+		Type:        eventType,
+		AggregateId: aggregateId,
+		Timestamp:   time.Now(),
+	}
+
+	payload, err := json.Marshal(eventEnvelope{
+		Id:        event.Id,
+		Type:      event.Type,
+		CreatedAt: event.Timestamp,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, internal_error.NewInternalServerError("Error trying to encode event payload").Wrap(err)
+	}
+
+	event.Payload = payload
+	return event, nil
+}
+
+type AuctionCreatedData struct {
+	AuctionId   string    `json:"auction_id"`
+	SellerId    string    `json:"seller_id"`
+	ProductName string    `json:"product_name"`
+	Category    string    `json:"category"`
+	Format      string    `json:"format"`
+	EndTime     time.Time `json:"end_time"`
+}
+
+type BidAcceptedData struct {
+	BidId     string    `json:"bid_id"`
+	AuctionId string    `json:"auction_id"`
+	UserId    string    `json:"user_id"`
+	Amount    float64   `json:"amount"`
+	Automatic bool      `json:"automatic"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type OutboxRepositoryInterface interface {
+	AppendEvent(ctx context.Context, event *Event) *internal_error.InternalError
+
+	FindUnpublishedEvents(
+		ctx context.Context, limit int64) ([]Event, *internal_error.InternalError)
+
+	MarkEventPublished(ctx context.Context, eventId string) *internal_error.InternalError
+}
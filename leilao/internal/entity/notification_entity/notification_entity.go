@@ -0,0 +1,82 @@
+package notification_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	// Outbid is sent to a user whose bid led an english auction and was
+	// beaten.
+	Outbid NotificationType = "outbid"
+	// EndingSoon is sent to the watchers and bidders of an auction about to
+	// end.
+	EndingSoon NotificationType = "ending_soon"
+	// AuctionWon and AuctionLost are sent to the bidders of an auction once
+	// it is settled.
+	AuctionWon  NotificationType = "auction_won"
+	AuctionLost NotificationType = "auction_lost"
+)
+
+type Notification struct {
+	Id        string
+	UserId    string
+	Type      NotificationType
+	AuctionId string
+	Message   string
+	Amount    float64
+	Timestamp time.Time
+}
+
+// CreateNotification derives the id from what is being notified: the type,
+// the user, the auction and a key telling occurrences apart, like the bid
+// that outbid the user. The same fact notified twice, by two replicas or by a
+// repeated scan, is then stored once.
+func CreateNotification(
+	notificationType NotificationType,
+	userId, auctionId, key, message string,
+	amount float64) *Notification {
+	name := strings.Join([]string{string(notificationType), userId, auctionId, key}, ":")
+
+	return &Notification{
+		Id:        uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String(),
+		UserId:    userId,
+		Type:      notificationType,
+		AuctionId: auctionId,
+		Message:   message,
+		Amount:    amount,
+		Timestamp: time.Now(),
+	}
+}
+
+// Recipient is the user a notification is sent to.
+type Recipient struct {
+	UserId string
+	Name   string
+	Email  string
+}
+
+// Channel delivers recorded notifications outside the API, like by email.
+type Channel interface {
+	Name() string
+
+	Send(ctx context.Context, recipient Recipient, notification *Notification) error
+}
+
+type NotificationRepositoryInterface interface {
+	// CreateNotification reports false when the notification was already
+	// stored.
+	CreateNotification(
+		ctx context.Context, notification *Notification) (bool, *internal_error.InternalError)
+
+	// FindNotificationsByUserId returns the latest notifications of the user,
+	// newest first.
+	FindNotificationsByUserId(
+		ctx context.Context, userId string, limit int64) ([]Notification, *internal_error.InternalError)
+}
@@ -0,0 +1,21 @@
+package notification_entity
+
+import "testing"
+
+func TestCreateNotificationIdIsDeterministic(t *testing.T) {
+	first := CreateNotification(Outbid, "user", "auction", "bid-1", "first", 10)
+	again := CreateNotification(Outbid, "user", "auction", "bid-1", "again", 20)
+	if first.Id != again.Id {
+		t.Errorf("Expected the same notification to keep its id, got %s and %s", first.Id, again.Id)
+	}
+
+	for _, other := range []*Notification{
+		CreateNotification(Outbid, "user", "auction", "bid-2", "", 0),
+		CreateNotification(EndingSoon, "user", "auction", "bid-1", "", 0),
+		CreateNotification(Outbid, "other", "auction", "bid-1", "", 0),
+	} {
+		if other.Id == first.Id {
+			t.Errorf("Expected a different notification to get another id, got %s for %+v", other.Id, other)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package proxy_bid_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProxyBid struct {
+	Id        string
+	UserId    string
+	AuctionId string
+	MaxAmount float64
+	Timestamp time.Time
+}
+
+func CreateProxyBid(userId, auctionId string, maxAmount float64) (*ProxyBid, *internal_error.InternalError) {
+	proxyBid := &ProxyBid{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		AuctionId: auctionId,
+		MaxAmount: maxAmount,
+		Timestamp: time.Now(),
+	}
+
+	if err := proxyBid.Validate(); err != nil {
+		return nil, err
+	}
+
+	return proxyBid, nil
+}
+
+func (pb *ProxyBid) Validate() *internal_error.InternalError {
+	if err := uuid.Validate(pb.UserId); err != nil {
+		return internal_error.NewValidationError("UserId is not a valid id",
+			internal_error.Cause{Field: "user_id", Message: "must be a valid id"})
+	} else if err := uuid.Validate(pb.AuctionId); err != nil {
+		return internal_error.NewValidationError("AuctionId is not a valid id",
+			internal_error.Cause{Field: "auction_id", Message: "must be a valid id"})
+	} else if pb.MaxAmount <= 0 {
+		return internal_error.NewValidationError("MaxAmount is not a valid value",
+			internal_error.Cause{Field: "max_amount", Message: "must be greater than 0"})
+	}
+
+	return nil
+}
+
+// ResolveProxyBids returns the automatic bids needed for the strongest proxy
+// to lead the auction by the minimum increment. Proxies are ranked by maximum
+// amount and, on a tie, by who registered first, so two competing proxies
+// always resolve the same way: the runner-up bids its maximum and the leader
+// bids just above it.
+func ResolveProxyBids(
+	highestBid *bid_entity.Bid,
+	proxyBids []ProxyBid,
+	increment float64) []bid_entity.Bid {
+	var highestAmount float64
+	var highestUserId string
+	if highestBid != nil {
+		highestAmount = highestBid.Amount
+		highestUserId = highestBid.UserId
+	}
+
+	candidates := rankProxyBids(proxyBids, highestAmount, highestUserId)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	leader := candidates[0]
+
+	var automaticBids []bid_entity.Bid
+	var competingAmount float64
+	if highestUserId != leader.UserId {
+		competingAmount = highestAmount
+	}
+
+	if len(candidates) > 1 {
+		runnerUp := candidates[1]
+		competingAmount = math.Max(competingAmount, runnerUp.MaxAmount)
+
+		if runnerUp.MaxAmount < leader.MaxAmount &&
+			(runnerUp.UserId != highestUserId || runnerUp.MaxAmount > highestAmount) {
+			if bid, err := bid_entity.CreateAutomaticBid(
+				runnerUp.UserId, runnerUp.AuctionId, runnerUp.MaxAmount); err == nil {
+				automaticBids = append(automaticBids, *bid)
+			}
+		}
+	}
+
+	target := roundAmount(math.Min(leader.MaxAmount, competingAmount+increment))
+	if highestUserId == leader.UserId && highestAmount >= target {
+		return automaticBids
+	}
+	if target <= highestAmount {
+		return automaticBids
+	}
+
+	if bid, err := bid_entity.CreateAutomaticBid(leader.UserId, leader.AuctionId, target); err == nil {
+		automaticBids = append(automaticBids, *bid)
+	}
+
+	return automaticBids
+}
+
+// rankProxyBids keeps the highest proxy of each user that can still compete
+// with the current highest bid, ordered from strongest to weakest.
+func rankProxyBids(
+	proxyBids []ProxyBid, highestAmount float64, highestUserId string) []ProxyBid {
+	sorted := make([]ProxyBid, len(proxyBids))
+	copy(sorted, proxyBids)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].MaxAmount != sorted[j].MaxAmount {
+			return sorted[i].MaxAmount > sorted[j].MaxAmount
+		}
+		if !sorted[i].Timestamp.Equal(sorted[j].Timestamp) {
+			return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	seenUsers := make(map[string]bool)
+	var candidates []ProxyBid
+	for _, proxyBid := range sorted {
+		if seenUsers[proxyBid.UserId] {
+			continue
+		}
+		seenUsers[proxyBid.UserId] = true
+
+		if proxyBid.MaxAmount <= highestAmount && proxyBid.UserId != highestUserId {
+			continue
+		}
+		candidates = append(candidates, proxyBid)
+	}
+
+	return candidates
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+type ProxyBidRepositoryInterface interface {
+	CreateProxyBid(
+		ctx context.Context,
+		proxyBidEntity *ProxyBid) *internal_error.InternalError
+
+	FindProxyBidsByAuctionId(
+		ctx context.Context, auctionId string) ([]ProxyBid, *internal_error.InternalError)
+}
@@ -0,0 +1,98 @@
+package proxy_bid_entity
+
+import (
+	"testing"
+	"time"
+
+	"fullcycle-auction_go/internal/entity/bid_entity"
+
+	"github.com/google/uuid"
+)
+
+func TestResolveProxyBids(t *testing.T) {
+	auctionId := uuid.New().String()
+	alice := uuid.New().String()
+	bob := uuid.New().String()
+	carol := uuid.New().String()
+	now := time.Now()
+
+	proxy := func(userId string, maxAmount float64, offset time.Duration) ProxyBid {
+		return ProxyBid{
+			Id:        uuid.New().String(),
+			UserId:    userId,
+			AuctionId: auctionId,
+			MaxAmount: maxAmount,
+			Timestamp: now.Add(offset),
+		}
+	}
+
+	type expectedBid struct {
+		userId string
+		amount float64
+	}
+
+	tests := []struct {
+		name       string
+		highestBid *bid_entity.Bid
+		proxyBids  []ProxyBid
+		expected   []expectedBid
+	}{
+		{
+			name:      "single proxy opens the auction with the increment",
+			proxyBids: []ProxyBid{proxy(alice, 100, 0)},
+			expected:  []expectedBid{{alice, 1}},
+		},
+		{
+			name:       "proxy outbids a manual bid by the increment",
+			highestBid: &bid_entity.Bid{UserId: carol, AuctionId: auctionId, Amount: 50},
+			proxyBids:  []ProxyBid{proxy(alice, 100, 0)},
+			expected:   []expectedBid{{alice, 51}},
+		},
+		{
+			name:       "proxy caps its bid at the maximum",
+			highestBid: &bid_entity.Bid{UserId: carol, AuctionId: auctionId, Amount: 99.5},
+			proxyBids:  []ProxyBid{proxy(alice, 100, 0)},
+			expected:   []expectedBid{{alice, 100}},
+		},
+		{
+			name:       "exhausted proxy does not bid",
+			highestBid: &bid_entity.Bid{UserId: carol, AuctionId: auctionId, Amount: 150},
+			proxyBids:  []ProxyBid{proxy(alice, 100, 0)},
+		},
+		{
+			name:       "leading proxy does not bid against itself",
+			highestBid: &bid_entity.Bid{UserId: alice, AuctionId: auctionId, Amount: 51},
+			proxyBids:  []ProxyBid{proxy(alice, 100, 0)},
+		},
+		{
+			name:       "two proxies resolve to runner-up maximum plus increment",
+			highestBid: &bid_entity.Bid{UserId: carol, AuctionId: auctionId, Amount: 10},
+			proxyBids:  []ProxyBid{proxy(bob, 80, 0), proxy(alice, 100, time.Second)},
+			expected:   []expectedBid{{bob, 80}, {alice, 81}},
+		},
+		{
+			name:      "tied proxies favour the earliest one",
+			proxyBids: []ProxyBid{proxy(bob, 100, time.Second), proxy(alice, 100, 0)},
+			expected:  []expectedBid{{alice, 100}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bids := ResolveProxyBids(tt.highestBid, tt.proxyBids, 1)
+			if len(bids) != len(tt.expected) {
+				t.Fatalf("Expected %d bids, got %d", len(tt.expected), len(bids))
+			}
+
+			for i, bid := range bids {
+				if bid.UserId != tt.expected[i].userId || bid.Amount != tt.expected[i].amount {
+					t.Errorf("Expected bid %d to be %v, got %s %v",
+						i, tt.expected[i], bid.UserId, bid.Amount)
+				}
+				if !bid.Automatic {
+					t.Errorf("Expected bid %d to be automatic", i)
+				}
+			}
+		})
+	}
+}
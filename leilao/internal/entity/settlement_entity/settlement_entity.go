@@ -0,0 +1,119 @@
+package settlement_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type SettlementOutcome string
+
+const (
+	Sold          SettlementOutcome = "sold"
+	NoBids        SettlementOutcome = "no_bids"
+	ReserveNotMet SettlementOutcome = "reserve_not_met"
+)
+
+type Settlement struct {
+	AuctionId    string
+	SellerId     string
+	Outcome      SettlementOutcome
+	WinnerId     string
+	WinningBidId string
+	FinalPrice   float64
+	ReservePrice float64
+	BidCount     int64
+	ClosedAt     time.Time
+	SettledAt    time.Time
+}
+
+// Settle computes the result of a completed auction from the highest bid
+// tracked on it. The winner is only kept when the reserve price was reached,
+// and pays the price the auction format charges.
+func Settle(auction *auction_entity.Auction) (*Settlement, *internal_error.InternalError) {
+	if auction.Status != auction_entity.Completed {
+		return nil, internal_error.NewConflictError("Only completed auctions can be settled")
+	}
+
+	settlement := &Settlement{
+		AuctionId:    auction.Id,
+		SellerId:     auction.SellerId,
+		ReservePrice: auction.ReservePrice,
+		BidCount:     auction.BidCount,
+		ClosedAt:     auction.EndTime,
+		SettledAt:    time.Now(),
+	}
+
+	switch {
+	case auction.HighestBidId == "":
+		settlement.Outcome = NoBids
+	case auction.CurrentPrice < auction.ReservePrice:
+		settlement.Outcome = ReserveNotMet
+		settlement.FinalPrice = auction.CurrentPrice
+	default:
+		settlement.Outcome = Sold
+		settlement.WinnerId = auction.HighestBidderId
+		settlement.WinningBidId = auction.HighestBidId
+		settlement.FinalPrice = auction.Payment()
+	}
+
+	return settlement, nil
+}
+
+// AuctionClosedEvent is emitted once per auction, after its settlement is
+// recorded.
+type AuctionClosedEvent struct {
+	AuctionId  string            `json:"auction_id"`
+	SellerId   string            `json:"seller_id"`
+	Outcome    SettlementOutcome `json:"outcome"`
+	WinnerId   string            `json:"winner_id,omitempty"`
+	FinalPrice float64           `json:"final_price"`
+	BidCount   int64             `json:"bid_count"`
+	ClosedAt   time.Time         `json:"closed_at"`
+}
+
+func (s *Settlement) ClosedEvent() AuctionClosedEvent {
+	return AuctionClosedEvent{
+		AuctionId:  s.AuctionId,
+		SellerId:   s.SellerId,
+		Outcome:    s.Outcome,
+		WinnerId:   s.WinnerId,
+		FinalPrice: s.FinalPrice,
+		BidCount:   s.BidCount,
+		ClosedAt:   s.ClosedAt,
+	}
+}
+
+// SettlementFilter selects settlements by winner, latest closed first.
+type SettlementFilter struct {
+	WinnerId string
+	Limit    int
+	Cursor   string
+}
+
+// SettlementStats summarizes every settlement matched by a filter.
+type SettlementStats struct {
+	Count        int64
+	TotalPrice   float64
+	AveragePrice float64
+	HighestPrice float64
+}
+
+type SettlementPage struct {
+	Settlements []Settlement
+	Stats       SettlementStats
+	NextCursor  string
+}
+
+type SettlementRepositoryInterface interface {
+	// CreateSettlement reports false when the auction was already settled.
+	CreateSettlement(
+		ctx context.Context, settlement *Settlement) (bool, *internal_error.InternalError)
+
+	FindSettlementByAuctionId(
+		ctx context.Context, auctionId string) (*Settlement, *internal_error.InternalError)
+
+	FindSettlements(
+		ctx context.Context, filter SettlementFilter) (*SettlementPage, *internal_error.InternalError)
+}
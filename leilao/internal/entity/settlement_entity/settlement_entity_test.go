@@ -0,0 +1,72 @@
+package settlement_entity
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"testing"
+	"time"
+)
+
+func TestSettle(t *testing.T) {
+	endTime := time.Now()
+	completedAuction := func(currentPrice, reservePrice float64, highestBidId string, bidCount int64) *auction_entity.Auction {
+		return &auction_entity.Auction{
+			Id:              "auction",
+			SellerId:        "seller",
+			Status:          auction_entity.Completed,
+			EndTime:         endTime,
+			CurrentPrice:    currentPrice,
+			ReservePrice:    reservePrice,
+			HighestBidId:    highestBidId,
+			HighestBidderId: "bidder",
+			BidCount:        bidCount,
+		}
+	}
+
+	secondPriceAuction := completedAuction(300, 100, "bid", 2)
+	secondPriceAuction.Format = auction_entity.SealedSecondPrice
+	secondPriceAuction.SecondPrice = 180
+
+	tests := []struct {
+		name       string
+		auction    *auction_entity.Auction
+		outcome    SettlementOutcome
+		winnerId   string
+		finalPrice float64
+	}{
+		{"no bids", completedAuction(0, 0, "", 0), NoBids, "", 0},
+		{"sold without reserve", completedAuction(150, 0, "bid", 3), Sold, "bidder", 150},
+		{"sold at reserve", completedAuction(200, 200, "bid", 2), Sold, "bidder", 200},
+		{"reserve not met", completedAuction(150, 200, "bid", 3), ReserveNotMet, "", 150},
+		{"second price charged", secondPriceAuction, Sold, "bidder", 180},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlement, err := Settle(tt.auction)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if settlement.Outcome != tt.outcome {
+				t.Errorf("Expected outcome %s, got %s", tt.outcome, settlement.Outcome)
+			}
+			if settlement.WinnerId != tt.winnerId {
+				t.Errorf("Expected winner %q, got %q", tt.winnerId, settlement.WinnerId)
+			}
+			if settlement.FinalPrice != tt.finalPrice {
+				t.Errorf("Expected final price %v, got %v", tt.finalPrice, settlement.FinalPrice)
+			}
+			if settlement.BidCount != tt.auction.BidCount || !settlement.ClosedAt.Equal(endTime) {
+				t.Errorf("Expected bid count and close time to be copied from the auction")
+			}
+		})
+	}
+}
+
+func TestSettleRejectsAuctionsNotCompleted(t *testing.T) {
+	for _, status := range []auction_entity.AuctionStatus{auction_entity.Active, auction_entity.Cancelled} {
+		if _, err := Settle(&auction_entity.Auction{Status: status}); err == nil {
+			t.Errorf("Expected an error settling an auction with status %d", status)
+		}
+	}
+}
@@ -3,14 +3,125 @@ package user_entity
 import (
 	"context"
 	"fullcycle-auction_go/internal/internal_error"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	Id   string
-	Name string
+	Id           string
+	Name         string
+	Email        string
+	PasswordHash string
+	Role         UserRole
+	Active       bool
+	Timestamp    time.Time
+}
+
+type UserRole string
+
+const (
+	Bidder UserRole = "bidder"
+	Seller UserRole = "seller"
+	Admin  UserRole = "admin"
+)
+
+func CreateUser(name, email, password string, role UserRole) (*User, *internal_error.InternalError) {
+	if role == "" {
+		role = Bidder
+	}
+
+	user := &User{
+		Id:        uuid.New().String(),
+		Name:      strings.TrimSpace(name),
+		Email:     strings.ToLower(strings.TrimSpace(email)),
+		Role:      role,
+		Active:    true,
+		Timestamp: time.Now(),
+	}
+
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := user.SetPassword(password); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *User) SetPassword(password string) *internal_error.InternalError {
+	if len(password) < 8 || len(password) > 72 {
+		return internal_error.NewValidationError("Password is not a valid value",
+			internal_error.Cause{Field: "password", Message: "must be between 8 and 72 characters"})
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return internal_error.NewInternalServerError("Error trying to hash password").Wrap(err)
+	}
+
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+func (u *User) CheckPassword(password string) bool {
+	if u.PasswordHash == "" {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+func (u *User) Update(name, email string, role UserRole) *internal_error.InternalError {
+	if name != "" {
+		u.Name = strings.TrimSpace(name)
+	}
+	if email != "" {
+		u.Email = strings.ToLower(strings.TrimSpace(email))
+	}
+	if role != "" {
+		u.Role = role
+	}
+
+	return u.Validate()
+}
+
+func (u *User) Validate() *internal_error.InternalError {
+	if len(u.Name) < 2 {
+		return internal_error.NewValidationError("Name is not a valid value",
+			internal_error.Cause{Field: "name", Message: "must be at least 2 characters"})
+	} else if _, err := mail.ParseAddress(u.Email); err != nil {
+		return internal_error.NewValidationError("Email is not a valid value",
+			internal_error.Cause{Field: "email", Message: "must be a valid email address"})
+	} else if u.Role != Bidder && u.Role != Seller && u.Role != Admin {
+		return internal_error.NewValidationError("Role is not a valid value",
+			internal_error.Cause{Field: "role", Message: "must be bidder, seller or admin"})
+	}
+
+	return nil
 }
 
 type UserRepositoryInterface interface {
+	CreateUser(
+		ctx context.Context, userEntity *User) *internal_error.InternalError
+
+	UpdateUser(
+		ctx context.Context, userEntity *User) *internal_error.InternalError
+
+	DeactivateUser(
+		ctx context.Context, userId string) *internal_error.InternalError
+
 	FindUserById(
 		ctx context.Context, userId string) (*User, *internal_error.InternalError)
+
+	FindUserByEmail(
+		ctx context.Context, email string) (*User, *internal_error.InternalError)
+
+	FindUsers(
+		ctx context.Context, onlyActive bool) ([]User, *internal_error.InternalError)
 }
@@ -0,0 +1,72 @@
+package user_entity
+
+import (
+	"strings"
+	"testing"
+
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+func TestCreateUser(t *testing.T) {
+	user, err := CreateUser("  Alice  ", " Alice@Example.COM ", "correct-horse", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if user.Name != "Alice" || user.Email != "alice@example.com" {
+		t.Errorf("Expected the name and email to be normalized, got %q and %q", user.Name, user.Email)
+	}
+	if user.Role != Bidder || !user.Active {
+		t.Errorf("Expected an active bidder by default, got role %q active %t", user.Role, user.Active)
+	}
+	if user.PasswordHash == "correct-horse" || !user.CheckPassword("correct-horse") {
+		t.Error("Expected the password to be stored hashed and to check")
+	}
+	if user.CheckPassword("wrong-horse") {
+		t.Error("Expected a wrong password not to check")
+	}
+}
+
+func TestCreateUserRejectsInvalidFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		email    string
+		password string
+		role     UserRole
+		field    string
+	}{
+		{"short name", "A", "alice@example.com", "correct-horse", Bidder, "name"},
+		{"invalid email", "Alice", "alice.example.com", "correct-horse", Bidder, "email"},
+		{"unknown role", "Alice", "alice@example.com", "correct-horse", "owner", "role"},
+		{"short password", "Alice", "alice@example.com", "short", Bidder, "password"},
+		{"long password", "Alice", "alice@example.com", strings.Repeat("a", 73), Bidder, "password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateUser(tt.userName, tt.email, tt.password, tt.role)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if err.Code != internal_error.Validation || len(err.Causes) != 1 || err.Causes[0].Field != tt.field {
+				t.Errorf("Expected a validation error on %s, got %+v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestUpdateKeepsOmittedFieldsAndValidates(t *testing.T) {
+	user, _ := CreateUser("Alice", "alice@example.com", "correct-horse", Bidder)
+
+	if err := user.Update("", "", Seller); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.Name != "Alice" || user.Email != "alice@example.com" || user.Role != Seller {
+		t.Errorf("Expected only the role to change, got %+v", user)
+	}
+
+	if err := user.Update("", "not-an-email", ""); err == nil {
+		t.Error("Expected an invalid email to be rejected")
+	}
+}
@@ -0,0 +1,38 @@
+package watchlist_entity
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// WatchlistItem is an auction followed by a user, who is notified when it is
+// about to end.
+type WatchlistItem struct {
+	UserId    string
+	AuctionId string
+	Timestamp time.Time
+}
+
+func CreateWatchlistItem(userId, auctionId string) *WatchlistItem {
+	return &WatchlistItem{
+		UserId:    userId,
+		AuctionId: auctionId,
+		Timestamp: time.Now(),
+	}
+}
+
+type WatchlistRepositoryInterface interface {
+	// AddToWatchlist ignores auctions the user already watches.
+	AddToWatchlist(ctx context.Context, item *WatchlistItem) *internal_error.InternalError
+
+	RemoveFromWatchlist(ctx context.Context, userId, auctionId string) *internal_error.InternalError
+
+	// FindWatchlistByUserId returns the auctions watched by the user, newest
+	// first.
+	FindWatchlistByUserId(
+		ctx context.Context, userId string) ([]WatchlistItem, *internal_error.InternalError)
+
+	FindWatchersByAuctionId(
+		ctx context.Context, auctionId string) ([]string, *internal_error.InternalError)
+}
@@ -0,0 +1,186 @@
+package webhook_entity
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Subscription struct {
+	Id         string
+	Url        string
+	Secret     string
+	EventTypes []event_entity.EventType
+	Timestamp  time.Time
+}
+
+func CreateSubscription(
+	targetUrl string, eventTypes []event_entity.EventType) (*Subscription, *internal_error.InternalError) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, internal_error.NewInternalServerError("Error trying to generate webhook secret").Wrap(err)
+	}
+
+	subscription := &Subscription{
+		Id:         uuid.New().String(),
+		Url:        targetUrl,
+		Secret:     hex.EncodeToString(secret),
+		EventTypes: eventTypes,
+		Timestamp:  time.Now(),
+	}
+
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *Subscription) Validate() *internal_error.InternalError {
+	parsedUrl, err := url.Parse(s.Url)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return internal_error.NewValidationError("webhook url must be an absolute http(s) url",
+			internal_error.Cause{Field: "url", Message: "must be an absolute http(s) url"})
+	}
+
+	if len(s.EventTypes) == 0 {
+		return internal_error.NewValidationError("webhook must subscribe to at least one event",
+			internal_error.Cause{Field: "event_types", Message: "must not be empty"})
+	}
+
+	for _, eventType := range s.EventTypes {
+		if !isKnownEventType(eventType) {
+			return internal_error.NewValidationError("unknown event type "+string(eventType),
+				internal_error.Cause{Field: "event_types", Message: "unknown event type " + string(eventType)})
+		}
+	}
+
+	return nil
+}
+
+func (s *Subscription) Matches(eventType event_entity.EventType) bool {
+	for _, subscribed := range s.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func isKnownEventType(eventType event_entity.EventType) bool {
+	for _, known := range event_entity.EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	Pending   DeliveryStatus = "pending"
+	Delivered DeliveryStatus = "delivered"
+	Dead      DeliveryStatus = "dead"
+)
+
+// Delivery is one event sent to one subscription. Deliveries that run out of
+// attempts are kept with status Dead and form the dead-letter list.
+type Delivery struct {
+	Id             string
+	SubscriptionId string
+	EventId        string
+	EventType      event_entity.EventType
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	LastStatusCode int
+	Timestamp      time.Time
+	DeliveredAt    time.Time
+}
+
+// DeliveryId is derived from the event and the subscription so relaying the
+// same event twice never creates duplicate deliveries.
+func DeliveryId(eventId, subscriptionId string) string {
+	return eventId + ":" + subscriptionId
+}
+
+// RetryPolicy describes the exponential backoff between delivery attempts.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// NextDelay is the wait after the given failed attempt (starting at 1):
+// BaseDelay doubled for every previous failure, capped at MaxDelay.
+func (rp RetryPolicy) NextDelay(attempt int) time.Duration {
+	delay := rp.BaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= rp.MaxDelay {
+			return rp.MaxDelay
+		}
+	}
+
+	if delay > rp.MaxDelay {
+		return rp.MaxDelay
+	}
+	return delay
+}
+
+func (rp RetryPolicy) Exhausted(attempts int) bool {
+	return attempts >= rp.MaxAttempts
+}
+
+// Sign returns the signature sent in the X-Webhook-Signature header: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed by the subscription secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type SubscriptionRepositoryInterface interface {
+	CreateSubscription(ctx context.Context, subscription *Subscription) *internal_error.InternalError
+
+	FindSubscriptions(ctx context.Context) ([]Subscription, *internal_error.InternalError)
+
+	FindSubscriptionById(
+		ctx context.Context, subscriptionId string) (*Subscription, *internal_error.InternalError)
+
+	FindSubscriptionsByEventType(
+		ctx context.Context, eventType event_entity.EventType) ([]Subscription, *internal_error.InternalError)
+
+	DeleteSubscription(ctx context.Context, subscriptionId string) *internal_error.InternalError
+}
+
+type DeliveryRepositoryInterface interface {
+	// CreateDelivery ignores deliveries that already exist.
+	CreateDelivery(ctx context.Context, delivery *Delivery) *internal_error.InternalError
+
+	// ClaimDueDelivery locks a pending delivery whose next attempt is due until
+	// leaseUntil, so concurrent dispatchers never send it twice at once.
+	ClaimDueDelivery(
+		ctx context.Context, now, leaseUntil time.Time) (*Delivery, *internal_error.InternalError)
+
+	UpdateDelivery(ctx context.Context, delivery *Delivery) *internal_error.InternalError
+
+	FindDeliveriesByStatus(
+		ctx context.Context, status DeliveryStatus, limit int64) ([]Delivery, *internal_error.InternalError)
+
+	RetryDeadDelivery(ctx context.Context, deliveryId string) *internal_error.InternalError
+}
@@ -0,0 +1,72 @@
+package webhook_entity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyNextDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := policy.NextDelay(i + 1); got != want {
+			t.Errorf("Attempt %d: expected delay %v, got %v", i+1, want, got)
+		}
+	}
+
+	if policy.Exhausted(4) || !policy.Exhausted(5) {
+		t.Errorf("Expected the policy to be exhausted after %d attempts", policy.MaxAttempts)
+	}
+}
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"event"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign("secret", timestamp, body); got != expected {
+		t.Errorf("Expected signature %s, got %s", expected, got)
+	}
+
+	if Sign("other-secret", timestamp, body) == expected {
+		t.Error("Expected a different secret to produce a different signature")
+	}
+}
+
+func TestCreateSubscription(t *testing.T) {
+	subscription, err := CreateSubscription(
+		"https://example.com/hooks", []event_entity.EventType{event_entity.BidAccepted})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(subscription.Secret) != 64 {
+		t.Errorf("Expected a 32 byte hex secret, got %q", subscription.Secret)
+	}
+	if !subscription.Matches(event_entity.BidAccepted) || subscription.Matches(event_entity.AuctionClosed) {
+		t.Error("Expected the subscription to match only its event types")
+	}
+
+	invalid := []struct {
+		url        string
+		eventTypes []event_entity.EventType
+	}{
+		{"ftp://example.com", []event_entity.EventType{event_entity.BidAccepted}},
+		{"/relative", []event_entity.EventType{event_entity.BidAccepted}},
+		{"https://example.com", nil},
+		{"https://example.com", []event_entity.EventType{"auction.unknown"}},
+	}
+	for _, tt := range invalid {
+		if _, err := CreateSubscription(tt.url, tt.eventTypes); err == nil {
+			t.Errorf("Expected an error for url %q and events %v", tt.url, tt.eventTypes)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package auction_controller
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// maxMultipartOverhead is the room left in an upload request for the
+// multipart boundaries and headers around the file.
+const maxMultipartOverhead = 1 << 20
+
+func (u *AuctionController) UploadAttachment(c *gin.Context) {
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(
+		c.Writer, c.Request.Body, u.auctionUseCase.MaxAttachmentSize()+maxMultipartOverhead)
+
+	part, err := fileFormPart(c.Request)
+	if err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid upload", rest_err.Causes{
+			Field:   "file",
+			Message: "must be sent as a multipart/form-data file field named file",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+	defer part.Close()
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	attachmentData, uploadErr := u.auctionUseCase.UploadAttachment(
+		ctx, auctionId, requesterFromContext(c), auction_usecase.AttachmentInputDTO{
+			FileName: part.FileName(),
+			Content:  part,
+		})
+	if uploadErr != nil {
+		restErr := rest_err.ConvertError(uploadErr)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachmentData)
+}
+
+func (u *AuctionController) FindAttachment(c *gin.Context) {
+	u.serveAttachment(c, false)
+}
+
+func (u *AuctionController) FindAttachmentThumbnail(c *gin.Context) {
+	u.serveAttachment(c, true)
+}
+
+func (u *AuctionController) DeleteAttachment(c *gin.Context) {
+	auctionId, attachmentId, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	err := u.auctionUseCase.DeleteAttachment(ctx, auctionId, attachmentId, requesterFromContext(c))
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// serveAttachment shows images inline and offers every other file as a
+// download, under the name it was uploaded with.
+func (u *AuctionController) serveAttachment(c *gin.Context, thumbnail bool) {
+	auctionId, attachmentId, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	attachmentContent, err := u.auctionUseCase.OpenAttachment(ctx, auctionId, attachmentId, thumbnail)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+	defer attachmentContent.Content.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachmentContent.ContentType, "image/") {
+		disposition = "inline"
+	}
+
+	c.DataFromReader(http.StatusOK, attachmentContent.Size, attachmentContent.ContentType,
+		attachmentContent.Content, map[string]string{
+			"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachmentContent.FileName}),
+			"Cache-Control":          "public, max-age=86400",
+			"X-Content-Type-Options": "nosniff",
+		})
+}
+
+func attachmentParams(c *gin.Context) (string, string, bool) {
+	auctionId := c.Param("auctionId")
+	attachmentId := c.Param("attachmentId")
+
+	var causes []rest_err.Causes
+	if err := uuid.Validate(auctionId); err != nil {
+		causes = append(causes, rest_err.Causes{Field: "auctionId", Message: "Invalid UUID value"})
+	}
+	if err := uuid.Validate(attachmentId); err != nil {
+		causes = append(causes, rest_err.Causes{Field: "attachmentId", Message: "Invalid UUID value"})
+	}
+
+	if len(causes) > 0 {
+		errRest := rest_err.NewValidationError("Invalid fields", causes...)
+
+		c.JSON(errRest.Code, errRest)
+		return "", "", false
+	}
+
+	return auctionId, attachmentId, true
+}
+
+// fileFormPart streams the "file" part of a multipart/form-data request
+// instead of buffering the whole form, so the size limits apply while the
+// file is read.
+func fileFormPart(request *http.Request) (*multipart.Part, error) {
+	reader, err := request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == "file" && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
+}
@@ -1,20 +1,20 @@
 package auction_controller
 
 import (
-	"context"
+	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
 	"fullcycle-auction_go/internal/usecase/auction_usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
-	"strconv"
 )
 
 func (u *AuctionController) FindAuctionById(c *gin.Context) {
 	auctionId := c.Param("auctionId")
 
 	if err := uuid.Validate(auctionId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
 			Field:   "auctionId",
 			Message: "Invalid UUID value",
 		})
@@ -23,7 +23,8 @@ func (u *AuctionController) FindAuctionById(c *gin.Context) {
 		return
 	}
 
-	auctionData, err := u.auctionUseCase.FindAuctionById(context.Background(), auctionId)
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	auctionData, err := u.auctionUseCase.FindAuctionById(ctx, auctionId)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -34,19 +35,19 @@ func (u *AuctionController) FindAuctionById(c *gin.Context) {
 }
 
 func (u *AuctionController) FindAuctions(c *gin.Context) {
-	status := c.Query("status")
-	category := c.Query("category")
-	productName := c.Query("productName")
+	var filterInputDTO auction_usecase.AuctionFilterInputDTO
 
-	statusNumber, errConv := strconv.Atoi(status)
-	if errConv != nil {
-		errRest := rest_err.NewBadRequestError("Error trying to validate auction status param")
+	if err := c.ShouldBindQuery(&filterInputDTO); err != nil {
+		errRest := validation.ValidateErr(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
-	auctions, err := u.auctionUseCase.FindAuctions(context.Background(),
-		auction_usecase.AuctionStatus(statusNumber), category, productName)
+	if filterInputDTO.ProductName == "" {
+		filterInputDTO.ProductName = c.Query("productName")
+	}
+
+	auctions, err := u.auctionUseCase.FindAuctions(c.Request.Context(), filterInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -56,11 +57,30 @@ func (u *AuctionController) FindAuctions(c *gin.Context) {
 	c.JSON(http.StatusOK, auctions)
 }
 
+func (u *AuctionController) SearchAuctions(c *gin.Context) {
+	var searchInputDTO auction_usecase.AuctionSearchInputDTO
+
+	if err := c.ShouldBindQuery(&searchInputDTO); err != nil {
+		errRest := validation.ValidateErr(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	searchResult, err := u.auctionUseCase.SearchAuctions(c.Request.Context(), searchInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, searchResult)
+}
+
 func (u *AuctionController) FindWinningBidByAuctionId(c *gin.Context) {
 	auctionId := c.Param("auctionId")
 
 	if err := uuid.Validate(auctionId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
 			Field:   "auctionId",
 			Message: "Invalid UUID value",
 		})
@@ -69,7 +89,8 @@ func (u *AuctionController) FindWinningBidByAuctionId(c *gin.Context) {
 		return
 	}
 
-	auctionData, err := u.auctionUseCase.FindWinningBidByAuctionId(context.Background(), auctionId)
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	auctionData, err := u.auctionUseCase.FindWinningBidByAuctionId(ctx, auctionId)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
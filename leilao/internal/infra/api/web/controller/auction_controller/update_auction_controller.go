@@ -0,0 +1,80 @@
+package auction_controller
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/auction_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (u *AuctionController) UpdateAuction(c *gin.Context) {
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	var auctionUpdateInputDTO auction_usecase.AuctionUpdateInputDTO
+
+	if err := c.ShouldBindJSON(&auctionUpdateInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	auctionData, err := u.auctionUseCase.UpdateAuction(
+		ctx, auctionId, requesterFromContext(c), auctionUpdateInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionController) CancelAuction(c *gin.Context) {
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	err := u.auctionUseCase.CancelAuction(ctx, auctionId, requesterFromContext(c))
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func requesterFromContext(c *gin.Context) auction_usecase.RequesterDTO {
+	return auction_usecase.RequesterDTO{
+		UserId:  middleware.AuthenticatedUserId(c),
+		IsAdmin: middleware.HasRole(c, user_entity.Admin),
+	}
+}
@@ -0,0 +1,40 @@
+package auth_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/auth_usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type AuthController struct {
+	authUseCase auth_usecase.AuthUseCaseInterface
+}
+
+func NewAuthController(authUseCase auth_usecase.AuthUseCaseInterface) *AuthController {
+	return &AuthController{
+		authUseCase: authUseCase,
+	}
+}
+
+func (u *AuthController) Login(c *gin.Context) {
+	var loginInputDTO auth_usecase.LoginInputDTO
+
+	if err := c.ShouldBindJSON(&loginInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	loginData, err := u.authUseCase.Login(c.Request.Context(), loginInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, loginData)
+}
@@ -1,8 +1,9 @@
 package bid_controller
 
 import (
-	"context"
+	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
 	"fullcycle-auction_go/internal/infra/api/web/validation"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
 	"github.com/gin-gonic/gin"
@@ -29,7 +30,10 @@ func (u *BidController) CreateBid(c *gin.Context) {
 		return
 	}
 
-	err := u.bidUseCase.CreateBid(context.Background(), bidInputDTO)
+	bidInputDTO.UserId = middleware.AuthenticatedUserId(c)
+
+	ctx := logger.WithAuctionId(c.Request.Context(), bidInputDTO.AuctionId)
+	err := u.bidUseCase.CreateBid(ctx, bidInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 
@@ -1,8 +1,11 @@
 package bid_controller
 
 import (
-	"context"
+	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/bid_usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
@@ -12,7 +15,7 @@ func (u *BidController) FindBidByAuctionId(c *gin.Context) {
 	auctionId := c.Param("auctionId")
 
 	if err := uuid.Validate(auctionId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
 			Field:   "auctionId",
 			Message: "Invalid UUID value",
 		})
@@ -21,12 +24,58 @@ func (u *BidController) FindBidByAuctionId(c *gin.Context) {
 		return
 	}
 
-	bidOutputList, err := u.bidUseCase.FindBidByAuctionId(context.Background(), auctionId)
+	var filterInputDTO bid_usecase.BidFilterInputDTO
+
+	if err := c.ShouldBindQuery(&filterInputDTO); err != nil {
+		errRest := validation.ValidateErr(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	bidPage, err := u.bidUseCase.FindBidByAuctionId(ctx, auctionId, filterInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, bidPage)
+}
+
+func (u *BidController) FindBidsByUserId(c *gin.Context) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if !middleware.IsSelfOrAdmin(c, userId) {
+		errRest := rest_err.NewForbiddenError("User is not allowed to access this user")
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	var filterInputDTO bid_usecase.BidFilterInputDTO
+
+	if err := c.ShouldBindQuery(&filterInputDTO); err != nil {
+		errRest := validation.ValidateErr(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	bidPage, err := u.bidUseCase.FindBidsByUserId(c.Request.Context(), userId, filterInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
-	c.JSON(http.StatusOK, bidOutputList)
+	c.JSON(http.StatusOK, bidPage)
 }
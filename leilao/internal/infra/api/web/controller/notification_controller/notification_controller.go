@@ -0,0 +1,149 @@
+package notification_controller
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/notification_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+	"strconv"
+)
+
+type NotificationController struct {
+	notificationUseCase notification_usecase.NotificationUseCaseInterface
+}
+
+func NewNotificationController(
+	notificationUseCase notification_usecase.NotificationUseCaseInterface) *NotificationController {
+	return &NotificationController{
+		notificationUseCase: notificationUseCase,
+	}
+}
+
+func (n *NotificationController) FindWatchlist(c *gin.Context) {
+	userId, ok := authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	watchlist, err := n.notificationUseCase.FindWatchlist(c.Request.Context(), userId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+func (n *NotificationController) AddToWatchlist(c *gin.Context) {
+	userId, ok := authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	var watchlistInputDTO notification_usecase.WatchlistInputDTO
+
+	if err := c.ShouldBindJSON(&watchlistInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), watchlistInputDTO.AuctionId)
+	if err := n.notificationUseCase.AddToWatchlist(ctx, userId, watchlistInputDTO); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusCreated)
+}
+
+func (n *NotificationController) RemoveFromWatchlist(c *gin.Context) {
+	userId, ok := authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	if err := n.notificationUseCase.RemoveFromWatchlist(ctx, userId, auctionId); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (n *NotificationController) FindNotifications(c *gin.Context) {
+	userId, ok := authorizeUser(c)
+	if !ok {
+		return
+	}
+
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		parsed, errConv := strconv.Atoi(value)
+		if errConv != nil || parsed <= 0 {
+			errRest := rest_err.NewValidationError("Error trying to validate limit param", rest_err.Causes{
+				Field:   "limit",
+				Message: "must be a positive integer",
+			})
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+		limit = parsed
+	}
+
+	notifications, err := n.notificationUseCase.FindNotifications(c.Request.Context(), userId, limit)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// authorizeUser validates the userId param and answers the request when it
+// does not refer to the requester, unless the requester is an admin.
+func authorizeUser(c *gin.Context) (string, bool) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	if !middleware.IsSelfOrAdmin(c, userId) {
+		errRest := rest_err.NewForbiddenError("User is not allowed to access this user")
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return userId, true
+}
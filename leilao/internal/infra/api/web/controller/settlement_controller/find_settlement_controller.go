@@ -0,0 +1,83 @@
+package settlement_controller
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/settlement_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type SettlementController struct {
+	settlementUseCase settlement_usecase.SettlementUseCaseInterface
+}
+
+func NewSettlementController(settlementUseCase settlement_usecase.SettlementUseCaseInterface) *SettlementController {
+	return &SettlementController{
+		settlementUseCase: settlementUseCase,
+	}
+}
+
+func (u *SettlementController) FindAuctionResult(c *gin.Context) {
+	auctionId := c.Param("auctionId")
+
+	if err := uuid.Validate(auctionId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "auctionId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	ctx := logger.WithAuctionId(c.Request.Context(), auctionId)
+	settlementData, err := u.settlementUseCase.FindAuctionResult(ctx, auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, settlementData)
+}
+
+func (u *SettlementController) FindAuctionsWon(c *gin.Context) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if !middleware.IsSelfOrAdmin(c, userId) {
+		errRest := rest_err.NewForbiddenError("User is not allowed to access this user")
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	var filterInputDTO settlement_usecase.SettlementFilterInputDTO
+
+	if err := c.ShouldBindQuery(&filterInputDTO); err != nil {
+		errRest := validation.ValidateErr(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	wonAuctions, err := u.settlementUseCase.FindAuctionsWonByUserId(c.Request.Context(), userId, filterInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, wonAuctions)
+}
@@ -0,0 +1,117 @@
+package user_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/user_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+func (u *UserController) CreateUser(c *gin.Context) {
+	var userInputDTO user_usecase.UserInputDTO
+
+	if err := c.ShouldBindJSON(&userInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	// Sign-ups are bidders; sellers and admins are created by an admin.
+	if role := user_entity.UserRole(userInputDTO.Role); (role == user_entity.Seller || role == user_entity.Admin) &&
+		!middleware.HasRole(c, user_entity.Admin) {
+		restErr := rest_err.NewForbiddenError("Only admins can create seller or admin users")
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	userData, err := u.userUseCase.CreateUser(c.Request.Context(), userInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, userData)
+}
+
+func (u *UserController) UpdateUser(c *gin.Context) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if !middleware.IsSelfOrAdmin(c, userId) {
+		errRest := rest_err.NewForbiddenError("User is not allowed to update this user")
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	var userUpdateInputDTO user_usecase.UserUpdateInputDTO
+
+	if err := c.ShouldBindJSON(&userUpdateInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if userUpdateInputDTO.Role != "" && !middleware.HasRole(c, user_entity.Admin) {
+		restErr := rest_err.NewForbiddenError("Only admins can change user roles")
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	userData, err := u.userUseCase.UpdateUser(c.Request.Context(), userId, userUpdateInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, userData)
+}
+
+func (u *UserController) DeactivateUser(c *gin.Context) {
+	userId := c.Param("userId")
+
+	if err := uuid.Validate(userId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "userId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if !middleware.IsSelfOrAdmin(c, userId) {
+		errRest := rest_err.NewForbiddenError("User is not allowed to deactivate this user")
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if err := u.userUseCase.DeactivateUser(c.Request.Context(), userId); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -1,12 +1,13 @@
 package user_controller
 
 import (
-	"context"
 	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/middleware"
 	"fullcycle-auction_go/internal/usecase/user_usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
+	"strconv"
 )
 
 type UserController struct {
@@ -23,7 +24,7 @@ func (u *UserController) FindUserById(c *gin.Context) {
 	userId := c.Param("userId")
 
 	if err := uuid.Validate(userId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
 			Field:   "userId",
 			Message: "Invalid UUID value",
 		})
@@ -32,7 +33,13 @@ func (u *UserController) FindUserById(c *gin.Context) {
 		return
 	}
 
-	userData, err := u.userUseCase.FindUserById(context.Background(), userId)
+	if !middleware.IsSelfOrAdmin(c, userId) {
+		errRest := rest_err.NewForbiddenError("User is not allowed to access this user")
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	userData, err := u.userUseCase.FindUserById(c.Request.Context(), userId)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -41,3 +48,28 @@ func (u *UserController) FindUserById(c *gin.Context) {
 
 	c.JSON(http.StatusOK, userData)
 }
+
+func (u *UserController) FindUsers(c *gin.Context) {
+	onlyActive := true
+	if active := c.Query("active"); active != "" {
+		value, errConv := strconv.ParseBool(active)
+		if errConv != nil {
+			errRest := rest_err.NewValidationError("Error trying to validate active param", rest_err.Causes{
+				Field:   "active",
+				Message: "must be a boolean",
+			})
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+		onlyActive = value
+	}
+
+	users, err := u.userUseCase.FindUsers(c.Request.Context(), onlyActive)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
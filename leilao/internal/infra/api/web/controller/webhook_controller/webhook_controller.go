@@ -0,0 +1,99 @@
+package webhook_controller
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/infra/api/web/validation"
+	"fullcycle-auction_go/internal/usecase/webhook_usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type WebhookController struct {
+	webhookUseCase webhook_usecase.WebhookUseCaseInterface
+}
+
+func NewWebhookController(webhookUseCase webhook_usecase.WebhookUseCaseInterface) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+func (u *WebhookController) CreateWebhook(c *gin.Context) {
+	var webhookInputDTO webhook_usecase.WebhookInputDTO
+
+	if err := c.ShouldBindJSON(&webhookInputDTO); err != nil {
+		restErr := validation.ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	webhookData, err := u.webhookUseCase.CreateWebhook(c.Request.Context(), webhookInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookData)
+}
+
+func (u *WebhookController) FindWebhooks(c *gin.Context) {
+	webhooks, err := u.webhookUseCase.FindWebhooks(c.Request.Context())
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (u *WebhookController) DeleteWebhook(c *gin.Context) {
+	webhookId := c.Param("webhookId")
+
+	if err := uuid.Validate(webhookId); err != nil {
+		errRest := rest_err.NewValidationError("Invalid fields", rest_err.Causes{
+			Field:   "webhookId",
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if err := u.webhookUseCase.DeleteWebhook(c.Request.Context(), webhookId); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *WebhookController) FindDeadLetters(c *gin.Context) {
+	deliveries, err := u.webhookUseCase.FindDeadLetters(c.Request.Context())
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (u *WebhookController) RetryDelivery(c *gin.Context) {
+	if err := u.webhookUseCase.RetryDelivery(c.Request.Context(), c.Param("deliveryId")); err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/auth"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	authUserIdKey   = "auth_user_id"
+	authUserRoleKey = "auth_user_role"
+)
+
+// Authenticate requires a valid bearer token and stores its subject and role
+// in the gin context.
+func Authenticate(
+	tokenManager *auth.TokenManager, userRepository user_entity.UserRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticate(c, tokenManager, userRepository) {
+			return
+		}
+
+		if AuthenticatedUserId(c) == "" {
+			restErr := rest_err.NewUnauthorizedError("Missing bearer token")
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAuthenticate identifies the caller when a bearer token is sent but
+// lets anonymous requests through.
+func OptionalAuthenticate(
+	tokenManager *auth.TokenManager, userRepository user_entity.UserRepositoryInterface) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticate(c, tokenManager, userRepository) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func RequireRoles(roles ...user_entity.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(c, roles...) {
+			restErr := rest_err.NewForbiddenError("User is not allowed to perform this action")
+			c.AbortWithStatusJSON(restErr.Code, restErr)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func AuthenticatedUserId(c *gin.Context) string {
+	return c.GetString(authUserIdKey)
+}
+
+func AuthenticatedUserRole(c *gin.Context) user_entity.UserRole {
+	role, _ := c.Get(authUserRoleKey)
+	userRole, _ := role.(user_entity.UserRole)
+	return userRole
+}
+
+func HasRole(c *gin.Context, roles ...user_entity.UserRole) bool {
+	userRole := AuthenticatedUserRole(c)
+	for _, role := range roles {
+		if userRole == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsSelfOrAdmin(c *gin.Context, userId string) bool {
+	return AuthenticatedUserId(c) == userId || HasRole(c, user_entity.Admin)
+}
+
+// authenticate checks the token against the stored user, so a user who was
+// deactivated or whose role changed loses the rights of the token before it
+// expires and has to log in again.
+func authenticate(
+	c *gin.Context,
+	tokenManager *auth.TokenManager, userRepository user_entity.UserRepositoryInterface) bool {
+	header := c.GetHeader("Authorization")
+	if header == "" {
+		return true
+	}
+
+	tokenString, found := strings.CutPrefix(header, "Bearer ")
+	if !found {
+		restErr := rest_err.NewUnauthorizedError("Authorization header must use the Bearer scheme")
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return false
+	}
+
+	claims, err := tokenManager.ParseToken(tokenString)
+	if err != nil {
+		restErr := rest_err.NewUnauthorizedError("Invalid or expired token")
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return false
+	}
+
+	userEntity, internalErr := userRepository.FindUserById(c.Request.Context(), claims.Subject)
+	if internalErr != nil && internalErr.Code != internal_error.NotFound {
+		restErr := rest_err.ConvertError(internalErr)
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return false
+	}
+
+	if internalErr != nil || !userEntity.Active || userEntity.Role != claims.Role {
+		restErr := rest_err.NewUnauthorizedError("Token is no longer valid for this user, log in again")
+		c.AbortWithStatusJSON(restErr.Code, restErr)
+		return false
+	}
+
+	c.Set(authUserIdKey, claims.Subject)
+	c.Set(authUserRoleKey, claims.Role)
+	return true
+}
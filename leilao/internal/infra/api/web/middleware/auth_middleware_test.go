@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/infra/auth"
+	"fullcycle-auction_go/internal/internal_error"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeUserRepository struct {
+	user_entity.UserRepositoryInterface
+	users map[string]*user_entity.User
+}
+
+func (f *fakeUserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	userEntity, ok := f.users[userId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("User not found")
+	}
+	return userEntity, nil
+}
+
+func TestAuthenticateChecksTheStoredUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenManager := auth.NewTokenManager("01234567890123456789012345678901", time.Minute)
+	userRepository := &fakeUserRepository{users: map[string]*user_entity.User{}}
+
+	router := gin.New()
+	router.POST("/auction", Authenticate(tokenManager, userRepository),
+		RequireRoles(user_entity.Seller, user_entity.Admin), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+
+	tests := []struct {
+		name     string
+		stored   *user_entity.User
+		expected int
+	}{
+		{"active seller", &user_entity.User{Role: user_entity.Seller, Active: true}, http.StatusNoContent},
+		{"deactivated seller", &user_entity.User{Role: user_entity.Seller, Active: false}, http.StatusUnauthorized},
+		{"demoted seller", &user_entity.User{Role: user_entity.Bidder, Active: true}, http.StatusUnauthorized},
+		{"deleted user", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userId := tt.name
+			if tt.stored != nil {
+				tt.stored.Id = userId
+				userRepository.users[userId] = tt.stored
+			}
+
+			token, _, err := tokenManager.GenerateToken(userId, user_entity.Seller)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			request := httptest.NewRequest(http.MethodPost, "/auction", nil)
+			request.Header.Set("Authorization", "Bearer "+token)
+			recorder := httptest.NewRecorder()
+
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RequestIdHeader    = "X-Request-ID"
+	maxRequestIdLength = 128
+)
+
+// RequestId takes the request id sent by the client, or generates one, echoes
+// it in the response, stores it in the request context for the logger and
+// adds it to the request span.
+func RequestId() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if !isValidRequestId(requestId) {
+			requestId = uuid.New().String()
+		}
+
+		c.Header(RequestIdHeader, requestId)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request_id", requestId))
+		c.Request = c.Request.WithContext(logger.WithRequestId(c.Request.Context(), requestId))
+
+		c.Next()
+	}
+}
+
+// Timeout bounds the request context, so database calls made for a request
+// give up once the client can no longer get a useful answer.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func isValidRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIdLength {
+		return false
+	}
+
+	for _, char := range requestId {
+		if char < '!' || char > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fullcycle-auction_go/configuration/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIdPropagatesToContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var contextRequestId string
+	var hasDeadline bool
+	router := gin.New()
+	router.Use(RequestId(), Timeout(time.Second))
+	router.GET("/", func(c *gin.Context) {
+		contextRequestId = logger.RequestIdFromContext(c.Request.Context())
+		_, hasDeadline = c.Request.Context().Deadline()
+	})
+
+	tests := []struct {
+		name          string
+		header        string
+		keepsClientId bool
+	}{
+		{name: "client id is kept", header: "client-request-1", keepsClientId: true},
+		{name: "missing id is generated"},
+		{name: "invalid id is replaced", header: "bad id\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.header != "" {
+				request.Header.Set(RequestIdHeader, test.header)
+			}
+			recorder := httptest.NewRecorder()
+
+			router.ServeHTTP(recorder, request)
+
+			responseRequestId := recorder.Header().Get(RequestIdHeader)
+			if responseRequestId == "" || responseRequestId != contextRequestId {
+				t.Errorf("Expected the response and context to share the request id, got %q and %q",
+					responseRequestId, contextRequestId)
+			}
+			if test.keepsClientId != (responseRequestId == test.header) {
+				t.Errorf("Unexpected request id %q for header %q", responseRequestId, test.header)
+			}
+			if !hasDeadline {
+				t.Errorf("Expected the request context to have a deadline")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"errors"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Claims struct {
+	Role user_entity.UserRole `json:"role"`
+	jwt.RegisteredClaims
+}
+
+type TokenManager struct {
+	secret     []byte
+	expiration time.Duration
+}
+
+func NewTokenManager(secret string, expiration time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     []byte(secret),
+		expiration: expiration,
+	}
+}
+
+func (tm *TokenManager) GenerateToken(
+	userId string, role user_entity.UserRole) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(tm.expiration)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		Role: role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userId,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signedToken, err := token.SignedString(tm.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signedToken, expiresAt, nil
+}
+
+func (tm *TokenManager) ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return tm.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	return claims, nil
+}
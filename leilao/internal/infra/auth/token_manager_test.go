@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"fullcycle-auction_go/internal/entity/user_entity"
+)
+
+func TestTokenManagerRoundTrip(t *testing.T) {
+	tokenManager := NewTokenManager("01234567890123456789012345678901", time.Minute)
+
+	token, expiresAt, err := tokenManager.GenerateToken("user-id", user_entity.Seller)
+	if err != nil {
+		t.Fatalf("Expected no error generating token, got %v", err)
+	}
+	if time.Until(expiresAt) <= 0 {
+		t.Errorf("Expected expiration in the future, got %v", expiresAt)
+	}
+
+	claims, err := tokenManager.ParseToken(token)
+	if err != nil {
+		t.Fatalf("Expected no error parsing token, got %v", err)
+	}
+	if claims.Subject != "user-id" || claims.Role != user_entity.Seller {
+		t.Errorf("Expected user-id/seller, got %s/%s", claims.Subject, claims.Role)
+	}
+}
+
+func TestTokenManagerRejectsInvalidTokens(t *testing.T) {
+	tokenManager := NewTokenManager("01234567890123456789012345678901", time.Minute)
+	otherManager := NewTokenManager("abcdefghijabcdefghijabcdefghijab", time.Minute)
+	expiredManager := NewTokenManager("01234567890123456789012345678901", -time.Minute)
+
+	foreignToken, _, _ := otherManager.GenerateToken("user-id", user_entity.Admin)
+	expiredToken, _, _ := expiredManager.GenerateToken("user-id", user_entity.Admin)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "malformed token", token: "not-a-token"},
+		{name: "token signed with another key", token: foreignToken},
+		{name: "expired token", token: expiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tokenManager.ParseToken(tt.token); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewBlobStore builds the named blob store rooted at root. Only "local" exists
+// for now; an S3-compatible store only has to implement
+// attachment_entity.BlobStore to be added here.
+func NewBlobStore(store, root string) (attachment_entity.BlobStore, error) {
+	switch store {
+	case "local":
+		return NewLocalStore(root)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", store)
+	}
+}
+
+// LocalStore keeps each blob as a file under a root directory, at the path
+// given by its key.
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("error trying to create blob store directory: %w", err)
+	}
+
+	return &LocalStore{root: root}, nil
+}
+
+// Put writes the content to a temporary file first and renames it into place,
+// so readers never see a partially written blob.
+func (ls *LocalStore) Put(
+	ctx context.Context, key string, content io.Reader) *internal_error.InternalError {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := ls.write(path, content); err != nil {
+		logger.ErrorContext(ctx, "Error trying to store blob", err)
+		return internal_error.NewInternalServerError("Error trying to store blob").Wrap(err)
+	}
+
+	return nil
+}
+
+func (ls *LocalStore) Open(
+	ctx context.Context, key string) (io.ReadCloser, *internal_error.InternalError) {
+	path, err := ls.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, openErr := os.Open(path)
+	if errors.Is(openErr, fs.ErrNotExist) {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Blob not found with this key = %s", key))
+	}
+	if openErr != nil {
+		logger.ErrorContext(ctx, "Error trying to open blob", openErr)
+		return nil, internal_error.NewInternalServerError("Error trying to open blob").Wrap(openErr)
+	}
+
+	return file, nil
+}
+
+// Delete ignores keys that do not exist.
+func (ls *LocalStore) Delete(ctx context.Context, key string) *internal_error.InternalError {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logger.ErrorContext(ctx, "Error trying to delete blob", err)
+		return internal_error.NewInternalServerError("Error trying to delete blob").Wrap(err)
+	}
+
+	return nil
+}
+
+// path maps a key to a file under the root and refuses keys that would escape
+// it.
+func (ls *LocalStore) path(key string) (string, *internal_error.InternalError) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." ||
+		strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", internal_error.NewBadRequestError(fmt.Sprintf("Invalid blob key %q", key))
+	}
+
+	return filepath.Join(ls.root, cleaned), nil
+}
+
+func (ls *LocalStore) write(path string, content io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
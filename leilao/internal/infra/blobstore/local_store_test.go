@@ -0,0 +1,57 @@
+package blobstore
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorePutOpenDelete(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := store.Put(ctx, "auctions/a1/b1", strings.NewReader("first")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Put(ctx, "auctions/a1/b1", strings.NewReader("second")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reader, openErr := store.Open(ctx, "auctions/a1/b1")
+	if openErr != nil {
+		t.Fatalf("Unexpected error: %v", openErr)
+	}
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(content) != "second" {
+		t.Errorf("Expected the last content to be kept, got %q", content)
+	}
+
+	if err := store.Delete(ctx, "auctions/a1/b1"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := store.Delete(ctx, "auctions/a1/b1"); err != nil {
+		t.Errorf("Expected deleting a missing blob to succeed, got %v", err)
+	}
+	if _, err := store.Open(ctx, "auctions/a1/b1"); err == nil || err.Code != internal_error.NotFound {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+}
+
+func TestLocalStoreRefusesKeysOutsideTheRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"", "../escape", "auctions/../../escape", "/etc/passwd"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x")); err == nil {
+			t.Errorf("Expected key %q to be refused", key)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AttachmentEntityMongo struct {
+	Id          string                           `bson:"_id"`
+	AuctionId   string                           `bson:"auction_id"`
+	Kind        attachment_entity.AttachmentKind `bson:"kind"`
+	FileName    string                           `bson:"file_name"`
+	ContentType string                           `bson:"content_type"`
+	Size        int64                            `bson:"size"`
+	Timestamp   int64                            `bson:"timestamp"`
+}
+
+type AttachmentRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewAttachmentRepository(database *mongo.Database) *AttachmentRepository {
+	return &AttachmentRepository{
+		Collection: database.Collection("attachments"),
+	}
+}
+
+func (ar *AttachmentRepository) CreateAttachment(
+	ctx context.Context, attachment *attachment_entity.Attachment) *internal_error.InternalError {
+	attachmentEntityMongo := &AttachmentEntityMongo{
+		Id:          attachment.Id,
+		AuctionId:   attachment.AuctionId,
+		Kind:        attachment.Kind,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Timestamp:   attachment.Timestamp.Unix(),
+	}
+
+	if _, err := ar.Collection.InsertOne(ctx, attachmentEntityMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert attachment", err)
+		return mongodb.NewDatabaseError("Error trying to insert attachment", err)
+	}
+
+	return nil
+}
+
+func (ar *AttachmentRepository) FindAttachmentById(
+	ctx context.Context, attachmentId string) (*attachment_entity.Attachment, *internal_error.InternalError) {
+	var attachmentEntityMongo AttachmentEntityMongo
+	if err := ar.Collection.FindOne(ctx, bson.M{"_id": attachmentId}).Decode(&attachmentEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Attachment not found with this id = %s", attachmentId))
+		}
+
+		logger.ErrorContext(ctx, "Error trying to find attachment", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find attachment", err)
+	}
+
+	attachment := toAttachmentEntity(attachmentEntityMongo)
+	return &attachment, nil
+}
+
+func (ar *AttachmentRepository) FindAttachmentsByAuctionIds(
+	ctx context.Context, auctionIds []string) ([]attachment_entity.Attachment, *internal_error.InternalError) {
+	filter := bson.M{"auction_id": bson.M{"$in": auctionIds}}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := ar.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find attachments", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find attachments", err)
+	}
+	defer cursor.Close(ctx)
+
+	var attachmentEntitiesMongo []AttachmentEntityMongo
+	if err := cursor.All(ctx, &attachmentEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find attachments", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find attachments", err)
+	}
+
+	var attachments []attachment_entity.Attachment
+	for _, attachmentEntityMongo := range attachmentEntitiesMongo {
+		attachments = append(attachments, toAttachmentEntity(attachmentEntityMongo))
+	}
+
+	return attachments, nil
+}
+
+func (ar *AttachmentRepository) DeleteAttachment(
+	ctx context.Context, attachmentId string) *internal_error.InternalError {
+	result, err := ar.Collection.DeleteOne(ctx, bson.M{"_id": attachmentId})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to delete attachment", err)
+		return mongodb.NewDatabaseError("Error trying to delete attachment", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Attachment not found with this id = %s", attachmentId))
+	}
+
+	return nil
+}
+
+func toAttachmentEntity(attachment AttachmentEntityMongo) attachment_entity.Attachment {
+	return attachment_entity.Attachment{
+		Id:          attachment.Id,
+		AuctionId:   attachment.AuctionId,
+		Kind:        attachment.Kind,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		Timestamp:   time.Unix(attachment.Timestamp, 0),
+	}
+}
@@ -2,77 +2,354 @@ package auction
 
 import (
 	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/database/mongodb"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/infra/database/outbox"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("fullcycle-auction_go/internal/infra/database/auction")
+
 type AuctionEntityMongo struct {
-	Id          string                          `bson:"_id"`
-	ProductName string                          `bson:"product_name"`
-	Category    string                          `bson:"category"`
-	Description string                          `bson:"description"`
-	Condition   auction_entity.ProductCondition `bson:"condition"`
-	Status      auction_entity.AuctionStatus    `bson:"status"`
-	Timestamp   int64                           `bson:"timestamp"`
+	Id              string                          `bson:"_id"`
+	SellerId        string                          `bson:"seller_id"`
+	ProductName     string                          `bson:"product_name"`
+	Category        string                          `bson:"category"`
+	Description     string                          `bson:"description"`
+	Condition       auction_entity.ProductCondition `bson:"condition"`
+	Status          auction_entity.AuctionStatus    `bson:"status"`
+	Timestamp       int64                           `bson:"timestamp"`
+	EndTime         int64                           `bson:"end_time"`
+	CurrentPrice    float64                         `bson:"current_price"`
+	ReservePrice    float64                         `bson:"reserve_price"`
+	HighestBidId    string                          `bson:"highest_bid_id"`
+	HighestBidderId string                          `bson:"highest_bidder_id"`
+	BidCount        int64                           `bson:"bid_count"`
+	Format          auction_entity.AuctionFormat    `bson:"format"`
+	BuyItNowPrice   float64                         `bson:"buy_it_now_price"`
+	SecondPrice     float64                         `bson:"second_price"`
+	Dutch           *DutchScheduleMongo             `bson:"dutch,omitempty"`
+	Outbox          []*outbox.EventEntityMongo      `bson:"outbox,omitempty"`
+}
+
+type DutchScheduleMongo struct {
+	StartPrice      float64 `bson:"start_price"`
+	Decrement       float64 `bson:"decrement"`
+	IntervalSeconds int64   `bson:"interval_seconds"`
+	FloorPrice      float64 `bson:"floor_price"`
 }
+
+// closingSweepBatchSize bounds how many ended auctions a sweep reads at once.
+const closingSweepBatchSize = 100
+
+// AuctionRepository closes each auction with a timer started when it is
+// created, and with a periodic sweep for the auctions whose timer was lost
+// with the process that created them.
 type AuctionRepository struct {
-	Collection *mongo.Collection
+	Collection      *mongo.Collection
+	auctionInterval time.Duration
+	sweepInterval   time.Duration
+	statusListeners []func(auctionId string, status auction_entity.AuctionStatus)
+	stopSweep       context.CancelFunc
+	sweepDone       chan struct{}
 }
 
-func NewAuctionRepository(database *mongo.Database) *AuctionRepository {
+// NewAuctionRepository closes expired auctions that no timer closed with a
+// sweep every sweepInterval, see StartClosingSweep.
+func NewAuctionRepository(
+	database *mongo.Database, auctionInterval, sweepInterval time.Duration) *AuctionRepository {
 	return &AuctionRepository{
-		Collection: database.Collection("auctions"),
+		Collection:      database.Collection("auctions"),
+		auctionInterval: auctionInterval,
+		sweepInterval:   sweepInterval,
 	}
 }
 
 func (ar *AuctionRepository) CreateAuction(
 	ctx context.Context,
 	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	if auctionEntity.EndTime.IsZero() {
+		auctionEntity.EndTime = auctionEntity.Timestamp.Add(ar.auctionInterval)
+	}
+
+	createdEvent, eventErr := outbox.NewPendingEvent(event_entity.AuctionCreated, auctionEntity.Id,
+		event_entity.AuctionCreatedData{
+			AuctionId:   auctionEntity.Id,
+			SellerId:    auctionEntity.SellerId,
+			ProductName: auctionEntity.ProductName,
+			Category:    auctionEntity.Category,
+			Format:      string(auctionEntity.Format),
+			EndTime:     auctionEntity.EndTime,
+		})
+	if eventErr != nil {
+		return eventErr
+	}
+
 	auctionEntityMongo := &AuctionEntityMongo{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   auctionEntity.Condition,
-		Status:      auctionEntity.Status,
-		Timestamp:   auctionEntity.Timestamp.Unix(),
+		Id:            auctionEntity.Id,
+		SellerId:      auctionEntity.SellerId,
+		ProductName:   auctionEntity.ProductName,
+		Category:      auctionEntity.Category,
+		Description:   auctionEntity.Description,
+		Condition:     auctionEntity.Condition,
+		Status:        auctionEntity.Status,
+		Timestamp:     auctionEntity.Timestamp.Unix(),
+		EndTime:       auctionEntity.EndTime.Unix(),
+		ReservePrice:  auctionEntity.ReservePrice,
+		Format:        auctionEntity.Format,
+		BuyItNowPrice: auctionEntity.BuyItNowPrice,
+		Outbox:        []*outbox.EventEntityMongo{createdEvent},
+	}
+	if auctionEntity.Format == auction_entity.Dutch {
+		auctionEntityMongo.Dutch = &DutchScheduleMongo{
+			StartPrice:      auctionEntity.DutchSchedule.StartPrice,
+			Decrement:       auctionEntity.DutchSchedule.Decrement,
+			IntervalSeconds: int64(auctionEntity.DutchSchedule.Interval / time.Second),
+			FloorPrice:      auctionEntity.DutchSchedule.FloorPrice,
+		}
 	}
 	_, err := ar.Collection.InsertOne(ctx, auctionEntityMongo)
 	if err != nil {
-		logger.Error("Error trying to insert auction", err)
-		return internal_error.NewInternalServerError("Error trying to insert auction")
+		logger.ErrorContext(ctx, "Error trying to insert auction", err)
+		return mongodb.NewDatabaseError("Error trying to insert auction", err)
 	}
 
+	ar.scheduleAuctionClose(auctionEntity.Id, auctionEntity.EndTime)
+
+	return nil
+}
+
+// scheduleAuctionClose waits for the auction end time and completes it. The end
+// time is re-read before closing because soft-close bids may have extended it.
+// The timer outlives the request that created the auction, so it does not use
+// its context.
+func (ar *AuctionRepository) scheduleAuctionClose(auctionId string, endTime time.Time) {
+	ctx := logger.WithAuctionId(context.Background(), auctionId)
+
 	go func() {
-		select {
-		case <-time.After(getAuctionInterval()):
-			update := bson.M{"$set": bson.M{"status": auction_entity.Completed}}
-			filter := bson.M{"_id": auctionEntityMongo.Id}
+		for {
+			<-time.After(time.Until(endTime))
 
-			_, err := ar.Collection.UpdateOne(ctx, filter, update)
+			auctionEntity, err := ar.FindAuctionById(ctx, auctionId)
 			if err != nil {
-				logger.Error("Error trying to update auction", err)
+				logger.ErrorContext(ctx, "Error trying to find auction to close", err)
+				return
+			}
+
+			if auctionEntity.Status != auction_entity.Active {
+				return
+			}
+
+			if auctionEntity.EndTime.After(time.Now()) {
+				endTime = auctionEntity.EndTime
+				continue
+			}
+
+			closed, err := ar.closeAuction(ctx, auctionEntity, time.Now())
+			if err != nil {
+				logger.ErrorContext(ctx, "Error trying to update auction", err)
+				return
+			}
+
+			if closed {
+				logger.InfoContext(ctx, "Auction closed", zap.String("auction_id", auctionId))
+				ar.notifyStatusChange(auctionId, auction_entity.Completed)
 				return
 			}
 		}
+	}()
+}
+
+// StartClosingSweep closes the auctions that ended while no replica was
+// running, then keeps closing ended auctions every sweepInterval until
+// StopClosingSweep is called. It must be started after the status listeners
+// are registered, or auctions closed meanwhile would not be settled.
+func (ar *AuctionRepository) StartClosingSweep(ctx context.Context) {
+	ctx, ar.stopSweep = context.WithCancel(ctx)
+	ar.sweepDone = make(chan struct{})
+
+	go func() {
+		defer close(ar.sweepDone)
 
+		ar.closeEndedAuctions(ctx, time.Now())
+
+		ticker := time.NewTicker(ar.sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ar.closeEndedAuctions(ctx, time.Now())
+			}
+		}
 	}()
+}
 
-	return nil
+func (ar *AuctionRepository) StopClosingSweep() {
+	if ar.stopSweep == nil {
+		return
+	}
+
+	ar.stopSweep()
+	<-ar.sweepDone
 }
 
-func getAuctionInterval() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
+// closeEndedAuctions completes every active auction whose end time has passed.
+// closeAuction only matches auctions still active, so an auction closed at the
+// same time by its timer or by another replica is notified once.
+func (ar *AuctionRepository) closeEndedAuctions(ctx context.Context, now time.Time) {
+	ctx, span := tracer.Start(ctx, "AuctionRepository.closeEndedAuctions")
+	defer span.End()
+
+	closedCount := 0
+	defer func() { span.SetAttributes(attribute.Int("auction.closed_count", closedCount)) }()
+
+	filter := bson.M{"status": auction_entity.Active, "end_time": bson.M{"$lte": now.Unix()}}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "end_time", Value: 1}}).
+		SetLimit(closingSweepBatchSize)
+
+	for {
+		cursor, err := ar.Collection.Find(ctx, filter, opts)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.ErrorContext(ctx, "Error trying to find ended auctions", err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return
+		}
+
+		var auctionsMongo []AuctionEntityMongo
+		if err := cursor.All(ctx, &auctionsMongo); err != nil {
+			if ctx.Err() == nil {
+				logger.ErrorContext(ctx, "Error trying to find ended auctions", err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return
+		}
+
+		closedInBatch := 0
+		for _, auctionMongo := range auctionsMongo {
+			auctionEntity := ar.toAuctionEntity(auctionMongo)
+			auctionCtx := logger.WithAuctionId(ctx, auctionEntity.Id)
+
+			closed, err := ar.closeAuction(auctionCtx, &auctionEntity, now)
+			if err != nil {
+				logger.ErrorContext(auctionCtx, "Error trying to update auction", err)
+				continue
+			}
+
+			if closed {
+				closedInBatch++
+				logger.InfoContext(auctionCtx, "Auction closed", zap.String("auction_id", auctionEntity.Id))
+				ar.notifyStatusChange(auctionEntity.Id, auction_entity.Completed)
+			}
+		}
+		closedCount += closedInBatch
+
+		// Auctions left active, like one whose highest bid changed while it was
+		// read, are retried by the next sweep rather than read again here.
+		if len(auctionsMongo) < closingSweepBatchSize || closedInBatch == 0 {
+			return
+		}
+	}
+}
+
+// closeAuction completes the auction once its end time has passed. The second
+// price of a sealed second-price auction is recomputed from the stored bids
+// as it closes, and only if its highest bid is still the one it was computed
+// for.
+func (ar *AuctionRepository) closeAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction, now time.Time) (bool, *internal_error.InternalError) {
+	ctx, span := tracer.Start(ctx, "AuctionRepository.closeAuction",
+		trace.WithAttributes(attribute.String("auction.id", auctionEntity.Id)))
+	defer span.End()
+
+	filter := bson.M{
+		"_id":      auctionEntity.Id,
+		"status":   auction_entity.Active,
+		"end_time": bson.M{"$lte": now.Unix()},
+	}
+	set := bson.M{"status": auction_entity.Completed}
+
+	if auctionEntity.Format == auction_entity.SealedSecondPrice {
+		secondPrice, err := ar.storedSecondPrice(ctx, auctionEntity.Id, auctionEntity.HighestBidderId)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return false, err
+		}
+
+		filter["highest_bid_id"] = auctionEntity.HighestBidId
+		set["second_price"] = secondPrice
+	}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, bson.M{"$set": set})
 	if err != nil {
-		return time.Minute * 5
+		logger.ErrorContext(ctx, "Error trying to close auction", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return false, mongodb.NewDatabaseError("Error trying to close auction", err)
 	}
 
-	return duration
+	span.SetAttributes(attribute.Bool("auction.closed", result.MatchedCount > 0))
+	return result.MatchedCount > 0, nil
+}
+
+// storedSecondPrice is the highest stored bid of anyone other than the highest
+// bidder. PlaceBid raises second_price before the bid is written, and
+// RevertHighestBid cannot lower it again when that write fails, since later
+// bids may have raised it too; only the stored bids tell what it should be.
+func (ar *AuctionRepository) storedSecondPrice(
+	ctx context.Context, auctionId, highestBidderId string) (float64, *internal_error.InternalError) {
+	filter := bson.M{"auction_id": auctionId, "user_id": bson.M{"$ne": highestBidderId}}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "amount", Value: -1}}).
+		SetProjection(bson.M{"amount": 1})
+
+	var bid struct {
+		Amount float64 `bson:"amount"`
+	}
+	err := ar.Collection.Database().Collection("bids").FindOne(ctx, filter, opts).Decode(&bid)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find the second price", err)
+		return 0, mongodb.NewDatabaseError("Error trying to find the second price", err)
+	}
+
+	return bid.Amount, nil
+}
+
+func (ar *AuctionRepository) ExtendAuctionEndTime(
+	ctx context.Context,
+	auctionId string, endTime time.Time) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId, "status": auction_entity.Active}
+	update := bson.M{"$max": bson.M{"end_time": endTime.Unix()}}
+
+	if _, err := ar.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to extend auction end time", err)
+		return mongodb.NewDatabaseError("Error trying to extend auction end time", err)
+	}
+
+	return nil
 }
@@ -2,7 +2,6 @@ package auction
 
 import (
 	"context"
-	"os"
 	"sync"
 	"testing"
 	"time"
@@ -49,47 +48,6 @@ func TestAutoCloseRoutineTriggersAfterInterval(t *testing.T) {
 	}
 }
 
-func TestGetAuctionInterval(t *testing.T) {
-	tests := []struct {
-		name           string
-		envValue       string
-		expectedResult time.Duration
-	}{
-		{
-			name:           "valid duration from env",
-			envValue:       "10m",
-			expectedResult: 10 * time.Minute,
-		},
-		{
-			name:           "valid duration in seconds",
-			envValue:       "30s",
-			expectedResult: 30 * time.Second,
-		},
-		{
-			name:           "invalid duration falls back to default",
-			envValue:       "invalid",
-			expectedResult: 5 * time.Minute,
-		},
-		{
-			name:           "empty env falls back to default",
-			envValue:       "",
-			expectedResult: 5 * time.Minute,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			os.Setenv("AUCTION_INTERVAL", tt.envValue)
-			defer os.Unsetenv("AUCTION_INTERVAL")
-
-			result := getAuctionInterval()
-			if result != tt.expectedResult {
-				t.Errorf("Expected %v, got %v", tt.expectedResult, result)
-			}
-		})
-	}
-}
-
 func TestUpdateAuctionStatusToCompleted(t *testing.T) {
 	filter := bson.M{
 		"_id":    "test-id",
@@ -2,80 +2,250 @@ package auction
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/internal_error"
+	"regexp"
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"time"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const defaultAuctionPageSize = 20
+
+var auctionSortFields = map[auction_entity.AuctionSortField]string{
+	auction_entity.SortByCreated:      "timestamp",
+	auction_entity.SortByEndTime:      "end_time",
+	auction_entity.SortByCurrentPrice: "current_price",
+}
+
+// auctionCursor points at the last auction of a page by its sort value and id,
+// so the next page keeps a stable order even when new auctions are inserted.
+type auctionCursor struct {
+	Value float64 `json:"v"`
+	Id    string  `json:"id"`
+}
+
 func (ar *AuctionRepository) FindAuctionById(
 	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
 	filter := bson.M{"_id": id}
 
 	var auctionEntityMongo AuctionEntityMongo
 	if err := ar.Collection.FindOne(ctx, filter).Decode(&auctionEntityMongo); err != nil {
-		logger.Error(fmt.Sprintf("Error trying to find auction by id = %s", id), err)
-		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
-	}
-
-	return &auction_entity.Auction{
-		Id:          auctionEntityMongo.Id,
-		ProductName: auctionEntityMongo.ProductName,
-		Category:    auctionEntityMongo.Category,
-		Description: auctionEntityMongo.Description,
-		Condition:   auctionEntityMongo.Condition,
-		Status:      auctionEntityMongo.Status,
-		Timestamp:   time.Unix(auctionEntityMongo.Timestamp, 0),
-	}, nil
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", id))
+		}
+
+		logger.ErrorContext(ctx, fmt.Sprintf("Error trying to find auction by id = %s", id), err)
+		return nil, mongodb.NewDatabaseError("Error trying to find auction by id", err)
+	}
+
+	auctionEntity := ar.toAuctionEntity(auctionEntityMongo)
+	return &auctionEntity, nil
 }
 
 func (repo *AuctionRepository) FindAuctions(
 	ctx context.Context,
-	status auction_entity.AuctionStatus,
-	category string,
-	productName string) ([]auction_entity.Auction, *internal_error.InternalError) {
+	auctionFilter auction_entity.AuctionFilter) (*auction_entity.AuctionPage, *internal_error.InternalError) {
 	filter := bson.M{}
 
-	if status != 0 {
-		filter["status"] = status
+	if auctionFilter.Status != nil {
+		filter["status"] = *auctionFilter.Status
+	}
+
+	if auctionFilter.Category != "" {
+		filter["category"] = auctionFilter.Category
+	}
+
+	if auctionFilter.ProductName != "" {
+		filter["product_name"] = primitive.Regex{
+			Pattern: regexp.QuoteMeta(auctionFilter.ProductName), Options: "i"}
 	}
 
-	if category != "" {
-		filter["category"] = category
+	if auctionFilter.Condition != nil {
+		filter["condition"] = *auctionFilter.Condition
 	}
 
-	if productName != "" {
-		filter["productName"] = primitive.Regex{Pattern: productName, Options: "i"}
+	priceFilter := bson.M{}
+	if auctionFilter.MinPrice != nil {
+		priceFilter["$gte"] = *auctionFilter.MinPrice
+	}
+	if auctionFilter.MaxPrice != nil {
+		priceFilter["$lte"] = *auctionFilter.MaxPrice
+	}
+	if len(priceFilter) > 0 {
+		filter["current_price"] = priceFilter
+	}
+	if auctionFilter.UsesPrice() {
+		// The price of an active sealed auction must not be probed, neither
+		// by filtering nor by sorting or by the cursor of the next page.
+		filter["$nor"] = bson.A{bson.M{
+			"status": auction_entity.Active,
+			"format": bson.M{"$in": bson.A{auction_entity.SealedFirstPrice, auction_entity.SealedSecondPrice}},
+		}}
+	}
+
+	if auctionFilter.EndingBefore != nil {
+		filter["end_time"] = bson.M{"$lte": auctionFilter.EndingBefore.Unix()}
 	}
 
-	cursor, err := repo.Collection.Find(ctx, filter)
+	total, err := repo.Collection.CountDocuments(ctx, filter)
 	if err != nil {
-		logger.Error("Error finding auctions", err)
-		return nil, internal_error.NewInternalServerError("Error finding auctions")
+		logger.ErrorContext(ctx, "Error counting auctions", err)
+		return nil, mongodb.NewDatabaseError("Error counting auctions", err)
+	}
+
+	sortField, ok := auctionSortFields[auctionFilter.SortBy]
+	if !ok {
+		sortField = auctionSortFields[auction_entity.SortByCreated]
+	}
+
+	direction, comparison := 1, "$gt"
+	if auctionFilter.SortDescending {
+		direction, comparison = -1, "$lt"
+	}
+
+	pageFilter := filter
+	if auctionFilter.Cursor != "" {
+		cursor, err := decodeAuctionCursor(auctionFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+
+		pageFilter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{sortField: bson.M{comparison: cursor.Value}},
+			bson.M{sortField: cursor.Value, "_id": bson.M{comparison: cursor.Id}},
+		}}}}
+	}
+
+	limit := auctionFilter.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := repo.Collection.Find(ctx, pageFilter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error finding auctions", err)
+		return nil, mongodb.NewDatabaseError("Error finding auctions", err)
 	}
 	defer cursor.Close(ctx)
 
 	var auctionsMongo []AuctionEntityMongo
 	if err := cursor.All(ctx, &auctionsMongo); err != nil {
-		logger.Error("Error decoding auctions", err)
-		return nil, internal_error.NewInternalServerError("Error decoding auctions")
+		logger.ErrorContext(ctx, "Error decoding auctions", err)
+		return nil, mongodb.NewDatabaseError("Error decoding auctions", err)
+	}
+
+	var nextCursor string
+	if len(auctionsMongo) > limit {
+		auctionsMongo = auctionsMongo[:limit]
+		nextCursor = encodeAuctionCursor(auctionsMongo[limit-1], sortField)
 	}
 
 	var auctionsEntity []auction_entity.Auction
 	for _, auction := range auctionsMongo {
-		auctionsEntity = append(auctionsEntity, auction_entity.Auction{
-			Id:          auction.Id,
-			ProductName: auction.ProductName,
-			Category:    auction.Category,
-			Status:      auction.Status,
-			Description: auction.Description,
-			Condition:   auction.Condition,
-			Timestamp:   time.Unix(auction.Timestamp, 0),
-		})
-	}
-
-	return auctionsEntity, nil
+		auctionsEntity = append(auctionsEntity, repo.toAuctionEntity(auction))
+	}
+
+	return &auction_entity.AuctionPage{
+		Auctions:   auctionsEntity,
+		Total:      total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// toAuctionEntity reads auctions stored before formats existed as English
+// auctions.
+func (ar *AuctionRepository) toAuctionEntity(auction AuctionEntityMongo) auction_entity.Auction {
+	format := auction.Format
+	if format == "" {
+		format = auction_entity.English
+	}
+
+	var dutchSchedule auction_entity.DutchSchedule
+	if auction.Dutch != nil {
+		dutchSchedule = auction_entity.DutchSchedule{
+			StartPrice: auction.Dutch.StartPrice,
+			Decrement:  auction.Dutch.Decrement,
+			Interval:   time.Duration(auction.Dutch.IntervalSeconds) * time.Second,
+			FloorPrice: auction.Dutch.FloorPrice,
+		}
+	}
+
+	return auction_entity.Auction{
+		Id:              auction.Id,
+		SellerId:        auction.SellerId,
+		ProductName:     auction.ProductName,
+		Category:        auction.Category,
+		Description:     auction.Description,
+		Condition:       auction.Condition,
+		Status:          auction.Status,
+		Timestamp:       time.Unix(auction.Timestamp, 0),
+		EndTime:         ar.auctionEndTime(auction),
+		CurrentPrice:    auction.CurrentPrice,
+		ReservePrice:    auction.ReservePrice,
+		HighestBidId:    auction.HighestBidId,
+		HighestBidderId: auction.HighestBidderId,
+		BidCount:        auction.BidCount,
+		Format:          format,
+		BuyItNowPrice:   auction.BuyItNowPrice,
+		DutchSchedule:   dutchSchedule,
+		SecondPrice:     auction.SecondPrice,
+	}
+}
+
+// auctionEndTime falls back to the configured interval for auctions stored
+// before end_time was persisted.
+func (ar *AuctionRepository) auctionEndTime(auction AuctionEntityMongo) time.Time {
+	if auction.EndTime == 0 {
+		return time.Unix(auction.Timestamp, 0).Add(ar.auctionInterval)
+	}
+
+	return time.Unix(auction.EndTime, 0)
+}
+
+func encodeAuctionCursor(auction AuctionEntityMongo, sortField string) string {
+	cursor := auctionCursor{Id: auction.Id}
+	switch sortField {
+	case "end_time":
+		cursor.Value = float64(auction.EndTime)
+	case "current_price":
+		cursor.Value = auction.CurrentPrice
+	default:
+		cursor.Value = float64(auction.Timestamp)
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAuctionCursor(value string) (*auctionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor auctionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.Id == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
 }
@@ -0,0 +1,72 @@
+package auction
+
+import (
+	"context"
+	"testing"
+
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+)
+
+func TestAuctionCursorRoundTrip(t *testing.T) {
+	auction := AuctionEntityMongo{
+		Id:           "auction-id",
+		Timestamp:    1705312800,
+		EndTime:      1705313100,
+		CurrentPrice: 1500.5,
+	}
+
+	tests := []struct {
+		sortField     string
+		expectedValue float64
+	}{
+		{sortField: "timestamp", expectedValue: 1705312800},
+		{sortField: "end_time", expectedValue: 1705313100},
+		{sortField: "current_price", expectedValue: 1500.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortField, func(t *testing.T) {
+			cursor, err := decodeAuctionCursor(encodeAuctionCursor(auction, tt.sortField))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if cursor.Id != auction.Id || cursor.Value != tt.expectedValue {
+				t.Errorf("Expected %s/%v, got %s/%v",
+					auction.Id, tt.expectedValue, cursor.Id, cursor.Value)
+			}
+		})
+	}
+}
+
+func TestDecodeAuctionCursorRejectsInvalidValues(t *testing.T) {
+	for _, value := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := decodeAuctionCursor(value); err == nil {
+			t.Errorf("Expected an error decoding %q", value)
+		}
+	}
+}
+
+func TestFindAuctionsByPriceLeavesOutActiveSealedAuctions(t *testing.T) {
+	repositorytest.FindAuctionsByPriceLeavesOutActiveSealedAuctions(t,
+		func(t *testing.T, auctions []auction_entity.Auction) auction_entity.AuctionRepositoryInterface {
+			ar := newTestRepository(t)
+			for _, auctionEntity := range auctions {
+				if _, err := ar.Collection.InsertOne(context.Background(), AuctionEntityMongo{
+					Id:           auctionEntity.Id,
+					ProductName:  auctionEntity.ProductName,
+					Category:     auctionEntity.Category,
+					Description:  auctionEntity.Description,
+					Condition:    auctionEntity.Condition,
+					Status:       auctionEntity.Status,
+					Timestamp:    auctionEntity.Timestamp.Unix(),
+					EndTime:      auctionEntity.EndTime.Unix(),
+					CurrentPrice: auctionEntity.CurrentPrice,
+					Format:       auctionEntity.Format,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return ar
+		})
+}
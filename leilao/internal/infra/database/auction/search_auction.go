@@ -0,0 +1,143 @@
+package auction
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	searchIndexName       = "auction_text_search"
+	defaultSearchPageSize = 20
+)
+
+type auctionSearchResultMongo struct {
+	AuctionEntityMongo `bson:",inline"`
+	Score              float64 `bson:"score"`
+}
+
+type categoryFacetMongo struct {
+	Category string `bson:"_id"`
+	Count    int64  `bson:"count"`
+}
+
+type auctionSearchFacetMongo struct {
+	Results    []auctionSearchResultMongo `bson:"results"`
+	Total      []struct{ Count int64 }    `bson:"total"`
+	Categories []categoryFacetMongo       `bson:"categories"`
+}
+
+// EnsureSearchIndex creates the text index used by SearchAuctions. Creating
+// an index that already exists with the same definition is a no-op in MongoDB,
+// so this is safe to run on every startup. Changing the language requires
+// dropping the index first.
+func (ar *AuctionRepository) EnsureSearchIndex(ctx context.Context, language string) error {
+	_, err := ar.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "product_name", Value: "text"},
+			{Key: "category", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName(searchIndexName).
+			SetDefaultLanguage(language).
+			SetWeights(bson.D{
+				{Key: "product_name", Value: 10},
+				{Key: "category", Value: 5},
+				{Key: "description", Value: 1},
+			}),
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create auction search index", err)
+		return err
+	}
+
+	return nil
+}
+
+// SearchAuctions ranks auctions by text relevance. Category facets are counted
+// over every match of the query, before the category filter is applied, so
+// clients can show how many results each category would return.
+func (ar *AuctionRepository) SearchAuctions(
+	ctx context.Context,
+	search auction_entity.AuctionSearch) (*auction_entity.AuctionSearchPage, *internal_error.InternalError) {
+	match := bson.M{"$text": bson.M{"$search": search.Query}}
+	if search.Status != nil {
+		match["status"] = *search.Status
+	}
+
+	categoryMatch := bson.M{}
+	if search.Category != "" {
+		categoryMatch["category"] = search.Category
+	}
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = defaultSearchPageSize
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+		{{Key: "$facet", Value: bson.M{
+			"results": bson.A{
+				bson.M{"$match": categoryMatch},
+				bson.M{"$sort": bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$skip": search.Offset},
+				bson.M{"$limit": limit},
+			},
+			"total": bson.A{
+				bson.M{"$match": categoryMatch},
+				bson.M{"$count": "count"},
+			},
+			"categories": bson.A{
+				bson.M{"$group": bson.M{"_id": "$category", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+		}}},
+	}
+
+	cursor, err := ar.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching auctions", err)
+		return nil, mongodb.NewDatabaseError("Error searching auctions", err)
+	}
+	defer cursor.Close(ctx)
+
+	var facets []auctionSearchFacetMongo
+	if err := cursor.All(ctx, &facets); err != nil {
+		logger.ErrorContext(ctx, "Error decoding auction search results", err)
+		return nil, mongodb.NewDatabaseError("Error decoding auction search results", err)
+	}
+
+	searchPage := &auction_entity.AuctionSearchPage{}
+	if len(facets) == 0 {
+		return searchPage, nil
+	}
+
+	for _, result := range facets[0].Results {
+		searchPage.Results = append(searchPage.Results, auction_entity.AuctionSearchResult{
+			Auction: ar.toAuctionEntity(result.AuctionEntityMongo),
+			Score:   result.Score,
+		})
+	}
+
+	if len(facets[0].Total) > 0 {
+		searchPage.Total = facets[0].Total[0].Count
+	}
+
+	for _, category := range facets[0].Categories {
+		searchPage.Facets = append(searchPage.Facets, auction_entity.CategoryFacet{
+			Category: category.Category,
+			Count:    category.Count,
+		})
+	}
+
+	return searchPage, nil
+}
@@ -0,0 +1,267 @@
+package auction
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpdateAuction only matches active auctions without bids, so a bid accepted
+// after the auction was read is never edited over.
+func (ar *AuctionRepository) UpdateAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionEntity.Id, "status": auction_entity.Active, "bid_count": 0}
+	update := bson.M{"$set": bson.M{
+		"product_name": auctionEntity.ProductName,
+		"category":     auctionEntity.Category,
+		"description":  auctionEntity.Description,
+		"condition":    auctionEntity.Condition,
+	}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to update auction", err)
+		return mongodb.NewDatabaseError("Error trying to update auction", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewConflictError("Only active auctions without bids can be edited")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) CancelAuction(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId, "status": auction_entity.Active}
+	update := bson.M{"$set": bson.M{"status": auction_entity.Cancelled}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to cancel auction", err)
+		return mongodb.NewDatabaseError("Error trying to cancel auction", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewConflictError("Only active auctions can be cancelled")
+	}
+
+	ar.notifyStatusChange(auctionId, auction_entity.Cancelled)
+
+	return nil
+}
+
+// PlaceHighestBid atomically makes the bid the highest one of the auction. The
+// update only matches an active auction whose end time has not passed and whose
+// current price is below the amount, so concurrent writers can never replace a
+// higher bid. It returns the auction as it was before the update, or nil when
+// the bid was rejected.
+func (ar *AuctionRepository) PlaceHighestBid(
+	ctx context.Context,
+	auctionId, bidId, bidderId string,
+	amount float64, bidTime time.Time) (*auction_entity.Auction, *internal_error.InternalError) {
+	filter := bson.M{
+		"_id":           auctionId,
+		"status":        auction_entity.Active,
+		"end_time":      bson.M{"$gte": bidTime.Unix()},
+		"current_price": bson.M{"$lt": amount},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"current_price":     amount,
+			"highest_bid_id":    bidId,
+			"highest_bidder_id": bidderId,
+		},
+		"$inc": bson.M{"bid_count": 1},
+	}
+
+	var auctionEntityMongo AuctionEntityMongo
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+	if err := ar.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&auctionEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.ErrorContext(ctx, "Error trying to place highest bid", err)
+		return nil, mongodb.NewDatabaseError("Error trying to place highest bid", err)
+	}
+
+	auctionEntity := ar.toAuctionEntity(auctionEntityMongo)
+	return &auctionEntity, nil
+}
+
+// maxBidPlacementAttempts bounds how many times PlaceBid retries a bid that
+// lost the race against another bid on the same auction.
+const maxBidPlacementAttempts = 5
+
+// PlaceBid applies the bid with the rules of the auction format, for the
+// auctions PlaceHighestBid cannot handle with a single conditional update.
+// The auction is read, the bid applied with ApplyBid and the new state written
+// only if every field ApplyBid looked at is unchanged; otherwise the bid is
+// retried on the new state. It returns the auction as it was before the bid,
+// or nil when the bid was rejected, and whether the bid closes the auction.
+// The auction is not closed here: CloseAuctionOnBid does it once the bid is
+// stored, so a bid that fails to be written never ends an auction.
+func (ar *AuctionRepository) PlaceBid(
+	ctx context.Context,
+	auctionId, bidId, bidderId string,
+	amount float64, bidTime time.Time) (*auction_entity.Auction, bool, *internal_error.InternalError) {
+	for attempt := 0; attempt < maxBidPlacementAttempts; attempt++ {
+		previous, err := ar.FindAuctionById(ctx, auctionId)
+		if err != nil {
+			return nil, false, err
+		}
+
+		auctionEntity := *previous
+		placement := auctionEntity.ApplyBid(bidId, bidderId, amount, bidTime)
+		if !placement.Accepted {
+			return nil, false, nil
+		}
+
+		filter := bson.M{
+			"_id":            auctionId,
+			"status":         auction_entity.Active,
+			"end_time":       previous.EndTime.Unix(),
+			"current_price":  previous.CurrentPrice,
+			"second_price":   previous.SecondPrice,
+			"highest_bid_id": previous.HighestBidId,
+			"bid_count":      previous.BidCount,
+		}
+		update := bson.M{"$set": bson.M{
+			"current_price":     auctionEntity.CurrentPrice,
+			"second_price":      auctionEntity.SecondPrice,
+			"highest_bid_id":    auctionEntity.HighestBidId,
+			"highest_bidder_id": auctionEntity.HighestBidderId,
+			"bid_count":         auctionEntity.BidCount,
+		}}
+
+		result, updateErr := ar.Collection.UpdateOne(ctx, filter, update)
+		if updateErr != nil {
+			logger.ErrorContext(ctx, "Error trying to place bid", updateErr)
+			return nil, false, mongodb.NewDatabaseError("Error trying to place bid", updateErr)
+		}
+
+		if result.MatchedCount > 0 {
+			return previous, placement.Closes, nil
+		}
+	}
+
+	return nil, false, internal_error.NewUnavailableError(
+		"Error trying to place bid, the auction kept changing")
+}
+
+// CloseAuctionOnBid completes an auction ended by one of its bids, like a
+// bid reaching the buy-it-now price, with the bid time as its end time.
+func (ar *AuctionRepository) CloseAuctionOnBid(
+	ctx context.Context,
+	auctionId, bidId string, bidTime time.Time) *internal_error.InternalError {
+	filter := bson.M{"_id": auctionId, "status": auction_entity.Active, "highest_bid_id": bidId}
+	update := bson.M{"$set": bson.M{"status": auction_entity.Completed, "end_time": bidTime.Unix()}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to close auction", err)
+		return mongodb.NewDatabaseError("Error trying to close auction", err)
+	}
+
+	if result.MatchedCount > 0 {
+		ar.notifyStatusChange(auctionId, auction_entity.Completed)
+	}
+
+	return nil
+}
+
+// RevertHighestBid undoes the placement of bidId when the bid itself could
+// not be stored: the highest bid recorded before it is restored, or, if a
+// later bid already beat it, only the bid count is corrected. A sealed bid
+// may still have raised the second price; closeAuction recomputes it from the
+// stored bids.
+func (ar *AuctionRepository) RevertHighestBid(
+	ctx context.Context,
+	previous *auction_entity.Auction, bidId string) *internal_error.InternalError {
+	filter := bson.M{"_id": previous.Id, "highest_bid_id": bidId}
+	update := bson.M{
+		"$set": bson.M{
+			"current_price":     previous.CurrentPrice,
+			"second_price":      previous.SecondPrice,
+			"highest_bid_id":    previous.HighestBidId,
+			"highest_bidder_id": previous.HighestBidderId,
+		},
+		"$inc": bson.M{"bid_count": -1},
+	}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to revert highest bid", err)
+		return mongodb.NewDatabaseError("Error trying to revert highest bid", err)
+	}
+
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	filter = bson.M{"_id": previous.Id}
+	update = bson.M{"$inc": bson.M{"bid_count": -1}}
+	if _, err := ar.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to revert bid count", err)
+		return mongodb.NewDatabaseError("Error trying to revert highest bid", err)
+	}
+
+	return nil
+}
+
+// OnStatusChange registers a callback fired whenever this repository closes or
+// cancels an auction, so caches of auction state can follow along.
+func (ar *AuctionRepository) OnStatusChange(
+	listener func(auctionId string, status auction_entity.AuctionStatus)) {
+	ar.statusListeners = append(ar.statusListeners, listener)
+}
+
+func (ar *AuctionRepository) notifyStatusChange(
+	auctionId string, status auction_entity.AuctionStatus) {
+	for _, listener := range ar.statusListeners {
+		listener(auctionId, status)
+	}
+}
+
+type auctionChangeMongo struct {
+	DocumentKey struct {
+		Id string `bson:"_id"`
+	} `bson:"documentKey"`
+}
+
+// WatchStateChanges calls onChange with the id of every auction whose status
+// or end time changes, on any replica, until ctx is done or the change stream
+// fails. Change streams need MongoDB to run as a replica set.
+func (ar *AuctionRepository) WatchStateChanges(ctx context.Context, onChange func(auctionId string)) error {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"$or": bson.A{
+		bson.M{"operationType": bson.M{"$in": bson.A{"replace", "delete"}}},
+		bson.M{"updateDescription.updatedFields.status": bson.M{"$exists": true}},
+		bson.M{"updateDescription.updatedFields.end_time": bson.M{"$exists": true}},
+	}}}}}
+
+	stream, err := ar.Collection.Watch(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change auctionChangeMongo
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+		onChange(change.DocumentKey.Id)
+	}
+
+	return stream.Err()
+}
@@ -0,0 +1,123 @@
+package auction
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"fullcycle-auction_go/internal/entity/auction_entity"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newTestRepository needs a MongoDB instance:
+//
+//	MONGODB_URL=mongodb://localhost:27017 go test ./internal/infra/database/auction
+func newTestRepository(t *testing.T) *AuctionRepository {
+	mongoURL := os.Getenv("MONGODB_URL")
+	if mongoURL == "" {
+		t.Skip("MONGODB_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	database := client.Database("auctions_test_" + uuid.New().String()[:8])
+	t.Cleanup(func() {
+		database.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	return NewAuctionRepository(database, time.Minute, time.Second)
+}
+
+func TestCloseAuctionRecomputesTheSecondPriceFromStoredBids(t *testing.T) {
+	ar := newTestRepository(t)
+	ctx := context.Background()
+
+	auctionEntity, _ := auction_entity.CreateAuction(uuid.New().String(), "Guitar", "Music",
+		"Vintage electric guitar", auction_entity.New, 0, auction_entity.SealedSecondPrice, 0, auction_entity.DutchSchedule{})
+	auctionEntity.EndTime = time.Now().Add(time.Hour)
+	if err := ar.CreateAuction(ctx, auctionEntity); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	winner, runnerUp, lost := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	for _, bid := range []struct {
+		id, userId string
+		amount     float64
+	}{
+		{uuid.New().String(), winner, 100},
+		{uuid.New().String(), runnerUp, 70},
+		{uuid.New().String(), lost, 90},
+	} {
+		if _, _, err := ar.PlaceBid(ctx, auctionEntity.Id, bid.id, bid.userId, bid.amount, time.Now()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if bid.userId != lost {
+			ar.Collection.Database().Collection("bids").InsertOne(ctx, bson.M{
+				"_id": bid.id, "auction_id": auctionEntity.Id, "user_id": bid.userId, "amount": bid.amount})
+		}
+	}
+
+	placed, _ := ar.FindAuctionById(ctx, auctionEntity.Id)
+	if placed.SecondPrice != 90 {
+		t.Fatalf("Expected the unstored bid to have raised the second price to 90, got %.2f", placed.SecondPrice)
+	}
+
+	closed, err := ar.closeAuction(ctx, placed, time.Now().Add(2*time.Hour))
+	if err != nil || !closed {
+		t.Fatalf("Expected the auction to close, got %t and %v", closed, err)
+	}
+
+	completed, _ := ar.FindAuctionById(ctx, auctionEntity.Id)
+	if completed.SecondPrice != 70 || completed.Payment() != 70 {
+		t.Errorf("Expected the winner to pay the stored runner-up bid of 70, got second price %.2f and payment %.2f",
+			completed.SecondPrice, completed.Payment())
+	}
+}
+
+// TestClosingSweepClosesAuctionsWithoutATimer stores ended auctions directly,
+// like auctions whose timer was lost in a restart.
+func TestClosingSweepClosesAuctionsWithoutATimer(t *testing.T) {
+	ar := newTestRepository(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ended, running := uuid.New().String(), uuid.New().String()
+	for auctionId, endTime := range map[string]time.Time{ended: now.Add(-time.Minute), running: now.Add(time.Hour)} {
+		if _, err := ar.Collection.InsertOne(ctx, AuctionEntityMongo{
+			Id:        auctionId,
+			Status:    auction_entity.Active,
+			Format:    auction_entity.English,
+			Timestamp: now.Add(-time.Hour).Unix(),
+			EndTime:   endTime.Unix(),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var closed []string
+	ar.OnStatusChange(func(auctionId string, status auction_entity.AuctionStatus) {
+		if status == auction_entity.Completed {
+			closed = append(closed, auctionId)
+		}
+	})
+
+	ar.closeEndedAuctions(ctx, now)
+	ar.closeEndedAuctions(ctx, now)
+
+	if len(closed) != 1 || closed[0] != ended {
+		t.Errorf("Expected only the ended auction to be closed once, got %v", closed)
+	}
+	if auctionEntity, _ := ar.FindAuctionById(ctx, running); auctionEntity.Status != auction_entity.Active {
+		t.Errorf("Expected the running auction to stay active, got status %d", auctionEntity.Status)
+	}
+}
@@ -0,0 +1,127 @@
+package bid
+
+import (
+	"container/list"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"sync"
+	"time"
+)
+
+// auctionState is what bids are checked against before being placed.
+type auctionState struct {
+	status  auction_entity.AuctionStatus
+	endTime time.Time
+	classic bool
+}
+
+type auctionStateEntry struct {
+	auctionId string
+	state     auctionState
+	expiresAt time.Time
+}
+
+// auctionStateCache keeps the state of at most size auctions, each for at most
+// ttl, evicting the least recently used auction when full. The TTL bounds how
+// long a replica can act on a state changed by another one when no
+// invalidation reaches it.
+type auctionStateCache struct {
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	mutex   *sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newAuctionStateCache(size int, ttl time.Duration) *auctionStateCache {
+	return &auctionStateCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		mutex:   &sync.Mutex{},
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (c *auctionStateCache) get(auctionId string) (auctionState, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[auctionId]
+	if !ok {
+		return auctionState{}, false
+	}
+
+	entry := element.Value.(*auctionStateEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		return auctionState{}, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.state, true
+}
+
+func (c *auctionStateCache) set(auctionId string, state auctionState) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.entries[auctionId]; ok {
+		entry := element.Value.(*auctionStateEntry)
+		entry.state, entry.expiresAt = state, expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[auctionId] = c.order.PushFront(
+		&auctionStateEntry{auctionId: auctionId, state: state, expiresAt: expiresAt})
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// extendEndTime moves the cached end time of an auction forward; end times
+// only grow, so an older value never replaces a newer one.
+func (c *auctionStateCache) extendEndTime(auctionId string, endTime time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[auctionId]; ok {
+		entry := element.Value.(*auctionStateEntry)
+		if endTime.After(entry.state.endTime) {
+			entry.state.endTime = endTime
+		}
+	}
+}
+
+func (c *auctionStateCache) invalidate(auctionId string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[auctionId]; ok {
+		c.remove(element)
+	}
+}
+
+func (c *auctionStateCache) clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *auctionStateCache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *auctionStateCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*auctionStateEntry).auctionId)
+}
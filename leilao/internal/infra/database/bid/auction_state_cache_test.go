@@ -0,0 +1,79 @@
+package bid
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"testing"
+	"time"
+)
+
+func TestAuctionStateCacheExpiresEntries(t *testing.T) {
+	now := time.Now()
+	cache := newAuctionStateCache(10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.set("auction", auctionState{status: auction_entity.Active, endTime: now.Add(time.Hour)})
+
+	now = now.Add(59 * time.Second)
+	if _, ok := cache.get("auction"); !ok {
+		t.Fatal("Expected the state to be cached before the TTL")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok := cache.get("auction"); ok {
+		t.Error("Expected the state to expire after the TTL")
+	}
+	if cache.len() != 0 {
+		t.Errorf("Expected the expired state to be dropped, got %d entries", cache.len())
+	}
+}
+
+func TestAuctionStateCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newAuctionStateCache(3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		cache.set(fmt.Sprint(i), auctionState{status: auction_entity.Active})
+	}
+	cache.get("0")
+	cache.set("3", auctionState{status: auction_entity.Active})
+
+	if cache.len() != 3 {
+		t.Errorf("Expected the cache to stay at 3 entries, got %d", cache.len())
+	}
+	if _, ok := cache.get("1"); ok {
+		t.Error("Expected the least recently used state to be evicted")
+	}
+	for _, auctionId := range []string{"0", "2", "3"} {
+		if _, ok := cache.get(auctionId); !ok {
+			t.Errorf("Expected %s to stay cached", auctionId)
+		}
+	}
+}
+
+func TestAuctionStateCacheUpdatesAndInvalidates(t *testing.T) {
+	endTime := time.Now()
+	cache := newAuctionStateCache(10, time.Minute)
+	cache.set("auction", auctionState{status: auction_entity.Active, endTime: endTime})
+
+	cache.extendEndTime("auction", endTime.Add(-time.Minute))
+	cache.extendEndTime("auction", endTime.Add(time.Minute))
+	cache.extendEndTime("missing", endTime)
+
+	if state, _ := cache.get("auction"); !state.endTime.Equal(endTime.Add(time.Minute)) {
+		t.Errorf("Expected the end time to only move forward, got %s", state.endTime)
+	}
+	if _, ok := cache.get("missing"); ok {
+		t.Error("Expected extending an uncached auction not to cache it")
+	}
+
+	cache.invalidate("auction")
+	if _, ok := cache.get("auction"); ok {
+		t.Error("Expected the state to be invalidated")
+	}
+
+	cache.set("other", auctionState{status: auction_entity.Active})
+	cache.clear()
+	if cache.len() != 0 {
+		t.Errorf("Expected the cache to be empty, got %d entries", cache.len())
+	}
+}
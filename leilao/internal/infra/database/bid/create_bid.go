@@ -2,16 +2,30 @@ package bid
 
 import (
 	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/database/mongodb"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/event_entity"
 	"fullcycle-auction_go/internal/infra/database/auction"
+	"fullcycle-auction_go/internal/infra/database/outbox"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const (
+	duplicateKeyCode = 11000
+
+	// changeStreamRetryDelay is the wait before watching the auction changes
+	// again after the change stream failed.
+	changeStreamRetryDelay = 5 * time.Second
 )
 
 type BidEntityMongo struct {
@@ -19,103 +33,451 @@ type BidEntityMongo struct {
 	UserId    string  `bson:"user_id"`
 	AuctionId string  `bson:"auction_id"`
 	Amount    float64 `bson:"amount"`
+	Automatic bool    `bson:"automatic"`
 	Timestamp int64   `bson:"timestamp"`
+
+	Outbox []*outbox.EventEntityMongo `bson:"outbox,omitempty"`
 }
 
 type BidRepository struct {
-	Collection            *mongo.Collection
-	AuctionRepository     *auction.AuctionRepository
-	auctionInterval       time.Duration
-	auctionStatusMap      map[string]auction_entity.AuctionStatus
-	auctionEndTimeMap     map[string]time.Time
-	auctionStatusMapMutex *sync.Mutex
-	auctionEndTimeMutex   *sync.Mutex
+	Collection        *mongo.Collection
+	AuctionRepository *auction.AuctionRepository
+	auctionInterval   time.Duration
+	softClosePolicy   auction_entity.SoftClosePolicy
+	auctionCache      *auctionStateCache
+
+	stopWatching context.CancelFunc
+	watchDone    chan struct{}
+}
+
+// AuctionCacheOptions bounds the cache of the auction state bids are checked
+// against: at most Size auctions, each kept for at most TTL.
+type AuctionCacheOptions struct {
+	Size int
+	TTL  time.Duration
 }
 
-func NewBidRepository(database *mongo.Database, auctionRepository *auction.AuctionRepository) *BidRepository {
-	return &BidRepository{
-		auctionInterval:       getAuctionInterval(),
-		auctionStatusMap:      make(map[string]auction_entity.AuctionStatus),
-		auctionEndTimeMap:     make(map[string]time.Time),
-		auctionStatusMapMutex: &sync.Mutex{},
-		auctionEndTimeMutex:   &sync.Mutex{},
-		Collection:            database.Collection("bids"),
-		AuctionRepository:     auctionRepository,
+func NewBidRepository(
+	database *mongo.Database,
+	auctionRepository *auction.AuctionRepository,
+	auctionInterval time.Duration,
+	softClosePolicy auction_entity.SoftClosePolicy,
+	cacheOptions AuctionCacheOptions) *BidRepository {
+	bidRepository := &BidRepository{
+		auctionInterval:   auctionInterval,
+		softClosePolicy:   softClosePolicy,
+		auctionCache:      newAuctionStateCache(cacheOptions.Size, cacheOptions.TTL),
+		Collection:        database.Collection("bids"),
+		AuctionRepository: auctionRepository,
 	}
+
+	auctionRepository.OnStatusChange(bidRepository.invalidateAuction)
+
+	return bidRepository
+}
+
+// placedBid is a bid of the batch together with its position in the batch
+// and the auction as it was before the bid was placed on it. previous is nil
+// for a replayed bid the auction already points at. closes is set when the
+// bid ends the auction once stored.
+type placedBid struct {
+	index    int
+	bid      bid_entity.Bid
+	previous *auction_entity.Auction
+	closes   bool
 }
 
+// CreateBid stores a batch of bids in two steps. First every bid is placed on
+// its auction: bids of the same auction one at a time in timestamp order, so
+// each one has to beat the highest bid recorded before it, and different
+// auctions concurrently. Then the accepted bids are written with a single
+// unordered InsertMany, and the bids whose insert failed are reverted on
+// their auction.
 func (bd *BidRepository) CreateBid(
 	ctx context.Context,
-	bidEntities []bid_entity.Bid) *internal_error.InternalError {
-	var wg sync.WaitGroup
-	for _, bid := range bidEntities {
+	bidEntities []bid_entity.Bid) ([]bid_entity.BidFailure, *internal_error.InternalError) {
+	bidsByAuction := make(map[string][]placedBid)
+	for index, bid := range bidEntities {
+		bidsByAuction[bid.AuctionId] = append(bidsByAuction[bid.AuctionId], placedBid{index: index, bid: bid})
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		placed   [][]placedBid
+		failures []bid_entity.BidFailure
+	)
+	for _, auctionBids := range bidsByAuction {
 		wg.Add(1)
-		go func(bidValue bid_entity.Bid) {
+		go func(auctionBids []placedBid) {
 			defer wg.Done()
 
-			bd.auctionStatusMapMutex.Lock()
-			auctionStatus, okStatus := bd.auctionStatusMap[bidValue.AuctionId]
-			bd.auctionStatusMapMutex.Unlock()
+			sort.SliceStable(auctionBids, func(i, j int) bool {
+				return auctionBids[i].bid.Timestamp.Before(auctionBids[j].bid.Timestamp)
+			})
 
-			bd.auctionEndTimeMutex.Lock()
-			auctionEndTime, okEndTime := bd.auctionEndTimeMap[bidValue.AuctionId]
-			bd.auctionEndTimeMutex.Unlock()
+			auctionPlaced, auctionFailures := bd.placeBids(ctx, auctionBids)
 
-			bidEntityMongo := &BidEntityMongo{
-				Id:        bidValue.Id,
-				UserId:    bidValue.UserId,
-				AuctionId: bidValue.AuctionId,
-				Amount:    bidValue.Amount,
-				Timestamp: bidValue.Timestamp.Unix(),
+			mutex.Lock()
+			defer mutex.Unlock()
+			if len(auctionPlaced) > 0 {
+				placed = append(placed, auctionPlaced)
 			}
+			failures = append(failures, auctionFailures...)
+		}(auctionBids)
+	}
+	wg.Wait()
 
-			if okEndTime && okStatus {
-				now := time.Now()
-				if auctionStatus == auction_entity.Completed || now.After(auctionEndTime) {
-					return
-				}
+	insertFailures, err := bd.insertBids(ctx, placed)
+	failures = append(failures, insertFailures...)
 
-				if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-					logger.Error("Error trying to insert bid", err)
-					return
-				}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Index < failures[j].Index
+	})
 
-				return
+	return failures, err
+}
+
+// placeBids places the bids of one auction in order and returns the accepted
+// ones along with the rejected ones.
+func (bd *BidRepository) placeBids(
+	ctx context.Context, auctionBids []placedBid) ([]placedBid, []bid_entity.BidFailure) {
+	var (
+		placed   []placedBid
+		failures []bid_entity.BidFailure
+	)
+
+	for _, placedValue := range auctionBids {
+		previous, closes, reason := bd.placeBid(ctx, placedValue.bid)
+		if reason != "" {
+			failures = append(failures, bid_entity.BidFailure{
+				Index: placedValue.index, BidId: placedValue.bid.Id, Reason: reason})
+			continue
+		}
+
+		placedValue.previous = previous
+		placedValue.closes = closes
+		placed = append(placed, placedValue)
+	}
+
+	return placed, failures
+}
+
+// placeBid places classic English bids with the atomic PlaceHighestBid and
+// the bids of every other format with PlaceBid. A cached state that refuses
+// the bid is read again first, as another replica may have extended the
+// auction; a cached state that accepts it is checked by the atomic update.
+func (bd *BidRepository) placeBid(
+	ctx context.Context, bidValue bid_entity.Bid) (*auction_entity.Auction, bool, bid_entity.BidFailureReason) {
+	state, cached := bd.auctionCache.get(bidValue.AuctionId)
+	if !cached || !state.acceptsBidAt(bidValue.Timestamp) {
+		var reason bid_entity.BidFailureReason
+		if state, reason = bd.loadAuctionState(ctx, bidValue.AuctionId); reason != "" {
+			return nil, false, reason
+		}
+	}
+
+	if !state.acceptsBidAt(bidValue.Timestamp) {
+		if bd.isHighestBid(ctx, bidValue) {
+			return nil, false, ""
+		}
+		return nil, false, bid_entity.AuctionClosed
+	}
+
+	var (
+		previous *auction_entity.Auction
+		closes   bool
+		err      *internal_error.InternalError
+	)
+	if state.classic {
+		previous, err = bd.AuctionRepository.PlaceHighestBid(
+			ctx, bidValue.AuctionId, bidValue.Id, bidValue.UserId, bidValue.Amount, bidValue.Timestamp)
+	} else {
+		previous, closes, err = bd.AuctionRepository.PlaceBid(
+			ctx, bidValue.AuctionId, bidValue.Id, bidValue.UserId, bidValue.Amount, bidValue.Timestamp)
+	}
+	if err != nil {
+		return nil, false, bid_entity.AuctionUnavailable
+	}
+
+	if previous == nil {
+		bd.auctionCache.invalidate(bidValue.AuctionId)
+
+		if bd.isHighestBid(ctx, bidValue) {
+			return nil, false, ""
+		}
+
+		logger.InfoContext(ctx, "Bid rejected by the rules of the auction format or the auction is closed",
+			zap.String("auction_id", bidValue.AuctionId),
+			zap.String("bid_id", bidValue.Id))
+		return nil, false, bid_entity.BidRejected
+	}
+
+	return previous, closes, ""
+}
+
+// loadAuctionState reads the state of an auction from the database and caches
+// it.
+func (bd *BidRepository) loadAuctionState(
+	ctx context.Context, auctionId string) (auctionState, bid_entity.BidFailureReason) {
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		if err.Code == internal_error.NotFound {
+			return auctionState{}, bid_entity.AuctionNotFound
+		}
+		return auctionState{}, bid_entity.AuctionUnavailable
+	}
+
+	state := auctionState{
+		status:  auctionEntity.Status,
+		endTime: auctionEntity.EndTime,
+		classic: auctionEntity.IsClassicEnglish(),
+	}
+	bd.auctionCache.set(auctionId, state)
+
+	return state, ""
+}
+
+func (s auctionState) acceptsBidAt(bidTime time.Time) bool {
+	return s.status == auction_entity.Active && !bidTime.After(s.endTime)
+}
+
+// isHighestBid handles a bid replayed from the write-ahead log after a crash
+// between placing it on the auction and storing it: the auction already
+// points at the bid, so only the bid document is missing.
+func (bd *BidRepository) isHighestBid(ctx context.Context, bidValue bid_entity.Bid) bool {
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
+	return err == nil && auctionEntity.HighestBidId == bidValue.Id
+}
+
+// insertBids writes the placed bids and reverts the ones that could not be
+// written. The others may close their auction or extend it under the
+// soft-close policy; their "bid accepted" event is written with the bid.
+func (bd *BidRepository) insertBids(
+	ctx context.Context, placed [][]placedBid) ([]bid_entity.BidFailure, *internal_error.InternalError) {
+	var bids []bid_entity.Bid
+	for _, auctionPlaced := range placed {
+		for _, placedValue := range auctionPlaced {
+			bids = append(bids, placedValue.bid)
+		}
+	}
+
+	if len(bids) == 0 {
+		return nil, nil
+	}
+
+	failed, stored, err := bd.writeBids(ctx, bids)
+
+	var (
+		failures []bid_entity.BidFailure
+		position int
+	)
+	for _, auctionPlaced := range placed {
+		offset := position
+		position += len(auctionPlaced)
+
+		// Reverting newest first restores each bid's previous highest bid
+		// even when several bids of the same auction failed.
+		for i := len(auctionPlaced) - 1; i >= 0; i-- {
+			placedValue := auctionPlaced[i]
+			if !failed[offset+i] {
+				continue
 			}
 
-			auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
-			if err != nil {
-				logger.Error("Error trying to find auction by id", err)
-				return
+			failures = append(failures, bid_entity.BidFailure{
+				Index: placedValue.index, BidId: placedValue.bid.Id, Reason: bid_entity.InsertFailed})
+
+			if placedValue.previous != nil {
+				bd.AuctionRepository.RevertHighestBid(ctx, placedValue.previous, placedValue.bid.Id)
 			}
-			if auctionEntity.Status == auction_entity.Completed {
-				return
+		}
+
+		for i, placedValue := range auctionPlaced {
+			if failed[offset+i] {
+				continue
 			}
 
-			bd.auctionStatusMapMutex.Lock()
-			bd.auctionStatusMap[bidValue.AuctionId] = auctionEntity.Status
-			bd.auctionStatusMapMutex.Unlock()
+			if placedValue.closes {
+				bd.closeAuctionOnBid(ctx, placedValue.bid)
+			}
 
-			bd.auctionEndTimeMutex.Lock()
-			bd.auctionEndTimeMap[bidValue.AuctionId] = auctionEntity.Timestamp.Add(bd.auctionInterval)
-			bd.auctionEndTimeMutex.Unlock()
+			if stored[offset+i] {
+				continue
+			}
 
-			if _, err := bd.Collection.InsertOne(ctx, bidEntityMongo); err != nil {
-				logger.Error("Error trying to insert bid", err)
-				return
+			if placedValue.previous != nil && !placedValue.closes &&
+				placedValue.previous.Format == auction_entity.English &&
+				bd.softClosePolicy.InWindow(placedValue.previous.EndTime, placedValue.bid.Timestamp) {
+				bd.extendAuctionEndTime(ctx, placedValue.bid)
 			}
-		}(bid)
+		}
 	}
-	wg.Wait()
-	return nil
+
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert bids", err, zap.Int("count", len(bids)))
+		return failures, mongodb.NewDatabaseError("Error trying to insert bids", err)
+	}
+
+	return failures, nil
+}
+
+// writeBids inserts the bid documents with one unordered InsertMany, so a
+// failing document does not stop the others. It returns the positions of the
+// bids that were not written and of the ones that were already stored, which
+// keeps replays idempotent: a stored bid already carries its event. err is set
+// only when the outcome of the whole insert is unknown, in which case every
+// bid is reported as failed.
+func (bd *BidRepository) writeBids(
+	ctx context.Context, bids []bid_entity.Bid) (failed, stored map[int]bool, err error) {
+	documents := make([]interface{}, 0, len(bids))
+	for _, bidValue := range bids {
+		document, eventErr := toBidEntityMongo(bidValue)
+		if eventErr != nil {
+			return insertManyFailures(eventErr, len(bids))
+		}
+		documents = append(documents, document)
+	}
+
+	_, err = bd.Collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	return insertManyFailures(err, len(bids))
+}
+
+func insertManyFailures(err error, count int) (failed, stored map[int]bool, unknown error) {
+	failed = make(map[int]bool)
+	stored = make(map[int]bool)
+	if err == nil {
+		return failed, stored, nil
+	}
+
+	var bulkWriteException mongo.BulkWriteException
+	if !errors.As(err, &bulkWriteException) {
+		for i := 0; i < count; i++ {
+			failed[i] = true
+		}
+		return failed, stored, err
+	}
+
+	for _, writeError := range bulkWriteException.WriteErrors {
+		if writeError.Code == duplicateKeyCode {
+			stored[writeError.Index] = true
+		} else {
+			failed[writeError.Index] = true
+		}
+	}
+
+	return failed, stored, nil
+}
+
+// toBidEntityMongo builds the bid document along with its "bid accepted"
+// event.
+func toBidEntityMongo(bidValue bid_entity.Bid) (*BidEntityMongo, *internal_error.InternalError) {
+	acceptedEvent, err := outbox.NewPendingEvent(event_entity.BidAccepted, bidValue.AuctionId, event_entity.BidAcceptedData{
+		BidId:     bidValue.Id,
+		AuctionId: bidValue.AuctionId,
+		UserId:    bidValue.UserId,
+		Amount:    bidValue.Amount,
+		Automatic: bidValue.Automatic,
+		Timestamp: bidValue.Timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &BidEntityMongo{
+		Id:        bidValue.Id,
+		UserId:    bidValue.UserId,
+		AuctionId: bidValue.AuctionId,
+		Amount:    bidValue.Amount,
+		Automatic: bidValue.Automatic,
+		Timestamp: bidValue.Timestamp.Unix(),
+		Outbox:    []*outbox.EventEntityMongo{acceptedEvent},
+	}, nil
 }
 
-func getAuctionInterval() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
+// closeAuctionOnBid completes the auction ended by a stored bid and keeps the
+// cached status in sync.
+func (bd *BidRepository) closeAuctionOnBid(ctx context.Context, bidValue bid_entity.Bid) {
+	if err := bd.AuctionRepository.CloseAuctionOnBid(
+		ctx, bidValue.AuctionId, bidValue.Id, bidValue.Timestamp); err != nil {
+		logger.ErrorContext(ctx, "Error trying to close auction on bid", err)
+		return
+	}
+
+	logger.InfoContext(ctx, "Auction closed by bid",
+		zap.String("auction_id", bidValue.AuctionId),
+		zap.String("bid_id", bidValue.Id))
+}
+
+// extendAuctionEndTime applies the soft-close policy to the auction of a bid
+// placed in its final window and keeps the cached end time in sync.
+func (bd *BidRepository) extendAuctionEndTime(ctx context.Context, bidValue bid_entity.Bid) {
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
 	if err != nil {
-		return time.Minute * 5
+		logger.ErrorContext(ctx, "Error trying to find auction by id", err)
+		return
+	}
+
+	nextEndTime, ok := bd.softClosePolicy.NextEndTime(
+		auctionEntity.Timestamp.Add(bd.auctionInterval), auctionEntity.EndTime, bidValue.Timestamp)
+	if !ok {
+		bd.auctionCache.extendEndTime(bidValue.AuctionId, auctionEntity.EndTime)
+		return
+	}
+
+	if err := bd.AuctionRepository.ExtendAuctionEndTime(ctx, bidValue.AuctionId, nextEndTime); err != nil {
+		logger.ErrorContext(ctx, "Error trying to extend auction end time", err)
+		return
+	}
+
+	bd.auctionCache.extendEndTime(bidValue.AuctionId, nextEndTime)
+
+	logger.InfoContext(ctx, "Auction end time extended",
+		zap.String("auction_id", bidValue.AuctionId),
+		zap.Time("end_time", nextEndTime))
+}
+
+// invalidateAuction drops the cached state of an auction closed or cancelled
+// by this replica.
+func (bd *BidRepository) invalidateAuction(auctionId string, status auction_entity.AuctionStatus) {
+	bd.auctionCache.invalidate(auctionId)
+}
+
+// WatchAuctionChanges drops the cached state of the auctions closed, cancelled
+// or extended by any replica, as they change, until StopWatchingAuctionChanges.
+// When the change stream fails the whole cache is dropped, since changes may
+// have been missed, and the stream is opened again after a delay; meanwhile
+// the cache TTL bounds how stale a state can be.
+func (bd *BidRepository) WatchAuctionChanges(ctx context.Context) {
+	ctx, bd.stopWatching = context.WithCancel(ctx)
+	bd.watchDone = make(chan struct{})
+
+	go func() {
+		defer close(bd.watchDone)
+
+		for {
+			err := bd.AuctionRepository.WatchStateChanges(ctx, bd.auctionCache.invalidate)
+			if ctx.Err() != nil {
+				return
+			}
+
+			logger.Error("Error trying to watch auction changes, retrying", err,
+				zap.Duration("retry_delay", changeStreamRetryDelay))
+			bd.auctionCache.clear()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(changeStreamRetryDelay):
+			}
+		}
+	}()
+}
+
+func (bd *BidRepository) StopWatchingAuctionChanges() {
+	if bd.stopWatching == nil {
+		return
 	}
 
-	return duration
+	bd.stopWatching()
+	<-bd.watchDone
 }
@@ -0,0 +1,124 @@
+package bid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestInsertManyFailures(t *testing.T) {
+	err := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Index: 1, Code: duplicateKeyCode}},
+		{WriteError: mongo.WriteError{Index: 3, Code: 121}},
+	}}
+
+	failed, stored, unknown := insertManyFailures(err, 4)
+	if unknown != nil {
+		t.Fatalf("Expected write errors to be mapped per bid, got %v", unknown)
+	}
+	if len(failed) != 1 || !failed[3] {
+		t.Errorf("Expected only bid 3 to fail, got %v", failed)
+	}
+	if len(stored) != 1 || !stored[1] {
+		t.Errorf("Expected bid 1 to be reported as already stored, got %v", stored)
+	}
+
+	failed, _, unknown = insertManyFailures(errors.New("connection reset"), 3)
+	if unknown == nil || len(failed) != 3 {
+		t.Errorf("Expected every bid to fail when the insert outcome is unknown, got %v", failed)
+	}
+}
+
+// The benchmarks compare the single unordered InsertMany used by CreateBid
+// with the former approach of one goroutine and one InsertOne per bid. They
+// need a MongoDB instance:
+//
+//	MONGODB_URL=mongodb://localhost:27017 go test ./internal/infra/database/bid -run ^$ -bench .
+func BenchmarkWriteBidsInsertMany(b *testing.B) {
+	bd := newTestRepository(b)
+
+	for _, size := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				bids := benchmarkBids(size)
+				b.StartTimer()
+				if _, _, err := bd.writeBids(context.Background(), bids); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteBidsInsertOne(b *testing.B) {
+	bd := newTestRepository(b)
+
+	for _, size := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				bids := benchmarkBids(size)
+				b.StartTimer()
+
+				var wg sync.WaitGroup
+				for _, bidValue := range bids {
+					wg.Add(1)
+					go func(bidValue bid_entity.Bid) {
+						defer wg.Done()
+						document, eventErr := toBidEntityMongo(bidValue)
+						if eventErr != nil {
+							b.Error(eventErr)
+							return
+						}
+						if _, err := bd.Collection.InsertOne(context.Background(), document); err != nil {
+							b.Error(err)
+						}
+					}(bidValue)
+				}
+				wg.Wait()
+			}
+		})
+	}
+}
+
+// newTestRepository uses a fresh database of the MongoDB at MONGODB_URL.
+func newTestRepository(tb testing.TB) *BidRepository {
+	mongoURL := os.Getenv("MONGODB_URL")
+	if mongoURL == "" {
+		tb.Skip("MONGODB_URL is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	database := client.Database("auctions_test_" + uuid.New().String()[:8])
+	tb.Cleanup(func() {
+		database.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	return &BidRepository{Collection: database.Collection("bids")}
+}
+
+func benchmarkBids(count int) []bid_entity.Bid {
+	auctionId := uuid.New().String()
+
+	bids := make([]bid_entity.Bid, 0, count)
+	for i := 0; i < count; i++ {
+		bid, _ := bid_entity.CreateBid(uuid.New().String(), auctionId, float64(i+1))
+		bids = append(bids, *bid)
+	}
+	return bids
+}
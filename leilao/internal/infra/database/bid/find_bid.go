@@ -2,65 +2,253 @@ package bid
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
+const defaultBidPageSize = 20
+
+// bidCursor points at the last bid of a page by its timestamp and id.
+type bidCursor struct {
+	Timestamp int64  `json:"t"`
+	Id        string `json:"id"`
+}
+
 func (bd *BidRepository) FindBidByAuctionId(
 	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
-	filter := bson.M{"auctionId": auctionId}
+	filter := bson.M{"auction_id": auctionId}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
 
-	cursor, err := bd.Collection.Find(ctx, filter)
+	cursor, err := bd.Collection.Find(ctx, filter, opts)
 	if err != nil {
-		logger.Error(
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		return nil, mongodb.NewDatabaseError(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
-		return nil, internal_error.NewInternalServerError(
-			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
 	}
+	defer cursor.Close(ctx)
 
 	var bidEntitiesMongo []BidEntityMongo
 	if err := cursor.All(ctx, &bidEntitiesMongo); err != nil {
-		logger.Error(
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		return nil, mongodb.NewDatabaseError(
 			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
-		return nil, internal_error.NewInternalServerError(
-			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
 	}
 
 	var bidEntities []bid_entity.Bid
 	for _, bidEntityMongo := range bidEntitiesMongo {
-		bidEntities = append(bidEntities, bid_entity.Bid{
-			Id:        bidEntityMongo.Id,
-			UserId:    bidEntityMongo.UserId,
-			AuctionId: bidEntityMongo.AuctionId,
-			Amount:    bidEntityMongo.Amount,
-			Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
-		})
+		bidEntities = append(bidEntities, toBidEntity(bidEntityMongo))
 	}
 
 	return bidEntities, nil
 }
 
+// FindBids pages through the bids matched by the filter and aggregates their
+// stats in the same call, so the stats do not depend on the page.
+func (bd *BidRepository) FindBids(
+	ctx context.Context, bidFilter bid_entity.BidFilter) (*bid_entity.BidPage, *internal_error.InternalError) {
+	filter := bson.M{}
+	if bidFilter.AuctionId != "" {
+		filter["auction_id"] = bidFilter.AuctionId
+	}
+	if bidFilter.UserId != "" {
+		filter["user_id"] = bidFilter.UserId
+	}
+
+	stats, err := bd.findBidStats(ctx, filter)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to aggregate bids", err)
+		return nil, mongodb.NewDatabaseError("Error trying to aggregate bids", err)
+	}
+
+	direction, comparison := 1, "$gt"
+	if bidFilter.SortDescending {
+		direction, comparison = -1, "$lt"
+	}
+
+	pageFilter := filter
+	if bidFilter.Cursor != "" {
+		cursor, err := decodeBidCursor(bidFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+
+		pageFilter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{"timestamp": bson.M{comparison: cursor.Timestamp}},
+			bson.M{"timestamp": cursor.Timestamp, "_id": bson.M{comparison: cursor.Id}},
+		}}}}
+	}
+
+	limit := bidFilter.Limit
+	if limit <= 0 {
+		limit = defaultBidPageSize
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := bd.Collection.Find(ctx, pageFilter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find bids", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find bids", err)
+	}
+	defer cursor.Close(ctx)
+
+	var bidEntitiesMongo []BidEntityMongo
+	if err := cursor.All(ctx, &bidEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find bids", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find bids", err)
+	}
+
+	var nextCursor string
+	if len(bidEntitiesMongo) > limit {
+		bidEntitiesMongo = bidEntitiesMongo[:limit]
+		last := bidEntitiesMongo[limit-1]
+		nextCursor = encodeBidCursor(bidCursor{Timestamp: last.Timestamp, Id: last.Id})
+	}
+
+	var bidEntities []bid_entity.Bid
+	for _, bidEntityMongo := range bidEntitiesMongo {
+		bidEntities = append(bidEntities, toBidEntity(bidEntityMongo))
+	}
+
+	return &bid_entity.BidPage{
+		Bids:       bidEntities,
+		Stats:      *stats,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (bd *BidRepository) findBidStats(ctx context.Context, filter bson.M) (*bid_entity.BidStats, error) {
+	cursor, err := bd.Collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":            nil,
+			"count":          bson.M{"$sum": 1},
+			"bidders":        bson.M{"$addToSet": "$user_id"},
+			"auctions":       bson.M{"$addToSet": "$auction_id"},
+			"highest_amount": bson.M{"$max": "$amount"},
+			"average_amount": bson.M{"$avg": "$amount"},
+			"first_bid_at":   bson.M{"$min": "$timestamp"},
+			"last_bid_at":    bson.M{"$max": "$timestamp"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"count":          1,
+			"bidders":        bson.M{"$size": "$bidders"},
+			"auctions":       bson.M{"$size": "$auctions"},
+			"highest_amount": 1,
+			"average_amount": 1,
+			"first_bid_at":   1,
+			"last_bid_at":    1,
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Count         int64   `bson:"count"`
+		Bidders       int64   `bson:"bidders"`
+		Auctions      int64   `bson:"auctions"`
+		HighestAmount float64 `bson:"highest_amount"`
+		AverageAmount float64 `bson:"average_amount"`
+		FirstBidAt    int64   `bson:"first_bid_at"`
+		LastBidAt     int64   `bson:"last_bid_at"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return &bid_entity.BidStats{}, nil
+	}
+
+	result := results[0]
+	return &bid_entity.BidStats{
+		Count:         result.Count,
+		Bidders:       result.Bidders,
+		Auctions:      result.Auctions,
+		HighestAmount: result.HighestAmount,
+		AverageAmount: result.AverageAmount,
+		FirstBidAt:    time.Unix(result.FirstBidAt, 0),
+		LastBidAt:     time.Unix(result.LastBidAt, 0),
+	}, nil
+}
+
+// FindWinningBidByAuctionId reads the highest bid tracked on the auction
+// document instead of sorting every bid of the auction.
 func (bd *BidRepository) FindWinningBidByAuctionId(
 	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
-	filter := bson.M{"auction_id": auctionId}
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auctionEntity.HighestBidId == "" {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
 
 	var bidEntityMongo BidEntityMongo
-	opts := options.FindOne().SetSort(bson.D{{"amount", -1}})
-	if err := bd.Collection.FindOne(ctx, filter, opts).Decode(&bidEntityMongo); err != nil {
-		logger.Error("Error trying to find the auction winner", err)
-		return nil, internal_error.NewInternalServerError("Error trying to find the auction winner")
+	filter := bson.M{"_id": auctionEntity.HighestBidId}
+	if err := bd.Collection.FindOne(ctx, filter).Decode(&bidEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("No bids found for auctionId %s", auctionId))
+		}
+
+		logger.ErrorContext(ctx, "Error trying to find the auction winner", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find the auction winner", err)
 	}
 
-	return &bid_entity.Bid{
+	bidEntity := toBidEntity(bidEntityMongo)
+	return &bidEntity, nil
+}
+
+func toBidEntity(bidEntityMongo BidEntityMongo) bid_entity.Bid {
+	return bid_entity.Bid{
 		Id:        bidEntityMongo.Id,
 		UserId:    bidEntityMongo.UserId,
 		AuctionId: bidEntityMongo.AuctionId,
 		Amount:    bidEntityMongo.Amount,
+		Automatic: bidEntityMongo.Automatic,
 		Timestamp: time.Unix(bidEntityMongo.Timestamp, 0),
-	}, nil
+	}
+}
+
+func encodeBidCursor(cursor bidCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeBidCursor(value string) (*bidCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor bidCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.Id == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
 }
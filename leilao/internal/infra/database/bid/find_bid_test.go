@@ -0,0 +1,48 @@
+package bid
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+	"testing"
+)
+
+func TestBidCursorRoundTrip(t *testing.T) {
+	cursor := bidCursor{Timestamp: 1700000000, Id: "bid"}
+
+	decoded, err := decodeBidCursor(encodeBidCursor(cursor))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *decoded != cursor {
+		t.Errorf("Expected %+v, got %+v", cursor, *decoded)
+	}
+
+	for _, value := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := decodeBidCursor(value); err == nil {
+			t.Errorf("Expected cursor %q to be rejected", value)
+		}
+	}
+}
+
+func TestFindBidsPagesWithoutOverlapOrGap(t *testing.T) {
+	repositorytest.FindBidsPagesWithoutOverlapOrGap(t,
+		func(t *testing.T, bids []bid_entity.Bid) bid_entity.BidEntityRepository {
+			bd := newTestRepository(t)
+
+			var documents []interface{}
+			for _, bid := range bids {
+				documents = append(documents, BidEntityMongo{
+					Id:        bid.Id,
+					AuctionId: bid.AuctionId,
+					UserId:    bid.UserId,
+					Amount:    bid.Amount,
+					Timestamp: bid.Timestamp.Unix(),
+				})
+			}
+			if _, err := bd.Collection.InsertMany(context.Background(), documents); err != nil {
+				t.Fatal(err)
+			}
+			return bd
+		})
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+)
+
+type AttachmentRepository struct {
+	attachments map[string]attachment_entity.Attachment
+	mutex       *sync.RWMutex
+}
+
+func NewAttachmentRepository() *AttachmentRepository {
+	return &AttachmentRepository{
+		attachments: make(map[string]attachment_entity.Attachment),
+		mutex:       &sync.RWMutex{},
+	}
+}
+
+func (ar *AttachmentRepository) CreateAttachment(
+	ctx context.Context, attachment *attachment_entity.Attachment) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	if _, ok := ar.attachments[attachment.Id]; ok {
+		return internal_error.NewConflictError("Error trying to insert attachment")
+	}
+
+	stored := *attachment
+	stored.Timestamp = truncate(stored.Timestamp)
+	ar.attachments[attachment.Id] = stored
+
+	return nil
+}
+
+func (ar *AttachmentRepository) FindAttachmentById(
+	ctx context.Context, attachmentId string) (*attachment_entity.Attachment, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	defer ar.mutex.RUnlock()
+
+	attachment, ok := ar.attachments[attachmentId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("Attachment not found with this id = %s", attachmentId))
+	}
+
+	return &attachment, nil
+}
+
+func (ar *AttachmentRepository) FindAttachmentsByAuctionIds(
+	ctx context.Context, auctionIds []string) ([]attachment_entity.Attachment, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	defer ar.mutex.RUnlock()
+
+	wanted := make(map[string]bool, len(auctionIds))
+	for _, auctionId := range auctionIds {
+		wanted[auctionId] = true
+	}
+
+	var attachments []attachment_entity.Attachment
+	for _, attachment := range ar.attachments {
+		if wanted[attachment.AuctionId] {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	sort.Slice(attachments, func(i, j int) bool {
+		if !attachments[i].Timestamp.Equal(attachments[j].Timestamp) {
+			return attachments[i].Timestamp.Before(attachments[j].Timestamp)
+		}
+		return attachments[i].Id < attachments[j].Id
+	})
+
+	return attachments, nil
+}
+
+func (ar *AttachmentRepository) DeleteAttachment(
+	ctx context.Context, attachmentId string) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	if _, ok := ar.attachments[attachmentId]; !ok {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Attachment not found with this id = %s", attachmentId))
+	}
+
+	delete(ar.attachments, attachmentId)
+	return nil
+}
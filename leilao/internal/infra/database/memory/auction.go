@@ -0,0 +1,507 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAuctionPageSize = 20
+	defaultSearchPageSize  = 20
+)
+
+// AuctionRepository keeps auctions in a map guarded by a single mutex, which
+// makes every update as atomic as its MongoDB counterpart. Times are kept with
+// second precision, like the Unix timestamps stored in MongoDB.
+type AuctionRepository struct {
+	Outbox          *OutboxRepository
+	auctions        map[string]auction_entity.Auction
+	mutex           *sync.RWMutex
+	auctionInterval time.Duration
+	statusListeners []func(auctionId string, status auction_entity.AuctionStatus)
+}
+
+func NewAuctionRepository(outbox *OutboxRepository, auctionInterval time.Duration) *AuctionRepository {
+	return &AuctionRepository{
+		Outbox:          outbox,
+		auctions:        make(map[string]auction_entity.Auction),
+		mutex:           &sync.RWMutex{},
+		auctionInterval: auctionInterval,
+	}
+}
+
+func (ar *AuctionRepository) CreateAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	if auctionEntity.EndTime.IsZero() {
+		auctionEntity.EndTime = auctionEntity.Timestamp.Add(ar.auctionInterval)
+	}
+
+	stored := *auctionEntity
+	stored.Timestamp = truncate(stored.Timestamp)
+	stored.EndTime = truncate(stored.EndTime)
+	stored.CurrentPrice = 0
+	stored.SecondPrice = 0
+	stored.HighestBidId = ""
+	stored.HighestBidderId = ""
+	stored.BidCount = 0
+
+	ar.mutex.Lock()
+	if _, ok := ar.auctions[stored.Id]; ok {
+		ar.mutex.Unlock()
+		return internal_error.NewConflictError("Error trying to insert auction")
+	}
+	ar.auctions[stored.Id] = stored
+	ar.mutex.Unlock()
+
+	ar.Outbox.Append(ctx, event_entity.AuctionCreated, auctionEntity.Id, event_entity.AuctionCreatedData{
+		AuctionId:   auctionEntity.Id,
+		SellerId:    auctionEntity.SellerId,
+		ProductName: auctionEntity.ProductName,
+		Category:    auctionEntity.Category,
+		Format:      string(auctionEntity.Format),
+		EndTime:     auctionEntity.EndTime,
+	})
+
+	ar.scheduleAuctionClose(stored.Id, stored.EndTime)
+
+	return nil
+}
+
+// scheduleAuctionClose waits for the auction end time and completes it. The end
+// time is re-read before closing because soft-close bids may have extended it.
+// The timer outlives the request that created the auction, so it does not use
+// its context.
+func (ar *AuctionRepository) scheduleAuctionClose(auctionId string, endTime time.Time) {
+	ctx := logger.WithAuctionId(context.Background(), auctionId)
+
+	go func() {
+		for {
+			<-time.After(time.Until(endTime))
+
+			auctionEntity, err := ar.FindAuctionById(ctx, auctionId)
+			if err != nil {
+				logger.ErrorContext(ctx, "Error trying to find auction to close", err)
+				return
+			}
+
+			if auctionEntity.Status != auction_entity.Active {
+				return
+			}
+
+			if auctionEntity.EndTime.After(time.Now()) {
+				endTime = auctionEntity.EndTime
+				continue
+			}
+
+			if ar.closeAuction(auctionId, time.Now()) {
+				logger.InfoContext(ctx, "Auction closed", zap.String("auction_id", auctionId))
+				ar.notifyStatusChange(auctionId, auction_entity.Completed)
+				return
+			}
+		}
+	}()
+}
+
+func (ar *AuctionRepository) closeAuction(auctionId string, now time.Time) bool {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	auctionEntity, ok := ar.auctions[auctionId]
+	if !ok || auctionEntity.Status != auction_entity.Active || auctionEntity.EndTime.Unix() > now.Unix() {
+		return false
+	}
+
+	auctionEntity.Status = auction_entity.Completed
+	ar.auctions[auctionId] = auctionEntity
+	return true
+}
+
+func (ar *AuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	ar.mutex.RLock()
+	defer ar.mutex.RUnlock()
+
+	auctionEntity, ok := ar.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", id))
+	}
+
+	return &auctionEntity, nil
+}
+
+func (ar *AuctionRepository) FindAuctions(
+	ctx context.Context,
+	auctionFilter auction_entity.AuctionFilter) (*auction_entity.AuctionPage, *internal_error.InternalError) {
+	var cursor *auctionCursor
+	if auctionFilter.Cursor != "" {
+		decoded, err := decodeAuctionCursor(auctionFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+		cursor = decoded
+	}
+
+	sortValue := auctionSortValue(auctionFilter.SortBy)
+	less := func(a, b auction_entity.Auction) bool {
+		if sortValue(a) != sortValue(b) {
+			return sortValue(a) < sortValue(b)
+		}
+		return a.Id < b.Id
+	}
+	if auctionFilter.SortDescending {
+		ascending := less
+		less = func(a, b auction_entity.Auction) bool { return ascending(b, a) }
+	}
+
+	ar.mutex.RLock()
+	var matches []auction_entity.Auction
+	for _, auctionEntity := range ar.auctions {
+		if matchesAuctionFilter(auctionEntity, auctionFilter) {
+			matches = append(matches, auctionEntity)
+		}
+	}
+	ar.mutex.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool { return less(matches[i], matches[j]) })
+
+	total := int64(len(matches))
+	if cursor != nil {
+		start := sort.Search(len(matches), func(i int) bool {
+			return cursor.follows(sortValue(matches[i]), matches[i].Id, auctionFilter.SortDescending)
+		})
+		matches = matches[start:]
+	}
+
+	limit := auctionFilter.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+
+	var nextCursor string
+	if len(matches) > limit {
+		matches = matches[:limit]
+		last := matches[limit-1]
+		nextCursor = encodeAuctionCursor(auctionCursor{Value: sortValue(last), Id: last.Id})
+	}
+
+	return &auction_entity.AuctionPage{
+		Auctions:   matches,
+		Total:      total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// SearchAuctions ranks auctions by how many query terms appear in their product
+// name, category and description, weighted like the MongoDB text index.
+func (ar *AuctionRepository) SearchAuctions(
+	ctx context.Context,
+	search auction_entity.AuctionSearch) (*auction_entity.AuctionSearchPage, *internal_error.InternalError) {
+	terms := strings.Fields(strings.ToLower(search.Query))
+
+	ar.mutex.RLock()
+	var matches []auction_entity.AuctionSearchResult
+	for _, auctionEntity := range ar.auctions {
+		if search.Status != nil && auctionEntity.Status != *search.Status {
+			continue
+		}
+
+		if score := searchScore(auctionEntity, terms); score > 0 {
+			matches = append(matches, auction_entity.AuctionSearchResult{Auction: auctionEntity, Score: score})
+		}
+	}
+	ar.mutex.RUnlock()
+
+	searchPage := &auction_entity.AuctionSearchPage{}
+
+	categoryCounts := make(map[string]int64)
+	var results []auction_entity.AuctionSearchResult
+	for _, match := range matches {
+		categoryCounts[match.Auction.Category]++
+		if search.Category == "" || match.Auction.Category == search.Category {
+			results = append(results, match)
+		}
+	}
+
+	for category, count := range categoryCounts {
+		searchPage.Facets = append(searchPage.Facets, auction_entity.CategoryFacet{Category: category, Count: count})
+	}
+	sort.Slice(searchPage.Facets, func(i, j int) bool {
+		if searchPage.Facets[i].Count != searchPage.Facets[j].Count {
+			return searchPage.Facets[i].Count > searchPage.Facets[j].Count
+		}
+		return searchPage.Facets[i].Category < searchPage.Facets[j].Category
+	})
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Auction.Id < results[j].Auction.Id
+	})
+
+	searchPage.Total = int64(len(results))
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = defaultSearchPageSize
+	}
+
+	if search.Offset < len(results) {
+		results = results[search.Offset:]
+		if len(results) > limit {
+			results = results[:limit]
+		}
+		searchPage.Results = results
+	}
+
+	return searchPage, nil
+}
+
+func (ar *AuctionRepository) UpdateAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	stored, ok := ar.auctions[auctionEntity.Id]
+	if !ok || stored.Status != auction_entity.Active || stored.BidCount > 0 {
+		return internal_error.NewConflictError("Only active auctions without bids can be edited")
+	}
+
+	stored.ProductName = auctionEntity.ProductName
+	stored.Category = auctionEntity.Category
+	stored.Description = auctionEntity.Description
+	stored.Condition = auctionEntity.Condition
+	ar.auctions[auctionEntity.Id] = stored
+
+	return nil
+}
+
+func (ar *AuctionRepository) CancelAuction(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	ar.mutex.Lock()
+	stored, ok := ar.auctions[auctionId]
+	if !ok || stored.Status != auction_entity.Active {
+		ar.mutex.Unlock()
+		return internal_error.NewConflictError("Only active auctions can be cancelled")
+	}
+
+	stored.Status = auction_entity.Cancelled
+	ar.auctions[auctionId] = stored
+	ar.mutex.Unlock()
+
+	ar.notifyStatusChange(auctionId, auction_entity.Cancelled)
+
+	return nil
+}
+
+func (ar *AuctionRepository) ExtendAuctionEndTime(
+	ctx context.Context,
+	auctionId string, endTime time.Time) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	stored, ok := ar.auctions[auctionId]
+	if !ok || stored.Status != auction_entity.Active {
+		return nil
+	}
+
+	if endTime = truncate(endTime); endTime.After(stored.EndTime) {
+		stored.EndTime = endTime
+		ar.auctions[auctionId] = stored
+	}
+
+	return nil
+}
+
+// PlaceBid applies the bid to the auction with the rules of its format. It
+// returns the auction as it was before the bid, or nil when the bid was
+// rejected, and whether the bid closed the auction.
+func (ar *AuctionRepository) PlaceBid(
+	ctx context.Context,
+	auctionId, bidId, bidderId string,
+	amount float64, bidTime time.Time) (*auction_entity.Auction, bool, *internal_error.InternalError) {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	stored, ok := ar.auctions[auctionId]
+	if !ok {
+		return nil, false, nil
+	}
+
+	previous := stored
+	placement := stored.ApplyBid(bidId, bidderId, amount, bidTime)
+	if !placement.Accepted {
+		return nil, false, nil
+	}
+
+	stored.EndTime = truncate(stored.EndTime)
+	ar.auctions[auctionId] = stored
+
+	return &previous, placement.Closes, nil
+}
+
+// RevertHighestBid undoes the placement of bidId when the bid itself could
+// not be stored: the highest bid recorded before it is restored, or, if a
+// later bid already beat it, only the bid count is corrected.
+func (ar *AuctionRepository) RevertHighestBid(
+	ctx context.Context,
+	previous *auction_entity.Auction, bidId string) *internal_error.InternalError {
+	ar.mutex.Lock()
+	defer ar.mutex.Unlock()
+
+	stored, ok := ar.auctions[previous.Id]
+	if !ok {
+		return nil
+	}
+
+	if stored.HighestBidId == bidId {
+		stored.CurrentPrice = previous.CurrentPrice
+		stored.SecondPrice = previous.SecondPrice
+		stored.HighestBidId = previous.HighestBidId
+		stored.HighestBidderId = previous.HighestBidderId
+	}
+	stored.BidCount--
+	ar.auctions[previous.Id] = stored
+
+	return nil
+}
+
+// OnStatusChange registers a callback fired whenever this repository closes or
+// cancels an auction, so caches of auction state can follow along.
+func (ar *AuctionRepository) OnStatusChange(
+	listener func(auctionId string, status auction_entity.AuctionStatus)) {
+	ar.statusListeners = append(ar.statusListeners, listener)
+}
+
+func (ar *AuctionRepository) notifyStatusChange(
+	auctionId string, status auction_entity.AuctionStatus) {
+	for _, listener := range ar.statusListeners {
+		listener(auctionId, status)
+	}
+}
+
+func matchesAuctionFilter(auctionEntity auction_entity.Auction, auctionFilter auction_entity.AuctionFilter) bool {
+	if auctionFilter.Status != nil && auctionEntity.Status != *auctionFilter.Status {
+		return false
+	}
+
+	if auctionFilter.Category != "" && auctionEntity.Category != auctionFilter.Category {
+		return false
+	}
+
+	if auctionFilter.ProductName != "" &&
+		!strings.Contains(strings.ToLower(auctionEntity.ProductName), strings.ToLower(auctionFilter.ProductName)) {
+		return false
+	}
+
+	if auctionFilter.Condition != nil && auctionEntity.Condition != *auctionFilter.Condition {
+		return false
+	}
+
+	if auctionFilter.MinPrice != nil && auctionEntity.CurrentPrice < *auctionFilter.MinPrice {
+		return false
+	}
+
+	if auctionFilter.MaxPrice != nil && auctionEntity.CurrentPrice > *auctionFilter.MaxPrice {
+		return false
+	}
+
+	if auctionFilter.UsesPrice() && auctionEntity.BidsSealed() {
+		return false
+	}
+
+	if auctionFilter.EndingBefore != nil && auctionEntity.EndTime.Unix() > auctionFilter.EndingBefore.Unix() {
+		return false
+	}
+
+	return true
+}
+
+func auctionSortValue(sortBy auction_entity.AuctionSortField) func(auction_entity.Auction) float64 {
+	switch sortBy {
+	case auction_entity.SortByEndTime:
+		return func(auctionEntity auction_entity.Auction) float64 { return float64(auctionEntity.EndTime.Unix()) }
+	case auction_entity.SortByCurrentPrice:
+		return func(auctionEntity auction_entity.Auction) float64 { return auctionEntity.CurrentPrice }
+	default:
+		return func(auctionEntity auction_entity.Auction) float64 { return float64(auctionEntity.Timestamp.Unix()) }
+	}
+}
+
+func searchScore(auctionEntity auction_entity.Auction, terms []string) float64 {
+	fields := []struct {
+		value  string
+		weight float64
+	}{
+		{strings.ToLower(auctionEntity.ProductName), 10},
+		{strings.ToLower(auctionEntity.Category), 5},
+		{strings.ToLower(auctionEntity.Description), 1},
+	}
+
+	var score float64
+	for _, term := range terms {
+		for _, field := range fields {
+			if strings.Contains(field.value, term) {
+				score += field.weight
+			}
+		}
+	}
+	return score
+}
+
+// auctionCursor points at the last auction of a page by its sort value and id.
+type auctionCursor struct {
+	Value float64 `json:"v"`
+	Id    string  `json:"id"`
+}
+
+// follows tells whether an auction with the given sort value and id comes after
+// the cursor in the page order.
+func (c *auctionCursor) follows(value float64, id string, descending bool) bool {
+	if value != c.Value {
+		return (value > c.Value) != descending
+	}
+	return (id > c.Id) != descending && id != c.Id
+}
+
+func encodeAuctionCursor(cursor auctionCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAuctionCursor(value string) (*auctionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor auctionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.Id == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
+}
+
+func truncate(value time.Time) time.Time {
+	return time.Unix(value.Unix(), 0)
+}
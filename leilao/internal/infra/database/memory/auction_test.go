@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+	"testing"
+	"time"
+)
+
+func TestFindAuctionsByPriceLeavesOutActiveSealedAuctions(t *testing.T) {
+	repositorytest.FindAuctionsByPriceLeavesOutActiveSealedAuctions(t,
+		func(t *testing.T, auctions []auction_entity.Auction) auction_entity.AuctionRepositoryInterface {
+			auctionRepository := NewAuctionRepository(NewOutboxRepository(), time.Minute)
+			for _, auctionEntity := range auctions {
+				auctionRepository.auctions[auctionEntity.Id] = auctionEntity
+			}
+			return auctionRepository
+		})
+}
@@ -0,0 +1,319 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type BidRepository struct {
+	AuctionRepository *AuctionRepository
+	bids              map[string]bid_entity.Bid
+	auctionBidIds     map[string][]string
+	mutex             *sync.RWMutex
+	auctionInterval   time.Duration
+	softClosePolicy   auction_entity.SoftClosePolicy
+}
+
+func NewBidRepository(
+	auctionRepository *AuctionRepository,
+	auctionInterval time.Duration,
+	softClosePolicy auction_entity.SoftClosePolicy) *BidRepository {
+	return &BidRepository{
+		AuctionRepository: auctionRepository,
+		bids:              make(map[string]bid_entity.Bid),
+		auctionBidIds:     make(map[string][]string),
+		mutex:             &sync.RWMutex{},
+		auctionInterval:   auctionInterval,
+		softClosePolicy:   softClosePolicy,
+	}
+}
+
+// CreateBid places and stores the bids of the batch one at a time in timestamp
+// order, so each one has to beat the highest bid recorded before it. Storing a
+// bid that already exists is a no-op, which keeps replays idempotent.
+func (bd *BidRepository) CreateBid(
+	ctx context.Context,
+	bidEntities []bid_entity.Bid) ([]bid_entity.BidFailure, *internal_error.InternalError) {
+	order := make([]int, len(bidEntities))
+	for index := range order {
+		order[index] = index
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return bidEntities[order[i]].Timestamp.Before(bidEntities[order[j]].Timestamp)
+	})
+
+	var failures []bid_entity.BidFailure
+	for _, index := range order {
+		if reason := bd.createBid(ctx, bidEntities[index]); reason != "" {
+			failures = append(failures, bid_entity.BidFailure{
+				Index: index, BidId: bidEntities[index].Id, Reason: reason})
+		}
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Index < failures[j].Index
+	})
+
+	return failures, nil
+}
+
+func (bd *BidRepository) createBid(ctx context.Context, bidValue bid_entity.Bid) bid_entity.BidFailureReason {
+	bd.mutex.RLock()
+	_, stored := bd.bids[bidValue.Id]
+	bd.mutex.RUnlock()
+
+	if stored {
+		return ""
+	}
+
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
+	if err != nil {
+		if err.Code == internal_error.NotFound {
+			return bid_entity.AuctionNotFound
+		}
+		return bid_entity.AuctionUnavailable
+	}
+
+	if auctionEntity.Status != auction_entity.Active || bidValue.Timestamp.After(auctionEntity.EndTime) {
+		return bid_entity.AuctionClosed
+	}
+
+	previous, closes, err := bd.AuctionRepository.PlaceBid(
+		ctx, bidValue.AuctionId, bidValue.Id, bidValue.UserId, bidValue.Amount, bidValue.Timestamp)
+	if err != nil {
+		return bid_entity.AuctionUnavailable
+	}
+
+	if previous == nil {
+		logger.InfoContext(ctx, "Bid rejected by the rules of the auction format or the auction is closed",
+			zap.String("auction_id", bidValue.AuctionId),
+			zap.String("bid_id", bidValue.Id))
+		return bid_entity.BidRejected
+	}
+
+	bidValue.Timestamp = truncate(bidValue.Timestamp)
+
+	bd.mutex.Lock()
+	bd.bids[bidValue.Id] = bidValue
+	bd.auctionBidIds[bidValue.AuctionId] = append(bd.auctionBidIds[bidValue.AuctionId], bidValue.Id)
+	bd.mutex.Unlock()
+
+	bd.AuctionRepository.Outbox.Append(ctx, event_entity.BidAccepted, bidValue.AuctionId, event_entity.BidAcceptedData{
+		BidId:     bidValue.Id,
+		AuctionId: bidValue.AuctionId,
+		UserId:    bidValue.UserId,
+		Amount:    bidValue.Amount,
+		Automatic: bidValue.Automatic,
+		Timestamp: bidValue.Timestamp,
+	})
+
+	if closes {
+		logger.InfoContext(ctx, "Auction closed by bid",
+			zap.String("auction_id", bidValue.AuctionId),
+			zap.String("bid_id", bidValue.Id))
+		bd.AuctionRepository.notifyStatusChange(bidValue.AuctionId, auction_entity.Completed)
+		return ""
+	}
+
+	if previous.Format == auction_entity.English &&
+		bd.softClosePolicy.InWindow(previous.EndTime, bidValue.Timestamp) {
+		bd.extendAuctionEndTime(ctx, bidValue)
+	}
+
+	return ""
+}
+
+// extendAuctionEndTime applies the soft-close policy to the auction of a bid
+// placed in its final window.
+func (bd *BidRepository) extendAuctionEndTime(ctx context.Context, bidValue bid_entity.Bid) {
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find auction by id", err)
+		return
+	}
+
+	nextEndTime, ok := bd.softClosePolicy.NextEndTime(
+		auctionEntity.Timestamp.Add(bd.auctionInterval), auctionEntity.EndTime, bidValue.Timestamp)
+	if !ok {
+		return
+	}
+
+	bd.AuctionRepository.ExtendAuctionEndTime(ctx, bidValue.AuctionId, nextEndTime)
+
+	logger.InfoContext(ctx, "Auction end time extended",
+		zap.String("auction_id", bidValue.AuctionId),
+		zap.Time("end_time", nextEndTime))
+}
+
+func (bd *BidRepository) FindBidByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	bd.mutex.RLock()
+	defer bd.mutex.RUnlock()
+
+	var bidEntities []bid_entity.Bid
+	for _, bidId := range bd.auctionBidIds[auctionId] {
+		bidEntities = append(bidEntities, bd.bids[bidId])
+	}
+
+	return bidEntities, nil
+}
+
+// FindBids pages through the matching bids ordered by timestamp and id.
+func (bd *BidRepository) FindBids(
+	ctx context.Context, bidFilter bid_entity.BidFilter) (*bid_entity.BidPage, *internal_error.InternalError) {
+	var cursor *bidCursor
+	if bidFilter.Cursor != "" {
+		decoded, err := decodeBidCursor(bidFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+		cursor = decoded
+	}
+
+	bd.mutex.RLock()
+	var matches []bid_entity.Bid
+	for _, bidEntity := range bd.bids {
+		if (bidFilter.AuctionId == "" || bidEntity.AuctionId == bidFilter.AuctionId) &&
+			(bidFilter.UserId == "" || bidEntity.UserId == bidFilter.UserId) {
+			matches = append(matches, bidEntity)
+		}
+	}
+	bd.mutex.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Timestamp.Unix() != matches[j].Timestamp.Unix() {
+			return (matches[i].Timestamp.Unix() < matches[j].Timestamp.Unix()) != bidFilter.SortDescending
+		}
+		return (matches[i].Id < matches[j].Id) != bidFilter.SortDescending
+	})
+
+	stats := bidStats(matches)
+	if cursor != nil {
+		start := sort.Search(len(matches), func(i int) bool {
+			return cursor.follows(matches[i].Timestamp.Unix(), matches[i].Id, bidFilter.SortDescending)
+		})
+		matches = matches[start:]
+	}
+
+	limit := bidFilter.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+
+	var nextCursor string
+	if len(matches) > limit {
+		matches = matches[:limit]
+		last := matches[limit-1]
+		nextCursor = encodeBidCursor(bidCursor{Timestamp: last.Timestamp.Unix(), Id: last.Id})
+	}
+
+	return &bid_entity.BidPage{
+		Bids:       matches,
+		Stats:      stats,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func bidStats(bids []bid_entity.Bid) bid_entity.BidStats {
+	var stats bid_entity.BidStats
+	if len(bids) == 0 {
+		return stats
+	}
+
+	bidders := make(map[string]bool)
+	auctions := make(map[string]bool)
+	var total float64
+	for index, bid := range bids {
+		bidders[bid.UserId] = true
+		auctions[bid.AuctionId] = true
+		total += bid.Amount
+
+		if index == 0 || bid.Amount > stats.HighestAmount {
+			stats.HighestAmount = bid.Amount
+		}
+		if index == 0 || bid.Timestamp.Before(stats.FirstBidAt) {
+			stats.FirstBidAt = bid.Timestamp
+		}
+		if index == 0 || bid.Timestamp.After(stats.LastBidAt) {
+			stats.LastBidAt = bid.Timestamp
+		}
+	}
+
+	stats.Count = int64(len(bids))
+	stats.Bidders = int64(len(bidders))
+	stats.Auctions = int64(len(auctions))
+	stats.AverageAmount = total / float64(len(bids))
+
+	return stats
+}
+
+// FindWinningBidByAuctionId reads the highest bid tracked on the auction.
+func (bd *BidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	auctionEntity, err := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	bd.mutex.RLock()
+	defer bd.mutex.RUnlock()
+
+	bidEntity, ok := bd.bids[auctionEntity.HighestBidId]
+	if auctionEntity.HighestBidId == "" || !ok {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
+
+	return &bidEntity, nil
+}
+
+// bidCursor points at the last bid of a page by its timestamp and id.
+type bidCursor struct {
+	Timestamp int64  `json:"t"`
+	Id        string `json:"id"`
+}
+
+// follows tells whether a bid with the given timestamp and id comes after the
+// cursor in the page order.
+func (c *bidCursor) follows(timestamp int64, id string, descending bool) bool {
+	if timestamp != c.Timestamp {
+		return (timestamp > c.Timestamp) != descending
+	}
+	return (id > c.Id) != descending && id != c.Id
+}
+
+func encodeBidCursor(cursor bidCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeBidCursor(value string) (*bidCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor bidCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.Id == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
+}
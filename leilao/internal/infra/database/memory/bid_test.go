@@ -0,0 +1,20 @@
+package memory
+
+import (
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+	"testing"
+	"time"
+)
+
+func TestFindBidsPagesWithoutOverlapOrGap(t *testing.T) {
+	repositorytest.FindBidsPagesWithoutOverlapOrGap(t,
+		func(t *testing.T, bids []bid_entity.Bid) bid_entity.BidEntityRepository {
+			bidRepository := NewBidRepository(nil, time.Minute, auction_entity.SoftClosePolicy{})
+			for _, bid := range bids {
+				bidRepository.bids[bid.Id] = bid
+			}
+			return bidRepository
+		})
+}
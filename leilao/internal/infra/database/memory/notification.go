@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/watchlist_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+)
+
+type WatchlistRepository struct {
+	items map[string]watchlist_entity.WatchlistItem
+	mutex *sync.RWMutex
+}
+
+func NewWatchlistRepository() *WatchlistRepository {
+	return &WatchlistRepository{
+		items: make(map[string]watchlist_entity.WatchlistItem),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+func (wr *WatchlistRepository) AddToWatchlist(
+	ctx context.Context, item *watchlist_entity.WatchlistItem) *internal_error.InternalError {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+
+	key := watchlistKey(item.UserId, item.AuctionId)
+	if _, ok := wr.items[key]; !ok {
+		stored := *item
+		stored.Timestamp = truncate(stored.Timestamp)
+		wr.items[key] = stored
+	}
+
+	return nil
+}
+
+func (wr *WatchlistRepository) RemoveFromWatchlist(
+	ctx context.Context, userId, auctionId string) *internal_error.InternalError {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+
+	key := watchlistKey(userId, auctionId)
+	if _, ok := wr.items[key]; !ok {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Auction %s is not in the watchlist", auctionId))
+	}
+
+	delete(wr.items, key)
+	return nil
+}
+
+func (wr *WatchlistRepository) FindWatchlistByUserId(
+	ctx context.Context, userId string) ([]watchlist_entity.WatchlistItem, *internal_error.InternalError) {
+	wr.mutex.RLock()
+	defer wr.mutex.RUnlock()
+
+	var items []watchlist_entity.WatchlistItem
+	for _, item := range wr.items {
+		if item.UserId == userId {
+			items = append(items, item)
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Timestamp.Equal(items[j].Timestamp) {
+			return items[i].Timestamp.After(items[j].Timestamp)
+		}
+		return items[i].AuctionId < items[j].AuctionId
+	})
+
+	return items, nil
+}
+
+func (wr *WatchlistRepository) FindWatchersByAuctionId(
+	ctx context.Context, auctionId string) ([]string, *internal_error.InternalError) {
+	wr.mutex.RLock()
+	defer wr.mutex.RUnlock()
+
+	var userIds []string
+	for _, item := range wr.items {
+		if item.AuctionId == auctionId {
+			userIds = append(userIds, item.UserId)
+		}
+	}
+
+	sort.Strings(userIds)
+	return userIds, nil
+}
+
+func watchlistKey(userId, auctionId string) string {
+	return userId + ":" + auctionId
+}
+
+type NotificationRepository struct {
+	notifications map[string]notification_entity.Notification
+	mutex         *sync.RWMutex
+}
+
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{
+		notifications: make(map[string]notification_entity.Notification),
+		mutex:         &sync.RWMutex{},
+	}
+}
+
+func (nr *NotificationRepository) CreateNotification(
+	ctx context.Context, notification *notification_entity.Notification) (bool, *internal_error.InternalError) {
+	nr.mutex.Lock()
+	defer nr.mutex.Unlock()
+
+	if _, ok := nr.notifications[notification.Id]; ok {
+		return false, nil
+	}
+
+	stored := *notification
+	stored.Timestamp = truncate(stored.Timestamp)
+	nr.notifications[notification.Id] = stored
+
+	return true, nil
+}
+
+func (nr *NotificationRepository) FindNotificationsByUserId(
+	ctx context.Context,
+	userId string, limit int64) ([]notification_entity.Notification, *internal_error.InternalError) {
+	nr.mutex.RLock()
+	defer nr.mutex.RUnlock()
+
+	var notifications []notification_entity.Notification
+	for _, notification := range nr.notifications {
+		if notification.UserId == userId {
+			notifications = append(notifications, notification)
+		}
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].Timestamp.Equal(notifications[j].Timestamp) {
+			return notifications[i].Timestamp.After(notifications[j].Timestamp)
+		}
+		return notifications[i].Id > notifications[j].Id
+	})
+
+	if int64(len(notifications)) > limit {
+		notifications = notifications[:limit]
+	}
+
+	return notifications, nil
+}
@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+)
+
+// OutboxRepository keeps only the unpublished events: an event is dropped as
+// soon as it is marked published.
+type OutboxRepository struct {
+	events map[string]event_entity.Event
+	mutex  *sync.Mutex
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{
+		events: make(map[string]event_entity.Event),
+		mutex:  &sync.Mutex{},
+	}
+}
+
+func (or *OutboxRepository) AppendEvent(
+	ctx context.Context, event *event_entity.Event) *internal_error.InternalError {
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+
+	if _, ok := or.events[event.Id]; ok {
+		return internal_error.NewConflictError("Error trying to insert outbox event")
+	}
+
+	or.events[event.Id] = *event
+	return nil
+}
+
+// Append builds the event and stores it, logging failures. It is meant to be
+// called right after the state change the event describes.
+func (or *OutboxRepository) Append(
+	ctx context.Context, eventType event_entity.EventType, aggregateId string, data interface{}) {
+	event, err := event_entity.CreateEvent(eventType, aggregateId, data)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to create outbox event", err)
+		return
+	}
+
+	or.AppendEvent(ctx, event)
+}
+
+func (or *OutboxRepository) FindUnpublishedEvents(
+	ctx context.Context, limit int64) ([]event_entity.Event, *internal_error.InternalError) {
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+
+	var eventEntities []event_entity.Event
+	for _, event := range or.events {
+		eventEntities = append(eventEntities, event)
+	}
+
+	sort.Slice(eventEntities, func(i, j int) bool {
+		return eventEntities[i].Timestamp.Before(eventEntities[j].Timestamp)
+	})
+
+	if limit > 0 && int64(len(eventEntities)) > limit {
+		eventEntities = eventEntities[:limit]
+	}
+
+	return eventEntities, nil
+}
+
+func (or *OutboxRepository) MarkEventPublished(
+	ctx context.Context, eventId string) *internal_error.InternalError {
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+
+	delete(or.events, eventId)
+
+	return nil
+}
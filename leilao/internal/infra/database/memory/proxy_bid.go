@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+)
+
+type ProxyBidRepository struct {
+	proxyBids map[string][]proxy_bid_entity.ProxyBid
+	mutex     *sync.RWMutex
+}
+
+func NewProxyBidRepository() *ProxyBidRepository {
+	return &ProxyBidRepository{
+		proxyBids: make(map[string][]proxy_bid_entity.ProxyBid),
+		mutex:     &sync.RWMutex{},
+	}
+}
+
+// CreateProxyBid keeps a single proxy per user and auction: a new maximum
+// replaces the previous one but the original registration time is kept.
+func (pr *ProxyBidRepository) CreateProxyBid(
+	ctx context.Context,
+	proxyBidEntity *proxy_bid_entity.ProxyBid) *internal_error.InternalError {
+	pr.mutex.Lock()
+	defer pr.mutex.Unlock()
+
+	auctionProxyBids := pr.proxyBids[proxyBidEntity.AuctionId]
+	for i := range auctionProxyBids {
+		if auctionProxyBids[i].UserId == proxyBidEntity.UserId {
+			auctionProxyBids[i].MaxAmount = proxyBidEntity.MaxAmount
+			return nil
+		}
+	}
+
+	stored := *proxyBidEntity
+	stored.Timestamp = truncate(stored.Timestamp)
+	pr.proxyBids[proxyBidEntity.AuctionId] = append(auctionProxyBids, stored)
+
+	return nil
+}
+
+func (pr *ProxyBidRepository) FindProxyBidsByAuctionId(
+	ctx context.Context, auctionId string) ([]proxy_bid_entity.ProxyBid, *internal_error.InternalError) {
+	pr.mutex.RLock()
+	defer pr.mutex.RUnlock()
+
+	return append([]proxy_bid_entity.ProxyBid(nil), pr.proxyBids[auctionId]...), nil
+}
@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+)
+
+type SettlementRepository struct {
+	Outbox      *OutboxRepository
+	settlements map[string]settlement_entity.Settlement
+	mutex       *sync.RWMutex
+}
+
+func NewSettlementRepository(outbox *OutboxRepository) *SettlementRepository {
+	return &SettlementRepository{
+		Outbox:      outbox,
+		settlements: make(map[string]settlement_entity.Settlement),
+		mutex:       &sync.RWMutex{},
+	}
+}
+
+// CreateSettlement is keyed by the auction id and never overwrites an existing
+// settlement.
+func (sr *SettlementRepository) CreateSettlement(
+	ctx context.Context,
+	settlementEntity *settlement_entity.Settlement) (bool, *internal_error.InternalError) {
+	sr.mutex.Lock()
+	if _, ok := sr.settlements[settlementEntity.AuctionId]; ok {
+		sr.mutex.Unlock()
+		return false, nil
+	}
+
+	stored := *settlementEntity
+	stored.ClosedAt = truncate(stored.ClosedAt)
+	stored.SettledAt = truncate(stored.SettledAt)
+	sr.settlements[settlementEntity.AuctionId] = stored
+	sr.mutex.Unlock()
+
+	sr.Outbox.Append(ctx, event_entity.AuctionClosed, settlementEntity.AuctionId, settlementEntity.ClosedEvent())
+
+	return true, nil
+}
+
+func (sr *SettlementRepository) FindSettlementByAuctionId(
+	ctx context.Context, auctionId string) (*settlement_entity.Settlement, *internal_error.InternalError) {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	settlementEntity, ok := sr.settlements[auctionId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("Settlement not found for auctionId %s", auctionId))
+	}
+
+	return &settlementEntity, nil
+}
+
+// FindSettlements pages through the matching settlements, latest closed first.
+func (sr *SettlementRepository) FindSettlements(
+	ctx context.Context,
+	settlementFilter settlement_entity.SettlementFilter) (*settlement_entity.SettlementPage, *internal_error.InternalError) {
+	var cursor *settlementCursor
+	if settlementFilter.Cursor != "" {
+		decoded, err := decodeSettlementCursor(settlementFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+		cursor = decoded
+	}
+
+	sr.mutex.RLock()
+	var matches []settlement_entity.Settlement
+	for _, settlementEntity := range sr.settlements {
+		if settlementFilter.WinnerId == "" || settlementEntity.WinnerId == settlementFilter.WinnerId {
+			matches = append(matches, settlementEntity)
+		}
+	}
+	sr.mutex.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].ClosedAt.Equal(matches[j].ClosedAt) {
+			return matches[i].ClosedAt.After(matches[j].ClosedAt)
+		}
+		return matches[i].AuctionId > matches[j].AuctionId
+	})
+
+	var stats settlement_entity.SettlementStats
+	for _, settlementEntity := range matches {
+		stats.Count++
+		stats.TotalPrice += settlementEntity.FinalPrice
+		if settlementEntity.FinalPrice > stats.HighestPrice {
+			stats.HighestPrice = settlementEntity.FinalPrice
+		}
+	}
+	if stats.Count > 0 {
+		stats.AveragePrice = stats.TotalPrice / float64(stats.Count)
+	}
+
+	if cursor != nil {
+		start := sort.Search(len(matches), func(i int) bool {
+			return cursor.follows(matches[i].ClosedAt.Unix(), matches[i].AuctionId)
+		})
+		matches = matches[start:]
+	}
+
+	limit := settlementFilter.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+
+	var nextCursor string
+	if len(matches) > limit {
+		matches = matches[:limit]
+		last := matches[limit-1]
+		nextCursor = encodeSettlementCursor(settlementCursor{ClosedAt: last.ClosedAt.Unix(), AuctionId: last.AuctionId})
+	}
+
+	return &settlement_entity.SettlementPage{
+		Settlements: matches,
+		Stats:       stats,
+		NextCursor:  nextCursor,
+	}, nil
+}
+
+// settlementCursor points at the last settlement of a page by its closing time
+// and auction id.
+type settlementCursor struct {
+	ClosedAt  int64  `json:"t"`
+	AuctionId string `json:"id"`
+}
+
+// follows tells whether a settlement with the given closing time and auction
+// id comes after the cursor, latest closed first.
+func (c *settlementCursor) follows(closedAt int64, auctionId string) bool {
+	if closedAt != c.ClosedAt {
+		return closedAt < c.ClosedAt
+	}
+	return auctionId < c.AuctionId
+}
+
+func encodeSettlementCursor(cursor settlementCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSettlementCursor(value string) (*settlementCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor settlementCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.AuctionId == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
+}
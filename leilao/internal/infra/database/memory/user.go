@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+)
+
+type UserRepository struct {
+	users map[string]user_entity.User
+	mutex *sync.RWMutex
+}
+
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users: make(map[string]user_entity.User),
+		mutex: &sync.RWMutex{},
+	}
+}
+
+func (ur *UserRepository) CreateUser(
+	ctx context.Context, userEntity *user_entity.User) *internal_error.InternalError {
+	ur.mutex.Lock()
+	defer ur.mutex.Unlock()
+
+	if err := ur.checkEmailAvailable(userEntity); err != nil {
+		return err
+	}
+
+	if _, ok := ur.users[userEntity.Id]; ok {
+		return internal_error.NewConflictError("Error trying to insert user")
+	}
+
+	stored := *userEntity
+	stored.Timestamp = truncate(stored.Timestamp)
+	ur.users[userEntity.Id] = stored
+
+	return nil
+}
+
+func (ur *UserRepository) UpdateUser(
+	ctx context.Context, userEntity *user_entity.User) *internal_error.InternalError {
+	ur.mutex.Lock()
+	defer ur.mutex.Unlock()
+
+	if err := ur.checkEmailAvailable(userEntity); err != nil {
+		return err
+	}
+
+	stored, ok := ur.users[userEntity.Id]
+	if !ok {
+		return internal_error.NewNotFoundError("User not found")
+	}
+
+	stored.Name = userEntity.Name
+	stored.Email = userEntity.Email
+	stored.Role = userEntity.Role
+	ur.users[userEntity.Id] = stored
+
+	return nil
+}
+
+func (ur *UserRepository) DeactivateUser(
+	ctx context.Context, userId string) *internal_error.InternalError {
+	ur.mutex.Lock()
+	defer ur.mutex.Unlock()
+
+	stored, ok := ur.users[userId]
+	if !ok {
+		return internal_error.NewNotFoundError("User not found")
+	}
+
+	stored.Active = false
+	ur.users[userId] = stored
+
+	return nil
+}
+
+func (ur *UserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	ur.mutex.RLock()
+	defer ur.mutex.RUnlock()
+
+	userEntity, ok := ur.users[userId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("User not found with this id = %s", userId))
+	}
+
+	return &userEntity, nil
+}
+
+func (ur *UserRepository) FindUserByEmail(
+	ctx context.Context, email string) (*user_entity.User, *internal_error.InternalError) {
+	ur.mutex.RLock()
+	defer ur.mutex.RUnlock()
+
+	for _, userEntity := range ur.users {
+		if userEntity.Email == email {
+			return &userEntity, nil
+		}
+	}
+
+	return nil, internal_error.NewNotFoundError("User not found with this email")
+}
+
+func (ur *UserRepository) FindUsers(
+	ctx context.Context, onlyActive bool) ([]user_entity.User, *internal_error.InternalError) {
+	ur.mutex.RLock()
+	defer ur.mutex.RUnlock()
+
+	var usersEntity []user_entity.User
+	for _, userEntity := range ur.users {
+		if onlyActive && !userEntity.Active {
+			continue
+		}
+		usersEntity = append(usersEntity, userEntity)
+	}
+
+	sort.Slice(usersEntity, func(i, j int) bool {
+		return usersEntity[i].Timestamp.Before(usersEntity[j].Timestamp)
+	})
+
+	return usersEntity, nil
+}
+
+func (ur *UserRepository) checkEmailAvailable(userEntity *user_entity.User) *internal_error.InternalError {
+	for _, stored := range ur.users {
+		if stored.Email == userEntity.Email && stored.Id != userEntity.Id {
+			return internal_error.NewConflictError("Email is already in use")
+		}
+	}
+
+	return nil
+}
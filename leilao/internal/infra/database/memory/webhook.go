@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"time"
+)
+
+type SubscriptionRepository struct {
+	subscriptions map[string]webhook_entity.Subscription
+	mutex         *sync.RWMutex
+}
+
+func NewSubscriptionRepository() *SubscriptionRepository {
+	return &SubscriptionRepository{
+		subscriptions: make(map[string]webhook_entity.Subscription),
+		mutex:         &sync.RWMutex{},
+	}
+}
+
+func (sr *SubscriptionRepository) CreateSubscription(
+	ctx context.Context, subscription *webhook_entity.Subscription) *internal_error.InternalError {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	if _, ok := sr.subscriptions[subscription.Id]; ok {
+		return internal_error.NewConflictError("Error trying to insert webhook subscription")
+	}
+
+	stored := *subscription
+	stored.Timestamp = truncate(stored.Timestamp)
+	sr.subscriptions[subscription.Id] = stored
+
+	return nil
+}
+
+func (sr *SubscriptionRepository) FindSubscriptions(
+	ctx context.Context) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	return sr.findSubscriptions(func(webhook_entity.Subscription) bool { return true }), nil
+}
+
+func (sr *SubscriptionRepository) FindSubscriptionsByEventType(
+	ctx context.Context,
+	eventType event_entity.EventType) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	return sr.findSubscriptions(func(subscription webhook_entity.Subscription) bool {
+		return subscription.Matches(eventType)
+	}), nil
+}
+
+func (sr *SubscriptionRepository) FindSubscriptionById(
+	ctx context.Context, subscriptionId string) (*webhook_entity.Subscription, *internal_error.InternalError) {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	subscription, ok := sr.subscriptions[subscriptionId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("Webhook not found with this id = %s", subscriptionId))
+	}
+
+	return &subscription, nil
+}
+
+func (sr *SubscriptionRepository) DeleteSubscription(
+	ctx context.Context, subscriptionId string) *internal_error.InternalError {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	if _, ok := sr.subscriptions[subscriptionId]; !ok {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Webhook not found with this id = %s", subscriptionId))
+	}
+
+	delete(sr.subscriptions, subscriptionId)
+	return nil
+}
+
+func (sr *SubscriptionRepository) findSubscriptions(
+	matches func(webhook_entity.Subscription) bool) []webhook_entity.Subscription {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	var subscriptions []webhook_entity.Subscription
+	for _, subscription := range sr.subscriptions {
+		if matches(subscription) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].Timestamp.Before(subscriptions[j].Timestamp)
+	})
+
+	return subscriptions
+}
+
+type DeliveryRepository struct {
+	deliveries map[string]webhook_entity.Delivery
+	mutex      *sync.Mutex
+}
+
+func NewDeliveryRepository() *DeliveryRepository {
+	return &DeliveryRepository{
+		deliveries: make(map[string]webhook_entity.Delivery),
+		mutex:      &sync.Mutex{},
+	}
+}
+
+func (dr *DeliveryRepository) CreateDelivery(
+	ctx context.Context, delivery *webhook_entity.Delivery) *internal_error.InternalError {
+	dr.mutex.Lock()
+	defer dr.mutex.Unlock()
+
+	if _, ok := dr.deliveries[delivery.Id]; !ok {
+		dr.deliveries[delivery.Id] = *delivery
+	}
+
+	return nil
+}
+
+func (dr *DeliveryRepository) ClaimDueDelivery(
+	ctx context.Context,
+	now, leaseUntil time.Time) (*webhook_entity.Delivery, *internal_error.InternalError) {
+	dr.mutex.Lock()
+	defer dr.mutex.Unlock()
+
+	var claimed *webhook_entity.Delivery
+	for _, delivery := range dr.deliveries {
+		if delivery.Status != webhook_entity.Pending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		if claimed == nil || delivery.NextAttemptAt.Before(claimed.NextAttemptAt) {
+			delivery := delivery
+			claimed = &delivery
+		}
+	}
+
+	if claimed == nil {
+		return nil, nil
+	}
+
+	claimed.NextAttemptAt = leaseUntil
+	dr.deliveries[claimed.Id] = *claimed
+
+	return claimed, nil
+}
+
+func (dr *DeliveryRepository) UpdateDelivery(
+	ctx context.Context, delivery *webhook_entity.Delivery) *internal_error.InternalError {
+	dr.mutex.Lock()
+	defer dr.mutex.Unlock()
+
+	if _, ok := dr.deliveries[delivery.Id]; ok {
+		dr.deliveries[delivery.Id] = *delivery
+	}
+
+	return nil
+}
+
+func (dr *DeliveryRepository) FindDeliveriesByStatus(
+	ctx context.Context,
+	status webhook_entity.DeliveryStatus, limit int64) ([]webhook_entity.Delivery, *internal_error.InternalError) {
+	dr.mutex.Lock()
+	defer dr.mutex.Unlock()
+
+	var deliveries []webhook_entity.Delivery
+	for _, delivery := range dr.deliveries {
+		if delivery.Status == status {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].Timestamp.After(deliveries[j].Timestamp)
+	})
+
+	if limit > 0 && int64(len(deliveries)) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// RetryDeadDelivery moves a dead-lettered delivery back to the queue with a
+// fresh attempt budget.
+func (dr *DeliveryRepository) RetryDeadDelivery(
+	ctx context.Context, deliveryId string) *internal_error.InternalError {
+	dr.mutex.Lock()
+	defer dr.mutex.Unlock()
+
+	delivery, ok := dr.deliveries[deliveryId]
+	if !ok || delivery.Status != webhook_entity.Dead {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Dead-lettered delivery not found with this id = %s", deliveryId))
+	}
+
+	delivery.Status = webhook_entity.Pending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	dr.deliveries[deliveryId] = delivery
+
+	return nil
+}
@@ -0,0 +1,334 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Migrations lists every schema change in version order. Never edit or
+// renumber an applied migration; add a new version instead. auctionInterval
+// gives the end time of the auctions stored before it was recorded.
+func Migrations(auctionInterval time.Duration) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create bids index by auction_id and amount",
+			Up: createIndexes("bids", mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "auction_id", Value: 1},
+					{Key: "amount", Value: -1},
+					{Key: "timestamp", Value: 1},
+				},
+				Options: options.Index().SetName("bids_auction_id_amount"),
+			}),
+		},
+		{
+			Version:     2,
+			Description: "create auctions indexes by status, category and end time",
+			Up: createIndexes("auctions",
+				mongo.IndexModel{
+					Keys: bson.D{
+						{Key: "status", Value: 1},
+						{Key: "category", Value: 1},
+					},
+					Options: options.Index().SetName("auctions_status_category"),
+				},
+				mongo.IndexModel{
+					Keys: bson.D{
+						{Key: "status", Value: 1},
+						{Key: "end_time", Value: 1},
+					},
+					Options: options.Index().SetName("auctions_status_end_time"),
+				}),
+		},
+		{
+			Version:     3,
+			Description: "create unique proxy bid index by auction_id and user_id",
+			Up: createIndexes("proxy_bids", mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "auction_id", Value: 1},
+					{Key: "user_id", Value: 1},
+				},
+				Options: options.Index().SetName("proxy_bids_auction_id_user_id").SetUnique(true),
+			}),
+		},
+		{
+			Version:     4,
+			Description: "create unique users index by email",
+			Up: createIndexes("users", mongo.IndexModel{
+				Keys: bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().
+					SetName("users_email").
+					SetUnique(true).
+					SetPartialFilterExpression(bson.M{"email": bson.M{"$type": "string"}}),
+			}),
+		},
+		{
+			Version:     5,
+			Description: "backfill auction end_time and current_price",
+			Up:          backfillAuctionEndTimeAndPrice(auctionInterval),
+		},
+		{
+			Version:     6,
+			Description: "backfill auction highest bid and bid count",
+			Up:          backfillAuctionHighestBid,
+		},
+		{
+			Version:     7,
+			Description: "create outbox index by published and timestamp",
+			Up: createIndexes("outbox", mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "published", Value: 1},
+					{Key: "timestamp", Value: 1},
+				},
+				Options: options.Index().SetName("outbox_published_timestamp"),
+			}),
+		},
+		{
+			Version:     8,
+			Description: "create webhook subscriptions index by event type",
+			Up: createIndexes("webhook_subscriptions", mongo.IndexModel{
+				Keys:    bson.D{{Key: "event_types", Value: 1}},
+				Options: options.Index().SetName("webhook_subscriptions_event_types"),
+			}),
+		},
+		{
+			Version:     9,
+			Description: "create webhook deliveries indexes by status",
+			Up: createIndexes("webhook_deliveries",
+				mongo.IndexModel{
+					Keys: bson.D{
+						{Key: "status", Value: 1},
+						{Key: "next_attempt_at", Value: 1},
+					},
+					Options: options.Index().SetName("webhook_deliveries_status_next_attempt_at"),
+				},
+				mongo.IndexModel{
+					Keys: bson.D{
+						{Key: "status", Value: 1},
+						{Key: "timestamp", Value: -1},
+					},
+					Options: options.Index().SetName("webhook_deliveries_status_timestamp"),
+				}),
+		},
+		{
+			Version:     10,
+			Description: "create attachments index by auction_id and timestamp",
+			Up: createIndexes("attachments", mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "auction_id", Value: 1},
+					{Key: "timestamp", Value: 1},
+				},
+				Options: options.Index().SetName("attachments_auction_id_timestamp"),
+			}),
+		},
+		{
+			Version:     11,
+			Description: "create watchlists and notifications indexes by user and auction",
+			Up: func(ctx context.Context, database *mongo.Database) error {
+				if err := createIndexes("watchlists",
+					mongo.IndexModel{
+						Keys: bson.D{
+							{Key: "user_id", Value: 1},
+							{Key: "timestamp", Value: -1},
+						},
+						Options: options.Index().SetName("watchlists_user_id_timestamp"),
+					},
+					mongo.IndexModel{
+						Keys:    bson.D{{Key: "auction_id", Value: 1}},
+						Options: options.Index().SetName("watchlists_auction_id"),
+					})(ctx, database); err != nil {
+					return err
+				}
+
+				return createIndexes("notifications", mongo.IndexModel{
+					Keys: bson.D{
+						{Key: "user_id", Value: 1},
+						{Key: "timestamp", Value: -1},
+					},
+					Options: options.Index().SetName("notifications_user_id_timestamp"),
+				})(ctx, database)
+			},
+		},
+		{
+			Version:     12,
+			Description: "create bids indexes by auction and user timeline and settlements index by winner",
+			Up: func(ctx context.Context, database *mongo.Database) error {
+				if err := createIndexes("bids",
+					mongo.IndexModel{
+						Keys: bson.D{
+							{Key: "auction_id", Value: 1},
+							{Key: "timestamp", Value: 1},
+							{Key: "_id", Value: 1},
+						},
+						Options: options.Index().SetName("bids_auction_id_timestamp"),
+					},
+					mongo.IndexModel{
+						Keys: bson.D{
+							{Key: "user_id", Value: 1},
+							{Key: "timestamp", Value: 1},
+							{Key: "_id", Value: 1},
+						},
+						Options: options.Index().SetName("bids_user_id_timestamp"),
+					})(ctx, database); err != nil {
+					return err
+				}
+
+				return createIndexes("settlements", mongo.IndexModel{
+					Keys: bson.D{
+						{Key: "winner_id", Value: 1},
+						{Key: "closed_at", Value: -1},
+						{Key: "_id", Value: -1},
+					},
+					Options: options.Index().SetName("settlements_winner_id_closed_at"),
+				})(ctx, database)
+			},
+		},
+		{
+			Version:     13,
+			Description: "create pending outbox event indexes on auctions, bids and settlements",
+			Up: func(ctx context.Context, database *mongo.Database) error {
+				for _, collection := range []string{"auctions", "bids", "settlements"} {
+					if err := createIndexes(collection, mongo.IndexModel{
+						Keys: bson.D{{Key: "outbox._id", Value: 1}},
+						Options: options.Index().
+							SetName(collection + "_pending_outbox").
+							SetPartialFilterExpression(bson.M{"outbox._id": bson.M{"$exists": true}}),
+					})(ctx, database); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func createIndexes(collection string, models ...mongo.IndexModel) func(context.Context, *mongo.Database) error {
+	return func(ctx context.Context, database *mongo.Database) error {
+		_, err := database.Collection(collection).Indexes().CreateMany(ctx, models)
+		return err
+	}
+}
+
+// backfillAuctionEndTimeAndPrice fills the fields introduced with soft-close
+// and pagination on auctions stored before them, so range filters and cursor
+// sorting do not skip those documents.
+func backfillAuctionEndTimeAndPrice(
+	auctionInterval time.Duration) func(ctx context.Context, database *mongo.Database) error {
+	return func(ctx context.Context, database *mongo.Database) error {
+		auctions := database.Collection("auctions")
+
+		intervalSeconds := int64(auctionInterval / time.Second)
+		if _, err := auctions.UpdateMany(ctx,
+			bson.M{"end_time": bson.M{"$exists": false}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				"end_time": bson.M{"$add": bson.A{"$timestamp", intervalSeconds}},
+			}}}}); err != nil {
+			return err
+		}
+
+		cursor, err := database.Collection("bids").Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$group", Value: bson.M{
+				"_id":    "$auction_id",
+				"amount": bson.M{"$max": "$amount"},
+			}}},
+		})
+		if err != nil {
+			return err
+		}
+		defer cursor.Close(ctx)
+
+		var highestBids []struct {
+			AuctionId string  `bson:"_id"`
+			Amount    float64 `bson:"amount"`
+		}
+		if err := cursor.All(ctx, &highestBids); err != nil {
+			return err
+		}
+
+		var models []mongo.WriteModel
+		for _, highestBid := range highestBids {
+			models = append(models, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": highestBid.AuctionId}).
+				SetUpdate(bson.M{"$max": bson.M{"current_price": highestBid.Amount}}))
+		}
+
+		if len(models) > 0 {
+			if _, err := auctions.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+				return err
+			}
+		}
+
+		_, err = auctions.UpdateMany(ctx,
+			bson.M{"current_price": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"current_price": 0}})
+		return err
+	}
+}
+
+// backfillAuctionHighestBid records the winning bid and the bid count on
+// auctions stored before they were tracked on the auction document.
+func backfillAuctionHighestBid(ctx context.Context, database *mongo.Database) error {
+	auctions := database.Collection("auctions")
+
+	cursor, err := database.Collection("bids").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{
+			{Key: "amount", Value: -1},
+			{Key: "timestamp", Value: 1},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       "$auction_id",
+			"bid_id":    bson.M{"$first": "$_id"},
+			"bidder_id": bson.M{"$first": "$user_id"},
+			"amount":    bson.M{"$first": "$amount"},
+			"count":     bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var highestBids []struct {
+		AuctionId string  `bson:"_id"`
+		BidId     string  `bson:"bid_id"`
+		BidderId  string  `bson:"bidder_id"`
+		Amount    float64 `bson:"amount"`
+		Count     int64   `bson:"count"`
+	}
+	if err := cursor.All(ctx, &highestBids); err != nil {
+		return err
+	}
+
+	var models []mongo.WriteModel
+	for _, highestBid := range highestBids {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": highestBid.AuctionId, "bid_count": bson.M{"$exists": false}}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"current_price":     highestBid.Amount,
+				"highest_bid_id":    highestBid.BidId,
+				"highest_bidder_id": highestBid.BidderId,
+				"bid_count":         highestBid.Count,
+			}}))
+	}
+
+	if len(models) > 0 {
+		if _, err := auctions.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+
+	_, err = auctions.UpdateMany(ctx,
+		bson.M{"bid_count": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{
+			"highest_bid_id":    "",
+			"highest_bidder_id": "",
+			"bid_count":         0,
+		}})
+	return err
+}
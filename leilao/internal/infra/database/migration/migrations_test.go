@@ -0,0 +1,25 @@
+package migration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigrationsHaveUniqueIncreasingVersions(t *testing.T) {
+	migrations := Migrations(5 * time.Minute)
+	if len(migrations) == 0 {
+		t.Fatal("Expected at least one migration")
+	}
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("Expected migration %d to have version %d, got %d", i, i+1, migration.Version)
+		}
+		if migration.Description == "" {
+			t.Errorf("Expected migration %d to have a description", migration.Version)
+		}
+		if migration.Up == nil {
+			t.Errorf("Expected migration %d to have an Up function", migration.Version)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Migration is a versioned schema change. Up must be idempotent: replicas
+// starting together may both apply a version before either records it.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, database *mongo.Database) error
+}
+
+type MigrationStatus struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+type migrationRecordMongo struct {
+	Version     int    `bson:"_id"`
+	Description string `bson:"description"`
+	AppliedAt   int64  `bson:"applied_at"`
+}
+
+type Migrator struct {
+	database   *mongo.Database
+	collection *mongo.Collection
+	migrations []Migration
+}
+
+func NewMigrator(database *mongo.Database, auctionInterval time.Duration) *Migrator {
+	sorted := Migrations(auctionInterval)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		database:   database,
+		collection: database.Collection("migrations"),
+		migrations: sorted,
+	}
+}
+
+// Run applies every migration not yet recorded in the migrations collection,
+// in version order, stopping at the first failure.
+func (m *Migrator) Run(ctx context.Context) error {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		logger.InfoContext(ctx, "Applying migration",
+			zap.Int("version", migration.Version),
+			zap.String("description", migration.Description))
+
+		if err := migration.Up(ctx, m.database); err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Error trying to apply migration %d", migration.Version), err)
+			return fmt.Errorf("migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		record := migrationRecordMongo{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now().Unix(),
+		}
+		opts := options.Replace().SetUpsert(true)
+		if _, err := m.collection.ReplaceOne(
+			ctx, bson.M{"_id": migration.Version}, record, opts); err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Error trying to record migration %d", migration.Version), err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MigrationStatus
+	for _, migration := range m.migrations {
+		record, ok := applied[migration.Version]
+		status := MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Applied:     ok,
+		}
+		if ok {
+			status.AppliedAt = time.Unix(record.AppliedAt, 0)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context) (map[int]migrationRecordMongo, error) {
+	cursor, err := m.collection.Find(ctx, bson.M{})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find applied migrations", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []migrationRecordMongo
+	if err := cursor.All(ctx, &records); err != nil {
+		logger.ErrorContext(ctx, "Error trying to decode applied migrations", err)
+		return nil, err
+	}
+
+	applied := make(map[int]migrationRecordMongo, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
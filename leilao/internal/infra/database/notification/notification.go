@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationEntityMongo struct {
+	Id        string                               `bson:"_id"`
+	UserId    string                               `bson:"user_id"`
+	Type      notification_entity.NotificationType `bson:"type"`
+	AuctionId string                               `bson:"auction_id"`
+	Message   string                               `bson:"message"`
+	Amount    float64                              `bson:"amount"`
+	Timestamp int64                                `bson:"timestamp"`
+}
+
+type NotificationRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewNotificationRepository(database *mongo.Database) *NotificationRepository {
+	return &NotificationRepository{
+		Collection: database.Collection("notifications"),
+	}
+}
+
+// CreateNotification never overwrites a notification with the same id.
+func (nr *NotificationRepository) CreateNotification(
+	ctx context.Context, notification *notification_entity.Notification) (bool, *internal_error.InternalError) {
+	filter := bson.M{"_id": notification.Id}
+	update := bson.M{"$setOnInsert": bson.M{
+		"user_id":    notification.UserId,
+		"type":       notification.Type,
+		"auction_id": notification.AuctionId,
+		"message":    notification.Message,
+		"amount":     notification.Amount,
+		"timestamp":  notification.Timestamp.Unix(),
+	}}
+
+	result, err := nr.Collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert notification", err)
+		return false, mongodb.NewDatabaseError("Error trying to insert notification", err)
+	}
+
+	return result.UpsertedCount > 0, nil
+}
+
+func (nr *NotificationRepository) FindNotificationsByUserId(
+	ctx context.Context,
+	userId string, limit int64) ([]notification_entity.Notification, *internal_error.InternalError) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := nr.Collection.Find(ctx, bson.M{"user_id": userId}, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find notifications", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find notifications", err)
+	}
+	defer cursor.Close(ctx)
+
+	var notificationEntitiesMongo []NotificationEntityMongo
+	if err := cursor.All(ctx, &notificationEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find notifications", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find notifications", err)
+	}
+
+	var notifications []notification_entity.Notification
+	for _, notificationEntityMongo := range notificationEntitiesMongo {
+		notifications = append(notifications, notification_entity.Notification{
+			Id:        notificationEntityMongo.Id,
+			UserId:    notificationEntityMongo.UserId,
+			Type:      notificationEntityMongo.Type,
+			AuctionId: notificationEntityMongo.AuctionId,
+			Message:   notificationEntityMongo.Message,
+			Amount:    notificationEntityMongo.Amount,
+			Timestamp: time.Unix(notificationEntityMongo.Timestamp, 0),
+		})
+	}
+
+	return notifications, nil
+}
@@ -0,0 +1,127 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/watchlist_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WatchlistItemEntityMongo struct {
+	Id        string `bson:"_id"`
+	UserId    string `bson:"user_id"`
+	AuctionId string `bson:"auction_id"`
+	Timestamp int64  `bson:"timestamp"`
+}
+
+type WatchlistRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewWatchlistRepository(database *mongo.Database) *WatchlistRepository {
+	return &WatchlistRepository{
+		Collection: database.Collection("watchlists"),
+	}
+}
+
+// AddToWatchlist is keyed by the user and the auction, so adding an auction
+// twice keeps the first item.
+func (wr *WatchlistRepository) AddToWatchlist(
+	ctx context.Context, item *watchlist_entity.WatchlistItem) *internal_error.InternalError {
+	filter := bson.M{"_id": watchlistId(item.UserId, item.AuctionId)}
+	update := bson.M{"$setOnInsert": bson.M{
+		"user_id":    item.UserId,
+		"auction_id": item.AuctionId,
+		"timestamp":  item.Timestamp.Unix(),
+	}}
+
+	if _, err := wr.Collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		logger.ErrorContext(ctx, "Error trying to add auction to watchlist", err)
+		return mongodb.NewDatabaseError("Error trying to add auction to watchlist", err)
+	}
+
+	return nil
+}
+
+func (wr *WatchlistRepository) RemoveFromWatchlist(
+	ctx context.Context, userId, auctionId string) *internal_error.InternalError {
+	result, err := wr.Collection.DeleteOne(ctx, bson.M{"_id": watchlistId(userId, auctionId)})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to remove auction from watchlist", err)
+		return mongodb.NewDatabaseError("Error trying to remove auction from watchlist", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Auction %s is not in the watchlist", auctionId))
+	}
+
+	return nil
+}
+
+func (wr *WatchlistRepository) FindWatchlistByUserId(
+	ctx context.Context, userId string) ([]watchlist_entity.WatchlistItem, *internal_error.InternalError) {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "auction_id", Value: 1}})
+
+	var itemEntitiesMongo []WatchlistItemEntityMongo
+	if err := wr.find(ctx, bson.M{"user_id": userId}, opts, &itemEntitiesMongo); err != nil {
+		return nil, err
+	}
+
+	var items []watchlist_entity.WatchlistItem
+	for _, itemEntityMongo := range itemEntitiesMongo {
+		items = append(items, watchlist_entity.WatchlistItem{
+			UserId:    itemEntityMongo.UserId,
+			AuctionId: itemEntityMongo.AuctionId,
+			Timestamp: time.Unix(itemEntityMongo.Timestamp, 0),
+		})
+	}
+
+	return items, nil
+}
+
+func (wr *WatchlistRepository) FindWatchersByAuctionId(
+	ctx context.Context, auctionId string) ([]string, *internal_error.InternalError) {
+	opts := options.Find().SetSort(bson.D{{Key: "user_id", Value: 1}})
+
+	var itemEntitiesMongo []WatchlistItemEntityMongo
+	if err := wr.find(ctx, bson.M{"auction_id": auctionId}, opts, &itemEntitiesMongo); err != nil {
+		return nil, err
+	}
+
+	var userIds []string
+	for _, itemEntityMongo := range itemEntitiesMongo {
+		userIds = append(userIds, itemEntityMongo.UserId)
+	}
+
+	return userIds, nil
+}
+
+func (wr *WatchlistRepository) find(
+	ctx context.Context,
+	filter bson.M, opts *options.FindOptions, items *[]WatchlistItemEntityMongo) *internal_error.InternalError {
+	cursor, err := wr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find watchlist", err)
+		return mongodb.NewDatabaseError("Error trying to find watchlist", err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, items); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find watchlist", err)
+		return mongodb.NewDatabaseError("Error trying to find watchlist", err)
+	}
+
+	return nil
+}
+
+func watchlistId(userId, auctionId string) string {
+	return userId + ":" + auctionId
+}
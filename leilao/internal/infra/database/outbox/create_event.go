@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const duplicateKeyCode = 11000
+
+type EventEntityMongo struct {
+	Id          string                 `bson:"_id"`
+	Type        event_entity.EventType `bson:"type"`
+	AggregateId string                 `bson:"aggregate_id"`
+	Payload     string                 `bson:"payload"`
+	Timestamp   int64                  `bson:"timestamp"`
+	Published   bool                   `bson:"published"`
+}
+
+// pendingEventSources are the collections whose documents embed, in the
+// "outbox" field, the events of the write that stored them. A single document
+// write is atomic without transactions, so the state change and its events
+// are stored together or not at all; relayPendingEvents then moves the events
+// to the outbox collection.
+var pendingEventSources = []string{"auctions", "bids", "settlements"}
+
+type OutboxRepository struct {
+	Collection *mongo.Collection
+	sources    []*mongo.Collection
+}
+
+func NewOutboxRepository(database *mongo.Database) *OutboxRepository {
+	sources := make([]*mongo.Collection, 0, len(pendingEventSources))
+	for _, name := range pendingEventSources {
+		sources = append(sources, database.Collection(name))
+	}
+
+	return &OutboxRepository{
+		Collection: database.Collection("outbox"),
+		sources:    sources,
+	}
+}
+
+// NewPendingEvent builds the event to embed in the "outbox" field of the
+// document whose write it describes.
+func NewPendingEvent(
+	eventType event_entity.EventType, aggregateId string, data interface{}) (*EventEntityMongo, *internal_error.InternalError) {
+	event, err := event_entity.CreateEvent(eventType, aggregateId, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return toEventEntityMongo(event), nil
+}
+
+func (or *OutboxRepository) AppendEvent(
+	ctx context.Context, event *event_entity.Event) *internal_error.InternalError {
+	if _, err := or.Collection.InsertOne(ctx, toEventEntityMongo(event)); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert outbox event", err,
+			zap.String("event_type", string(event.Type)),
+			zap.String("aggregate_id", event.AggregateId))
+		return mongodb.NewDatabaseError("Error trying to insert outbox event", err)
+	}
+
+	return nil
+}
+
+type pendingEventsMongo struct {
+	Id     string             `bson:"_id"`
+	Events []EventEntityMongo `bson:"outbox"`
+}
+
+// relayPendingEvents copies up to limit documents' embedded events to the
+// outbox collection of each source, then removes them from the documents.
+// Event ids are the outbox keys, so a relay interrupted between the two steps
+// stores nothing twice when it runs again.
+func (or *OutboxRepository) relayPendingEvents(ctx context.Context, limit int64) *internal_error.InternalError {
+	filter := bson.M{"outbox._id": bson.M{"$exists": true}}
+	opts := options.Find().SetProjection(bson.M{"outbox": 1}).SetLimit(limit)
+
+	for _, source := range or.sources {
+		cursor, err := source.Find(ctx, filter, opts)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find pending outbox events", err,
+				zap.String("collection", source.Name()))
+			return mongodb.NewDatabaseError("Error trying to find pending outbox events", err)
+		}
+
+		var documents []pendingEventsMongo
+		if err := cursor.All(ctx, &documents); err != nil {
+			logger.ErrorContext(ctx, "Error trying to find pending outbox events", err,
+				zap.String("collection", source.Name()))
+			return mongodb.NewDatabaseError("Error trying to find pending outbox events", err)
+		}
+
+		for _, document := range documents {
+			if err := or.relayDocumentEvents(ctx, source, document); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (or *OutboxRepository) relayDocumentEvents(
+	ctx context.Context, source *mongo.Collection, document pendingEventsMongo) *internal_error.InternalError {
+	events := make([]interface{}, 0, len(document.Events))
+	eventIds := make(bson.A, 0, len(document.Events))
+	for _, event := range document.Events {
+		events = append(events, event)
+		eventIds = append(eventIds, event.Id)
+	}
+
+	_, err := or.Collection.InsertMany(ctx, events, options.InsertMany().SetOrdered(false))
+	if err != nil && !onlyDuplicateKeys(err) {
+		logger.ErrorContext(ctx, "Error trying to relay outbox events", err,
+			zap.String("collection", source.Name()), zap.String("document_id", document.Id))
+		return mongodb.NewDatabaseError("Error trying to relay outbox events", err)
+	}
+
+	update := bson.M{"$pull": bson.M{"outbox": bson.M{"_id": bson.M{"$in": eventIds}}}}
+	if _, err := source.UpdateOne(ctx, bson.M{"_id": document.Id}, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to remove relayed outbox events", err,
+			zap.String("collection", source.Name()), zap.String("document_id", document.Id))
+		return mongodb.NewDatabaseError("Error trying to remove relayed outbox events", err)
+	}
+
+	return nil
+}
+
+// onlyDuplicateKeys reports whether every write of a failed insert was
+// refused because the document already exists.
+func onlyDuplicateKeys(err error) bool {
+	var bulkWriteException mongo.BulkWriteException
+	if !errors.As(err, &bulkWriteException) ||
+		bulkWriteException.WriteConcernError != nil || len(bulkWriteException.WriteErrors) == 0 {
+		return false
+	}
+
+	for _, writeError := range bulkWriteException.WriteErrors {
+		if writeError.Code != duplicateKeyCode {
+			return false
+		}
+	}
+	return true
+}
+
+func toEventEntityMongo(event *event_entity.Event) *EventEntityMongo {
+	return &EventEntityMongo{
+		Id:          event.Id,
+		Type:        event.Type,
+		AggregateId: event.AggregateId,
+		Payload:     string(event.Payload),
+		Timestamp:   event.Timestamp.UnixNano(),
+	}
+}
@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestOnlyDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name: "every event already relayed",
+			err: mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+				{WriteError: mongo.WriteError{Index: 0, Code: duplicateKeyCode}},
+				{WriteError: mongo.WriteError{Index: 1, Code: duplicateKeyCode}},
+			}},
+			expected: true,
+		},
+		{
+			name: "an event failed for another reason",
+			err: mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+				{WriteError: mongo.WriteError{Index: 0, Code: duplicateKeyCode}},
+				{WriteError: mongo.WriteError{Index: 1, Code: 121}},
+			}},
+			expected: false,
+		},
+		{
+			name: "write concern not satisfied",
+			err: mongo.BulkWriteException{
+				WriteErrors:       []mongo.BulkWriteError{{WriteError: mongo.WriteError{Code: duplicateKeyCode}}},
+				WriteConcernError: &mongo.WriteConcernError{Code: 64},
+			},
+			expected: false,
+		},
+		{
+			name:     "outcome unknown",
+			err:      errors.New("connection reset"),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := onlyDuplicateKeys(tt.err); got != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindUnpublishedEvents relays the events still embedded in the documents
+// that produced them before reading the outbox.
+func (or *OutboxRepository) FindUnpublishedEvents(
+	ctx context.Context, limit int64) ([]event_entity.Event, *internal_error.InternalError) {
+	if err := or.relayPendingEvents(ctx, limit); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"published": false}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}).SetLimit(limit)
+
+	cursor, err := or.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find unpublished events", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find unpublished events", err)
+	}
+	defer cursor.Close(ctx)
+
+	var eventEntitiesMongo []EventEntityMongo
+	if err := cursor.All(ctx, &eventEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find unpublished events", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find unpublished events", err)
+	}
+
+	var eventEntities []event_entity.Event
+	for _, eventEntityMongo := range eventEntitiesMongo {
+		eventEntities = append(eventEntities, event_entity.Event{
+			Id:          eventEntityMongo.Id,
+			Type:        eventEntityMongo.Type,
+			AggregateId: eventEntityMongo.AggregateId,
+			Payload:     []byte(eventEntityMongo.Payload),
+			Timestamp:   time.Unix(0, eventEntityMongo.Timestamp),
+		})
+	}
+
+	return eventEntities, nil
+}
+
+func (or *OutboxRepository) MarkEventPublished(
+	ctx context.Context, eventId string) *internal_error.InternalError {
+	filter := bson.M{"_id": eventId}
+	update := bson.M{"$set": bson.M{"published": true}}
+
+	if _, err := or.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to mark event as published", err)
+		return mongodb.NewDatabaseError("Error trying to mark event as published", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const attachmentColumns = "id, auction_id, kind, file_name, content_type, size, timestamp"
+
+type AttachmentRepository struct {
+	database *sql.DB
+}
+
+func NewAttachmentRepository(database *sql.DB) *AttachmentRepository {
+	return &AttachmentRepository{database: database}
+}
+
+func (ar *AttachmentRepository) CreateAttachment(
+	ctx context.Context, attachment *attachment_entity.Attachment) *internal_error.InternalError {
+	if _, err := ar.database.ExecContext(ctx,
+		"INSERT INTO attachments ("+attachmentColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		attachment.Id, attachment.AuctionId, string(attachment.Kind), attachment.FileName,
+		attachment.ContentType, attachment.Size, attachment.Timestamp.Unix()); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert attachment", err)
+		return postgres_connection.NewDatabaseError("Error trying to insert attachment", err)
+	}
+
+	return nil
+}
+
+func (ar *AttachmentRepository) FindAttachmentById(
+	ctx context.Context, attachmentId string) (*attachment_entity.Attachment, *internal_error.InternalError) {
+	attachment, err := scanAttachment(ar.database.QueryRowContext(ctx,
+		"SELECT "+attachmentColumns+" FROM attachments WHERE id = $1", attachmentId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("Attachment not found with this id = %s", attachmentId))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find attachment", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find attachment", err)
+	}
+
+	return attachment, nil
+}
+
+func (ar *AttachmentRepository) FindAttachmentsByAuctionIds(
+	ctx context.Context, auctionIds []string) ([]attachment_entity.Attachment, *internal_error.InternalError) {
+	rows, err := ar.database.QueryContext(ctx,
+		"SELECT "+attachmentColumns+" FROM attachments WHERE auction_id = ANY($1) ORDER BY timestamp, id",
+		pq.Array(auctionIds))
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find attachments", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find attachments", err)
+	}
+	defer rows.Close()
+
+	var attachments []attachment_entity.Attachment
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find attachments", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find attachments", err)
+		}
+		attachments = append(attachments, *attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find attachments", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find attachments", err)
+	}
+
+	return attachments, nil
+}
+
+func (ar *AttachmentRepository) DeleteAttachment(
+	ctx context.Context, attachmentId string) *internal_error.InternalError {
+	result, err := ar.database.ExecContext(ctx, "DELETE FROM attachments WHERE id = $1", attachmentId)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to delete attachment", err)
+		return postgres_connection.NewDatabaseError("Error trying to delete attachment", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Attachment not found with this id = %s", attachmentId))
+	}
+
+	return nil
+}
+
+func scanAttachment(row scanner) (*attachment_entity.Attachment, error) {
+	var attachment attachment_entity.Attachment
+	var kind string
+	var timestamp int64
+	if err := row.Scan(&attachment.Id, &attachment.AuctionId, &kind, &attachment.FileName,
+		&attachment.ContentType, &attachment.Size, &timestamp); err != nil {
+		return nil, err
+	}
+
+	attachment.Kind = attachment_entity.AttachmentKind(kind)
+	attachment.Timestamp = time.Unix(timestamp, 0)
+
+	return &attachment, nil
+}
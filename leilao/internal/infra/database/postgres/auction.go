@@ -0,0 +1,576 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("fullcycle-auction_go/internal/infra/database/postgres")
+
+const (
+	defaultAuctionPageSize = 20
+	defaultSearchPageSize  = 20
+
+	// closingSweepBatchSize bounds how many auctions a single sweep statement
+	// closes, keeping its row locks short.
+	closingSweepBatchSize = 100
+)
+
+const auctionColumns = `id, seller_id, product_name, category, description, condition, status,
+	timestamp, end_time, current_price, reserve_price, highest_bid_id, highest_bidder_id, bid_count,
+	format, buy_it_now_price, second_price, dutch_start_price, dutch_decrement, dutch_interval_seconds,
+	dutch_floor_price`
+
+// searchQuery turns the search terms into a tsquery matching any of them,
+// like the MongoDB text index does.
+const searchQuery = `replace(plainto_tsquery('portuguese', $1)::text, ' & ', ' | ')::tsquery`
+
+// AuctionRepository closes auctions with a periodic sweep instead of a timer
+// per auction, so every replica can run it: rows claimed by one replica are
+// skipped by the others.
+type AuctionRepository struct {
+	database        *sql.DB
+	auctionInterval time.Duration
+	sweepInterval   time.Duration
+	statusListeners []func(auctionId string, status auction_entity.AuctionStatus)
+	stopSweep       context.CancelFunc
+	sweepDone       chan struct{}
+}
+
+// NewAuctionRepository closes expired auctions with a sweep every
+// sweepInterval, see StartClosingSweep.
+func NewAuctionRepository(database *sql.DB, auctionInterval, sweepInterval time.Duration) *AuctionRepository {
+	return &AuctionRepository{
+		database:        database,
+		auctionInterval: auctionInterval,
+		sweepInterval:   sweepInterval,
+	}
+}
+
+func (ar *AuctionRepository) CreateAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	if auctionEntity.EndTime.IsZero() {
+		auctionEntity.EndTime = auctionEntity.Timestamp.Add(ar.auctionInterval)
+	}
+
+	event, err := event_entity.CreateEvent(event_entity.AuctionCreated, auctionEntity.Id, event_entity.AuctionCreatedData{
+		AuctionId:   auctionEntity.Id,
+		SellerId:    auctionEntity.SellerId,
+		ProductName: auctionEntity.ProductName,
+		Category:    auctionEntity.Category,
+		Format:      string(auctionEntity.Format),
+		EndTime:     auctionEntity.EndTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := ar.insertAuction(ctx, auctionEntity, event); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert auction", err)
+		return postgres_connection.NewDatabaseError("Error trying to insert auction", err)
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) insertAuction(
+	ctx context.Context, auctionEntity *auction_entity.Auction, event *event_entity.Event) error {
+	tx, err := ar.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO auctions (id, seller_id, product_name, category, description, condition,
+		status, timestamp, end_time, reserve_price, format, buy_it_now_price, dutch_start_price,
+		dutch_decrement, dutch_interval_seconds, dutch_floor_price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		auctionEntity.Id, auctionEntity.SellerId, auctionEntity.ProductName, auctionEntity.Category,
+		auctionEntity.Description, auctionEntity.Condition, auctionEntity.Status,
+		auctionEntity.Timestamp.Unix(), auctionEntity.EndTime.Unix(), auctionEntity.ReservePrice,
+		auctionEntity.Format, auctionEntity.BuyItNowPrice, auctionEntity.DutchSchedule.StartPrice,
+		auctionEntity.DutchSchedule.Decrement, int64(auctionEntity.DutchSchedule.Interval/time.Second),
+		auctionEntity.DutchSchedule.FloorPrice); err != nil {
+		return err
+	}
+
+	if err := insertEvents(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (ar *AuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	auctionEntity, err := scanAuction(ar.database.QueryRowContext(ctx,
+		"SELECT "+auctionColumns+" FROM auctions WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", id))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Error trying to find auction by id = %s", id), err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find auction by id", err)
+	}
+
+	return auctionEntity, nil
+}
+
+// FindAuctions pages with a keyset on the sort column and the id, so pages stay
+// stable while auctions are created.
+func (ar *AuctionRepository) FindAuctions(
+	ctx context.Context,
+	auctionFilter auction_entity.AuctionFilter) (*auction_entity.AuctionPage, *internal_error.InternalError) {
+	var where conditions
+	if auctionFilter.Status != nil {
+		where.add("status = $%d", *auctionFilter.Status)
+	}
+	if auctionFilter.Category != "" {
+		where.add("category = $%d", auctionFilter.Category)
+	}
+	if auctionFilter.ProductName != "" {
+		where.add("product_name ILIKE $%d", "%"+escapeLike(auctionFilter.ProductName)+"%")
+	}
+	if auctionFilter.Condition != nil {
+		where.add("condition = $%d", *auctionFilter.Condition)
+	}
+	if auctionFilter.MinPrice != nil {
+		where.add("current_price >= $%d", *auctionFilter.MinPrice)
+	}
+	if auctionFilter.MaxPrice != nil {
+		where.add("current_price <= $%d", *auctionFilter.MaxPrice)
+	}
+	if auctionFilter.UsesPrice() {
+		// The price of an active sealed auction must not be probed, neither
+		// by filtering nor by sorting or by the cursor of the next page.
+		where.add(fmt.Sprintf("(status <> %d OR format <> ALL($%%d))", auction_entity.Active), pq.Array([]string{
+			string(auction_entity.SealedFirstPrice), string(auction_entity.SealedSecondPrice)}))
+	}
+	if auctionFilter.EndingBefore != nil {
+		where.add("end_time <= $%d", auctionFilter.EndingBefore.Unix())
+	}
+
+	var total int64
+	if err := ar.database.QueryRowContext(ctx,
+		"SELECT count(*) FROM auctions"+where.sql(), where.args...).Scan(&total); err != nil {
+		logger.ErrorContext(ctx, "Error counting auctions", err)
+		return nil, postgres_connection.NewDatabaseError("Error counting auctions", err)
+	}
+
+	column := auctionSortColumn(auctionFilter.SortBy)
+	direction, comparison := "ASC", ">"
+	if auctionFilter.SortDescending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if auctionFilter.Cursor != "" {
+		cursor, err := decodeAuctionCursor(auctionFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+
+		where.args = append(where.args, cursorValue(column, cursor.Value), cursor.Id)
+		where.clauses = append(where.clauses, fmt.Sprintf("(%s, id) %s ($%d, $%d)",
+			column, comparison, len(where.args)-1, len(where.args)))
+	}
+
+	limit := auctionFilter.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM auctions%s ORDER BY %s %s, id %s LIMIT %d",
+		auctionColumns, where.sql(), column, direction, direction, limit+1)
+	auctionEntities, err := ar.queryAuctions(ctx, query, where.args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error finding auctions", err)
+		return nil, postgres_connection.NewDatabaseError("Error finding auctions", err)
+	}
+
+	var nextCursor string
+	if len(auctionEntities) > limit {
+		auctionEntities = auctionEntities[:limit]
+		last := auctionEntities[limit-1]
+		nextCursor = encodeAuctionCursor(auctionCursor{Value: auctionSortValue(column, last), Id: last.Id})
+	}
+
+	return &auction_entity.AuctionPage{
+		Auctions:   auctionEntities,
+		Total:      total,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// SearchAuctions ranks auctions with ts_rank over the weighted search_vector
+// column. Category facets are counted over every match of the query, before
+// the category filter is applied.
+func (ar *AuctionRepository) SearchAuctions(
+	ctx context.Context,
+	search auction_entity.AuctionSearch) (*auction_entity.AuctionSearchPage, *internal_error.InternalError) {
+	where := conditions{
+		clauses: []string{"search_vector @@ " + searchQuery},
+		args:    []interface{}{search.Query},
+	}
+	if search.Status != nil {
+		where.add("status = $%d", *search.Status)
+	}
+
+	searchPage := &auction_entity.AuctionSearchPage{}
+
+	rows, err := ar.database.QueryContext(ctx,
+		"SELECT category, count(*) FROM auctions"+where.sql()+" GROUP BY category ORDER BY count(*) DESC, category",
+		where.args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching auctions", err)
+		return nil, postgres_connection.NewDatabaseError("Error searching auctions", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var facet auction_entity.CategoryFacet
+		if err := rows.Scan(&facet.Category, &facet.Count); err != nil {
+			logger.ErrorContext(ctx, "Error decoding auction search results", err)
+			return nil, postgres_connection.NewDatabaseError("Error decoding auction search results", err)
+		}
+		searchPage.Facets = append(searchPage.Facets, facet)
+
+		if search.Category == "" || facet.Category == search.Category {
+			searchPage.Total += facet.Count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error decoding auction search results", err)
+		return nil, postgres_connection.NewDatabaseError("Error decoding auction search results", err)
+	}
+
+	if search.Category != "" {
+		where.add("category = $%d", search.Category)
+	}
+
+	limit := search.Limit
+	if limit <= 0 {
+		limit = defaultSearchPageSize
+	}
+
+	resultRows, err := ar.database.QueryContext(ctx, fmt.Sprintf(
+		"SELECT %s, ts_rank(search_vector, %s) AS score FROM auctions%s ORDER BY score DESC, id LIMIT %d OFFSET %d",
+		auctionColumns, searchQuery, where.sql(), limit, search.Offset), where.args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching auctions", err)
+		return nil, postgres_connection.NewDatabaseError("Error searching auctions", err)
+	}
+	defer resultRows.Close()
+
+	for resultRows.Next() {
+		var result auction_entity.AuctionSearchResult
+		auctionEntity, err := scanAuction(resultRows, &result.Score)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error decoding auction search results", err)
+			return nil, postgres_connection.NewDatabaseError("Error decoding auction search results", err)
+		}
+		result.Auction = *auctionEntity
+		searchPage.Results = append(searchPage.Results, result)
+	}
+	if err := resultRows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error decoding auction search results", err)
+		return nil, postgres_connection.NewDatabaseError("Error decoding auction search results", err)
+	}
+
+	return searchPage, nil
+}
+
+func (ar *AuctionRepository) UpdateAuction(
+	ctx context.Context,
+	auctionEntity *auction_entity.Auction) *internal_error.InternalError {
+	result, err := ar.database.ExecContext(ctx,
+		`UPDATE auctions SET product_name = $2, category = $3, description = $4, condition = $5
+		WHERE id = $1 AND status = $6 AND bid_count = 0`,
+		auctionEntity.Id, auctionEntity.ProductName, auctionEntity.Category,
+		auctionEntity.Description, auctionEntity.Condition, auction_entity.Active)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to update auction", err)
+		return postgres_connection.NewDatabaseError("Error trying to update auction", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewConflictError("Only active auctions without bids can be edited")
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) CancelAuction(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	result, err := ar.database.ExecContext(ctx,
+		"UPDATE auctions SET status = $2 WHERE id = $1 AND status = $3",
+		auctionId, auction_entity.Cancelled, auction_entity.Active)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to cancel auction", err)
+		return postgres_connection.NewDatabaseError("Error trying to cancel auction", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewConflictError("Only active auctions can be cancelled")
+	}
+
+	ar.notifyStatusChange(auctionId, auction_entity.Cancelled)
+
+	return nil
+}
+
+func (ar *AuctionRepository) ExtendAuctionEndTime(
+	ctx context.Context,
+	auctionId string, endTime time.Time) *internal_error.InternalError {
+	if _, err := ar.database.ExecContext(ctx,
+		"UPDATE auctions SET end_time = $2 WHERE id = $1 AND status = $3 AND end_time < $2",
+		auctionId, endTime.Unix(), auction_entity.Active); err != nil {
+		logger.ErrorContext(ctx, "Error trying to extend auction end time", err)
+		return postgres_connection.NewDatabaseError("Error trying to extend auction end time", err)
+	}
+
+	return nil
+}
+
+// StartClosingSweep closes ended auctions every AUCTION_CLOSE_SWEEP_INTERVAL
+// until StopClosingSweep is called. It must be started after the status
+// listeners are registered, or auctions closed meanwhile would not be settled.
+func (ar *AuctionRepository) StartClosingSweep(ctx context.Context) {
+	ctx, ar.stopSweep = context.WithCancel(ctx)
+	ar.sweepDone = make(chan struct{})
+
+	go func() {
+		defer close(ar.sweepDone)
+
+		ticker := time.NewTicker(ar.sweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ar.closeEndedAuctions(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+func (ar *AuctionRepository) StopClosingSweep() {
+	if ar.stopSweep == nil {
+		return
+	}
+
+	ar.stopSweep()
+	<-ar.sweepDone
+}
+
+// closeEndedAuctions completes every active auction whose end time has passed.
+// SKIP LOCKED leaves out auctions locked by a bid being accepted or by another
+// replica's sweep; the next sweep picks them up if they are still due.
+func (ar *AuctionRepository) closeEndedAuctions(ctx context.Context, now time.Time) {
+	ctx, span := tracer.Start(ctx, "AuctionRepository.closeEndedAuctions")
+	defer span.End()
+
+	closedCount := 0
+	defer func() { span.SetAttributes(attribute.Int("auction.closed_count", closedCount)) }()
+
+	for {
+		rows, err := ar.database.QueryContext(ctx,
+			`UPDATE auctions SET status = $1 WHERE id IN (
+				SELECT id FROM auctions WHERE status = $2 AND end_time <= $3
+				ORDER BY end_time LIMIT $4 FOR UPDATE SKIP LOCKED)
+			RETURNING id`,
+			auction_entity.Completed, auction_entity.Active, now.Unix(), closingSweepBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.ErrorContext(ctx, "Error trying to close auctions", err)
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return
+		}
+
+		var closed []string
+		for rows.Next() {
+			var auctionId string
+			if err := rows.Scan(&auctionId); err != nil {
+				logger.ErrorContext(ctx, "Error trying to close auctions", err)
+				break
+			}
+			closed = append(closed, auctionId)
+		}
+		rows.Close()
+		closedCount += len(closed)
+
+		for _, auctionId := range closed {
+			logger.InfoContext(ctx, "Auction closed", zap.String("auction_id", auctionId))
+			ar.notifyStatusChange(auctionId, auction_entity.Completed)
+		}
+
+		if len(closed) < closingSweepBatchSize {
+			return
+		}
+	}
+}
+
+// OnStatusChange registers a callback fired whenever this replica closes or
+// cancels an auction, so caches of auction state can follow along.
+func (ar *AuctionRepository) OnStatusChange(
+	listener func(auctionId string, status auction_entity.AuctionStatus)) {
+	ar.statusListeners = append(ar.statusListeners, listener)
+}
+
+func (ar *AuctionRepository) notifyStatusChange(
+	auctionId string, status auction_entity.AuctionStatus) {
+	for _, listener := range ar.statusListeners {
+		listener(auctionId, status)
+	}
+}
+
+func (ar *AuctionRepository) queryAuctions(
+	ctx context.Context, query string, args ...interface{}) ([]auction_entity.Auction, error) {
+	rows, err := ar.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var auctionEntities []auction_entity.Auction
+	for rows.Next() {
+		auctionEntity, err := scanAuction(rows)
+		if err != nil {
+			return nil, err
+		}
+		auctionEntities = append(auctionEntities, *auctionEntity)
+	}
+
+	return auctionEntities, rows.Err()
+}
+
+// scanAuction reads the auctionColumns, followed by any extra columns.
+func scanAuction(row scanner, extra ...interface{}) (*auction_entity.Auction, error) {
+	var auctionEntity auction_entity.Auction
+	var timestamp, endTime, dutchIntervalSeconds int64
+
+	dest := append([]interface{}{
+		&auctionEntity.Id, &auctionEntity.SellerId, &auctionEntity.ProductName, &auctionEntity.Category,
+		&auctionEntity.Description, &auctionEntity.Condition, &auctionEntity.Status,
+		&timestamp, &endTime, &auctionEntity.CurrentPrice, &auctionEntity.ReservePrice,
+		&auctionEntity.HighestBidId, &auctionEntity.HighestBidderId, &auctionEntity.BidCount,
+		&auctionEntity.Format, &auctionEntity.BuyItNowPrice, &auctionEntity.SecondPrice,
+		&auctionEntity.DutchSchedule.StartPrice, &auctionEntity.DutchSchedule.Decrement,
+		&dutchIntervalSeconds, &auctionEntity.DutchSchedule.FloorPrice,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	auctionEntity.Timestamp = time.Unix(timestamp, 0)
+	auctionEntity.EndTime = time.Unix(endTime, 0)
+	auctionEntity.DutchSchedule.Interval = time.Duration(dutchIntervalSeconds) * time.Second
+
+	return &auctionEntity, nil
+}
+
+// conditions builds a WHERE clause with numbered placeholders. Each clause
+// holds a single %d verb replaced by the number of its argument.
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+func (c *conditions) add(clause string, arg interface{}) {
+	c.args = append(c.args, arg)
+	c.clauses = append(c.clauses, fmt.Sprintf(clause, len(c.args)))
+}
+
+func (c *conditions) sql() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func auctionSortColumn(sortBy auction_entity.AuctionSortField) string {
+	switch sortBy {
+	case auction_entity.SortByEndTime:
+		return "end_time"
+	case auction_entity.SortByCurrentPrice:
+		return "current_price"
+	default:
+		return "timestamp"
+	}
+}
+
+func auctionSortValue(column string, auctionEntity auction_entity.Auction) float64 {
+	switch column {
+	case "end_time":
+		return float64(auctionEntity.EndTime.Unix())
+	case "current_price":
+		return auctionEntity.CurrentPrice
+	default:
+		return float64(auctionEntity.Timestamp.Unix())
+	}
+}
+
+// cursorValue converts the cursor value back to the type of its column, so the
+// keyset comparison can use the column's index.
+func cursorValue(column string, value float64) interface{} {
+	if column == "current_price" {
+		return value
+	}
+	return int64(value)
+}
+
+// auctionCursor points at the last auction of a page by its sort value and id.
+type auctionCursor struct {
+	Value float64 `json:"v"`
+	Id    string  `json:"id"`
+}
+
+func encodeAuctionCursor(cursor auctionCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAuctionCursor(value string) (*auctionCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor auctionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.Id == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestConditionsNumberPlaceholders(t *testing.T) {
+	var where conditions
+	if where.sql() != "" {
+		t.Errorf("Expected no WHERE clause without conditions, got %q", where.sql())
+	}
+
+	where.add("status = $%d", 0)
+	where.add("product_name ILIKE $%d", "%"+escapeLike(`50%_off\`)+"%")
+
+	if expected := " WHERE status = $1 AND product_name ILIKE $2"; where.sql() != expected {
+		t.Errorf("Expected %q, got %q", expected, where.sql())
+	}
+	if expected := []interface{}{0, `%50\%\_off\\%`}; !reflect.DeepEqual(where.args, expected) {
+		t.Errorf("Expected args %v, got %v", expected, where.args)
+	}
+}
+
+func TestFindAuctionsByPriceLeavesOutActiveSealedAuctions(t *testing.T) {
+	repositorytest.FindAuctionsByPriceLeavesOutActiveSealedAuctions(t,
+		func(t *testing.T, auctions []auction_entity.Auction) auction_entity.AuctionRepositoryInterface {
+			database := newTestDatabase(t)
+			auctionRepository := NewAuctionRepository(database, 5*time.Minute, time.Second)
+			for _, auctionEntity := range auctions {
+				auctionEntity := auctionEntity
+				if err := auctionRepository.CreateAuction(context.Background(), &auctionEntity); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if _, err := database.Exec("UPDATE auctions SET current_price = $1 WHERE id = $2",
+					auctionEntity.CurrentPrice, auctionEntity.Id); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			}
+			return auctionRepository
+		})
+}
@@ -0,0 +1,464 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const bidColumns = "id, user_id, auction_id, amount, automatic, timestamp"
+
+type BidRepository struct {
+	database          *sql.DB
+	AuctionRepository *AuctionRepository
+	auctionInterval   time.Duration
+	softClosePolicy   auction_entity.SoftClosePolicy
+}
+
+func NewBidRepository(
+	database *sql.DB,
+	auctionRepository *AuctionRepository,
+	auctionInterval time.Duration,
+	softClosePolicy auction_entity.SoftClosePolicy) *BidRepository {
+	return &BidRepository{
+		database:          database,
+		AuctionRepository: auctionRepository,
+		auctionInterval:   auctionInterval,
+		softClosePolicy:   softClosePolicy,
+	}
+}
+
+// CreateBid accepts the bids of each auction in their own transaction, running
+// the auctions of the batch concurrently. Within an auction the row lock taken
+// by placeBids serializes bids across requests and replicas.
+func (bd *BidRepository) CreateBid(
+	ctx context.Context,
+	bidEntities []bid_entity.Bid) ([]bid_entity.BidFailure, *internal_error.InternalError) {
+	auctionIndexes := make(map[string][]int)
+	for index, bidValue := range bidEntities {
+		auctionIndexes[bidValue.AuctionId] = append(auctionIndexes[bidValue.AuctionId], index)
+	}
+
+	var failures []bid_entity.BidFailure
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for auctionId, indexes := range auctionIndexes {
+		wg.Add(1)
+		go func(auctionId string, indexes []int) {
+			defer wg.Done()
+
+			auctionFailures := bd.placeBids(ctx, auctionId, bidEntities, indexes)
+
+			mutex.Lock()
+			failures = append(failures, auctionFailures...)
+			mutex.Unlock()
+		}(auctionId, indexes)
+	}
+
+	wg.Wait()
+
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Index < failures[j].Index
+	})
+
+	return failures, nil
+}
+
+// placeBids locks the auction row with SELECT ... FOR UPDATE, applies each bid
+// to the locked state in timestamp order and writes the accepted bids, the new
+// auction state, the soft-close extension and the outbox events in one
+// transaction. Bids that are already stored are skipped, which keeps replays
+// idempotent. If the transaction fails nothing is written and every bid of the
+// auction is reported as retryable.
+func (bd *BidRepository) placeBids(
+	ctx context.Context,
+	auctionId string, bidEntities []bid_entity.Bid, indexes []int) []bid_entity.BidFailure {
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return bidEntities[indexes[i]].Timestamp.Before(bidEntities[indexes[j]].Timestamp)
+	})
+
+	failAll := func(reason bid_entity.BidFailureReason) []bid_entity.BidFailure {
+		failures := make([]bid_entity.BidFailure, 0, len(indexes))
+		for _, index := range indexes {
+			failures = append(failures, bid_entity.BidFailure{
+				Index: index, BidId: bidEntities[index].Id, Reason: reason})
+		}
+		return failures
+	}
+
+	tx, err := bd.database.BeginTx(ctx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to begin bid transaction", err, zap.String("auction_id", auctionId))
+		return failAll(bid_entity.AuctionUnavailable)
+	}
+	defer tx.Rollback()
+
+	auction, err := scanAuction(tx.QueryRowContext(ctx,
+		"SELECT "+auctionColumns+" FROM auctions WHERE id = $1 FOR UPDATE", auctionId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return failAll(bid_entity.AuctionNotFound)
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to lock auction", err, zap.String("auction_id", auctionId))
+		return failAll(bid_entity.AuctionUnavailable)
+	}
+
+	stored, err := bd.storedBidIds(ctx, tx, bidEntities, indexes)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find stored bids", err, zap.String("auction_id", auctionId))
+		return failAll(bid_entity.AuctionUnavailable)
+	}
+
+	originalEndTime := auction.Timestamp.Add(bd.auctionInterval)
+	initialEndTime := auction.EndTime
+
+	var failures []bid_entity.BidFailure
+	var accepted []bid_entity.Bid
+	for _, index := range indexes {
+		bidValue := bidEntities[index]
+		if stored[bidValue.Id] {
+			continue
+		}
+
+		reason := bd.acceptBid(auction, bidValue, originalEndTime)
+		if reason != "" {
+			failures = append(failures, bid_entity.BidFailure{
+				Index: index, BidId: bidValue.Id, Reason: reason})
+			continue
+		}
+
+		bidValue.Timestamp = time.Unix(bidValue.Timestamp.Unix(), 0)
+		accepted = append(accepted, bidValue)
+	}
+
+	if len(accepted) == 0 {
+		return failures
+	}
+
+	if err := bd.writeBids(ctx, tx, auction, accepted); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert bids", err,
+			zap.String("auction_id", auctionId), zap.Int("count", len(accepted)))
+		return failAll(bid_entity.InsertFailed)
+	}
+
+	if auction.Status == auction_entity.Completed {
+		logger.InfoContext(ctx, "Auction closed by bid",
+			zap.String("auction_id", auctionId),
+			zap.String("bid_id", auction.HighestBidId))
+		bd.AuctionRepository.notifyStatusChange(auctionId, auction_entity.Completed)
+	} else if !auction.EndTime.Equal(initialEndTime) {
+		logger.InfoContext(ctx, "Auction end time extended",
+			zap.String("auction_id", auctionId),
+			zap.Time("end_time", auction.EndTime))
+	}
+
+	return failures
+}
+
+// acceptBid applies the bid to the locked auction with the rules of its
+// format and, for English auctions, the soft-close policy.
+func (bd *BidRepository) acceptBid(
+	auction *auction_entity.Auction,
+	bidValue bid_entity.Bid, originalEndTime time.Time) bid_entity.BidFailureReason {
+	if auction.Status != auction_entity.Active || bidValue.Timestamp.Unix() > auction.EndTime.Unix() {
+		return bid_entity.AuctionClosed
+	}
+
+	placement := auction.ApplyBid(bidValue.Id, bidValue.UserId, bidValue.Amount, bidValue.Timestamp)
+	if !placement.Accepted {
+		logger.Info("Bid rejected by the rules of the auction format",
+			zap.String("auction_id", bidValue.AuctionId),
+			zap.String("bid_id", bidValue.Id))
+		return bid_entity.BidRejected
+	}
+
+	if placement.Closes || auction.Format != auction_entity.English {
+		return ""
+	}
+
+	if nextEndTime, ok := bd.softClosePolicy.NextEndTime(
+		originalEndTime, auction.EndTime, bidValue.Timestamp); ok {
+		auction.EndTime = nextEndTime
+	}
+
+	return ""
+}
+
+func (bd *BidRepository) storedBidIds(
+	ctx context.Context,
+	tx *sql.Tx, bidEntities []bid_entity.Bid, indexes []int) (map[string]bool, error) {
+	ids := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		ids = append(ids, bidEntities[index].Id)
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM bids WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		stored[id] = true
+	}
+
+	return stored, rows.Err()
+}
+
+func (bd *BidRepository) writeBids(
+	ctx context.Context,
+	tx *sql.Tx, auction *auction_entity.Auction, accepted []bid_entity.Bid) error {
+	events := make([]*event_entity.Event, 0, len(accepted))
+	for _, bidValue := range accepted {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO bids ("+bidColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+			bidValue.Id, bidValue.UserId, bidValue.AuctionId, bidValue.Amount,
+			bidValue.Automatic, bidValue.Timestamp.Unix()); err != nil {
+			return err
+		}
+
+		event, err := event_entity.CreateEvent(event_entity.BidAccepted, bidValue.AuctionId, event_entity.BidAcceptedData{
+			BidId:     bidValue.Id,
+			AuctionId: bidValue.AuctionId,
+			UserId:    bidValue.UserId,
+			Amount:    bidValue.Amount,
+			Automatic: bidValue.Automatic,
+			Timestamp: bidValue.Timestamp,
+		})
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE auctions SET current_price = $2, second_price = $3, highest_bid_id = $4,
+		highest_bidder_id = $5, bid_count = $6, end_time = $7, status = $8 WHERE id = $1`,
+		auction.Id, auction.CurrentPrice, auction.SecondPrice, auction.HighestBidId,
+		auction.HighestBidderId, auction.BidCount, auction.EndTime.Unix(), auction.Status); err != nil {
+		return err
+	}
+
+	if err := insertEvents(ctx, tx, events...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (bd *BidRepository) FindBidByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	rows, err := bd.database.QueryContext(ctx,
+		"SELECT "+bidColumns+" FROM bids WHERE auction_id = $1 ORDER BY timestamp, id", auctionId)
+	if err != nil {
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		return nil, postgres_connection.NewDatabaseError(
+			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+	}
+	defer rows.Close()
+
+	var bidEntities []bid_entity.Bid
+	for rows.Next() {
+		bidEntity, err := scanBid(rows)
+		if err != nil {
+			logger.ErrorContext(ctx,
+				fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+			return nil, postgres_connection.NewDatabaseError(
+				fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		}
+		bidEntities = append(bidEntities, *bidEntity)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+		return nil, postgres_connection.NewDatabaseError(
+			fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
+	}
+
+	return bidEntities, nil
+}
+
+// FindBids pages through the bids matched by the filter with a keyset on
+// (timestamp, id) and aggregates their stats over every match.
+func (bd *BidRepository) FindBids(
+	ctx context.Context, bidFilter bid_entity.BidFilter) (*bid_entity.BidPage, *internal_error.InternalError) {
+	var where conditions
+	if bidFilter.AuctionId != "" {
+		where.add("auction_id = $%d", bidFilter.AuctionId)
+	}
+	if bidFilter.UserId != "" {
+		where.add("user_id = $%d", bidFilter.UserId)
+	}
+
+	var stats bid_entity.BidStats
+	var firstBidAt, lastBidAt int64
+	if err := bd.database.QueryRowContext(ctx,
+		`SELECT count(*), count(DISTINCT user_id), count(DISTINCT auction_id), COALESCE(max(amount), 0),
+		COALESCE(avg(amount), 0), COALESCE(min(timestamp), 0), COALESCE(max(timestamp), 0) FROM bids`+where.sql(),
+		where.args...).Scan(&stats.Count, &stats.Bidders, &stats.Auctions, &stats.HighestAmount,
+		&stats.AverageAmount, &firstBidAt, &lastBidAt); err != nil {
+		logger.ErrorContext(ctx, "Error trying to aggregate bids", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to aggregate bids", err)
+	}
+	if stats.Count > 0 {
+		stats.FirstBidAt = time.Unix(firstBidAt, 0)
+		stats.LastBidAt = time.Unix(lastBidAt, 0)
+	}
+
+	direction, comparison := "ASC", ">"
+	if bidFilter.SortDescending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if bidFilter.Cursor != "" {
+		cursor, err := decodeBidCursor(bidFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+
+		where.args = append(where.args, cursor.Timestamp, cursor.Id)
+		where.clauses = append(where.clauses, fmt.Sprintf("(timestamp, id) %s ($%d, $%d)",
+			comparison, len(where.args)-1, len(where.args)))
+	}
+
+	limit := bidFilter.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+
+	rows, err := bd.database.QueryContext(ctx, fmt.Sprintf(
+		"SELECT %s FROM bids%s ORDER BY timestamp %s, id %s LIMIT %d",
+		bidColumns, where.sql(), direction, direction, limit+1), where.args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find bids", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find bids", err)
+	}
+	defer rows.Close()
+
+	var bidEntities []bid_entity.Bid
+	for rows.Next() {
+		bidEntity, err := scanBid(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find bids", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find bids", err)
+		}
+		bidEntities = append(bidEntities, *bidEntity)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find bids", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find bids", err)
+	}
+
+	var nextCursor string
+	if len(bidEntities) > limit {
+		bidEntities = bidEntities[:limit]
+		last := bidEntities[limit-1]
+		nextCursor = encodeBidCursor(bidCursor{Timestamp: last.Timestamp.Unix(), Id: last.Id})
+	}
+
+	return &bid_entity.BidPage{
+		Bids:       bidEntities,
+		Stats:      stats,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// FindWinningBidByAuctionId reads the highest bid tracked on the auction row
+// instead of sorting every bid of the auction.
+func (bd *BidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	var highestBidId string
+	err := bd.database.QueryRowContext(ctx,
+		"SELECT highest_bid_id FROM auctions WHERE id = $1", auctionId).Scan(&highestBidId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", auctionId))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Error trying to find auction by id = %s", auctionId), err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find auction by id", err)
+	}
+
+	if highestBidId == "" {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
+
+	bidEntity, err := scanBid(bd.database.QueryRowContext(ctx,
+		"SELECT "+bidColumns+" FROM bids WHERE id = $1", highestBidId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find the auction winner", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find the auction winner", err)
+	}
+
+	return bidEntity, nil
+}
+
+func scanBid(row scanner) (*bid_entity.Bid, error) {
+	var bidEntity bid_entity.Bid
+	var timestamp int64
+	if err := row.Scan(&bidEntity.Id, &bidEntity.UserId, &bidEntity.AuctionId,
+		&bidEntity.Amount, &bidEntity.Automatic, &timestamp); err != nil {
+		return nil, err
+	}
+	bidEntity.Timestamp = time.Unix(timestamp, 0)
+
+	return &bidEntity, nil
+}
+
+// bidCursor points at the last bid of a page by its timestamp and id.
+type bidCursor struct {
+	Timestamp int64  `json:"t"`
+	Id        string `json:"id"`
+}
+
+func encodeBidCursor(cursor bidCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeBidCursor(value string) (*bidCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor bidCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.Id == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,215 @@
+package postgres
+
+// Migration is a versioned schema change applied in a single transaction
+// together with its schema_migrations record.
+type Migration struct {
+	Version     int
+	Description string
+	Up          string
+}
+
+// Migrations lists every schema change in version order. Never edit or
+// renumber an applied migration; add a new version instead. Times are stored
+// as Unix seconds, like the MongoDB documents, except outbox timestamps which
+// keep nanoseconds to order events.
+func Migrations() []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "create users table",
+			Up: `
+CREATE TABLE users (
+	id            TEXT PRIMARY KEY,
+	name          TEXT NOT NULL,
+	email         TEXT NOT NULL,
+	password_hash TEXT NOT NULL,
+	role          TEXT NOT NULL,
+	active        BOOLEAN NOT NULL DEFAULT TRUE,
+	timestamp     BIGINT NOT NULL,
+	CONSTRAINT users_email_key UNIQUE (email)
+);`,
+		},
+		{
+			Version:     2,
+			Description: "create auctions table with status and full-text indexes",
+			Up: `
+CREATE TABLE auctions (
+	id                TEXT PRIMARY KEY,
+	seller_id         TEXT NOT NULL DEFAULT '',
+	product_name      TEXT NOT NULL,
+	category          TEXT NOT NULL,
+	description       TEXT NOT NULL,
+	condition         INTEGER NOT NULL,
+	status            INTEGER NOT NULL,
+	timestamp         BIGINT NOT NULL,
+	end_time          BIGINT NOT NULL,
+	current_price     DOUBLE PRECISION NOT NULL DEFAULT 0,
+	reserve_price     DOUBLE PRECISION NOT NULL DEFAULT 0,
+	highest_bid_id    TEXT NOT NULL DEFAULT '',
+	highest_bidder_id TEXT NOT NULL DEFAULT '',
+	bid_count         BIGINT NOT NULL DEFAULT 0,
+	search_vector     TSVECTOR GENERATED ALWAYS AS (
+		setweight(to_tsvector('portuguese', product_name), 'A') ||
+		setweight(to_tsvector('portuguese', category), 'B') ||
+		setweight(to_tsvector('portuguese', description), 'C')
+	) STORED
+);
+
+CREATE INDEX auctions_status_category ON auctions (status, category);
+CREATE INDEX auctions_status_end_time ON auctions (status, end_time);
+CREATE INDEX auctions_search_vector ON auctions USING GIN (search_vector);`,
+		},
+		{
+			Version:     3,
+			Description: "create bids table indexed by auction_id and amount",
+			Up: `
+CREATE TABLE bids (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	auction_id TEXT NOT NULL REFERENCES auctions (id),
+	amount     DOUBLE PRECISION NOT NULL,
+	automatic  BOOLEAN NOT NULL DEFAULT FALSE,
+	timestamp  BIGINT NOT NULL
+);
+
+CREATE INDEX bids_auction_id_amount ON bids (auction_id, amount DESC, timestamp);`,
+		},
+		{
+			Version:     4,
+			Description: "create proxy_bids table unique by auction_id and user_id",
+			Up: `
+CREATE TABLE proxy_bids (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	auction_id TEXT NOT NULL,
+	max_amount DOUBLE PRECISION NOT NULL,
+	timestamp  BIGINT NOT NULL,
+	CONSTRAINT proxy_bids_auction_id_user_id UNIQUE (auction_id, user_id)
+);`,
+		},
+		{
+			Version:     5,
+			Description: "create settlements table",
+			Up: `
+CREATE TABLE settlements (
+	auction_id     TEXT PRIMARY KEY,
+	seller_id      TEXT NOT NULL DEFAULT '',
+	outcome        TEXT NOT NULL,
+	winner_id      TEXT NOT NULL DEFAULT '',
+	winning_bid_id TEXT NOT NULL DEFAULT '',
+	final_price    DOUBLE PRECISION NOT NULL DEFAULT 0,
+	reserve_price  DOUBLE PRECISION NOT NULL DEFAULT 0,
+	bid_count      BIGINT NOT NULL DEFAULT 0,
+	closed_at      BIGINT NOT NULL,
+	settled_at     BIGINT NOT NULL
+);`,
+		},
+		{
+			Version:     6,
+			Description: "create outbox table indexed by unpublished timestamp",
+			Up: `
+CREATE TABLE outbox (
+	id           TEXT PRIMARY KEY,
+	type         TEXT NOT NULL,
+	aggregate_id TEXT NOT NULL,
+	payload      BYTEA NOT NULL,
+	timestamp    BIGINT NOT NULL,
+	published    BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX outbox_unpublished_timestamp ON outbox (timestamp) WHERE NOT published;`,
+		},
+		{
+			Version:     7,
+			Description: "create webhook subscriptions and deliveries tables",
+			Up: `
+CREATE TABLE webhook_subscriptions (
+	id          TEXT PRIMARY KEY,
+	url         TEXT NOT NULL,
+	secret      TEXT NOT NULL,
+	event_types TEXT[] NOT NULL DEFAULT '{}',
+	timestamp   BIGINT NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+	id               TEXT PRIMARY KEY,
+	subscription_id  TEXT NOT NULL,
+	event_id         TEXT NOT NULL,
+	event_type       TEXT NOT NULL,
+	payload          BYTEA NOT NULL,
+	status           TEXT NOT NULL,
+	attempts         INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at  BIGINT NOT NULL,
+	last_error       TEXT NOT NULL DEFAULT '',
+	last_status_code INTEGER NOT NULL DEFAULT 0,
+	timestamp        BIGINT NOT NULL,
+	delivered_at     BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX webhook_deliveries_status_next_attempt_at ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX webhook_deliveries_status_timestamp ON webhook_deliveries (status, timestamp DESC);`,
+		},
+		{
+			Version:     8,
+			Description: "add auction format, buy-it-now price, second price and dutch schedule columns",
+			Up: `
+ALTER TABLE auctions
+	ADD COLUMN format                 TEXT NOT NULL DEFAULT 'english',
+	ADD COLUMN buy_it_now_price       DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN second_price           DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN dutch_start_price      DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN dutch_decrement        DOUBLE PRECISION NOT NULL DEFAULT 0,
+	ADD COLUMN dutch_interval_seconds BIGINT NOT NULL DEFAULT 0,
+	ADD COLUMN dutch_floor_price      DOUBLE PRECISION NOT NULL DEFAULT 0;`,
+		},
+		{
+			Version:     9,
+			Description: "create attachments table indexed by auction_id and timestamp",
+			Up: `
+CREATE TABLE attachments (
+	id           TEXT PRIMARY KEY,
+	auction_id   TEXT NOT NULL,
+	kind         TEXT NOT NULL,
+	file_name    TEXT NOT NULL,
+	content_type TEXT NOT NULL,
+	size         BIGINT NOT NULL,
+	timestamp    BIGINT NOT NULL
+);
+
+CREATE INDEX attachments_auction_id_timestamp ON attachments (auction_id, timestamp);`,
+		},
+		{
+			Version:     10,
+			Description: "create watchlists and notifications tables",
+			Up: `
+CREATE TABLE watchlists (
+	user_id    TEXT NOT NULL,
+	auction_id TEXT NOT NULL,
+	timestamp  BIGINT NOT NULL,
+	PRIMARY KEY (user_id, auction_id)
+);
+
+CREATE INDEX watchlists_auction_id ON watchlists (auction_id);
+
+CREATE TABLE notifications (
+	id         TEXT PRIMARY KEY,
+	user_id    TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	auction_id TEXT NOT NULL,
+	message    TEXT NOT NULL,
+	amount     DOUBLE PRECISION NOT NULL DEFAULT 0,
+	timestamp  BIGINT NOT NULL
+);
+
+CREATE INDEX notifications_user_id_timestamp ON notifications (user_id, timestamp DESC);`,
+		},
+		{
+			Version:     11,
+			Description: "create bids indexes by auction and user timeline and settlements index by winner",
+			Up: `
+CREATE INDEX bids_auction_id_timestamp ON bids (auction_id, timestamp, id);
+CREATE INDEX bids_user_id_timestamp ON bids (user_id, timestamp, id);
+CREATE INDEX settlements_winner_id_closed_at ON settlements (winner_id, closed_at DESC, auction_id DESC);`,
+		},
+	}
+}
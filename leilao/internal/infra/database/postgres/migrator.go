@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/database/migration"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// migrationLockKey is the advisory lock that serializes replicas migrating the
+// same database at startup.
+const migrationLockKey = 7_304_112_901
+
+const createMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+	version     INTEGER PRIMARY KEY,
+	description TEXT NOT NULL,
+	applied_at  BIGINT NOT NULL
+)`
+
+type Migrator struct {
+	database   *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(database *sql.DB) *Migrator {
+	sorted := Migrations()
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		database:   database,
+		migrations: sorted,
+	}
+}
+
+// Run applies every migration not yet recorded in schema_migrations, in
+// version order, stopping at the first failure. Each migration runs in its own
+// transaction holding the advisory lock, so a failed one leaves no trace.
+func (m *Migrator) Run(ctx context.Context) error {
+	for _, pending := range m.migrations {
+		if err := m.apply(ctx, pending); err != nil {
+			logger.ErrorContext(ctx, fmt.Sprintf("Error trying to apply migration %d", pending.Version), err)
+			return fmt.Errorf("migration %d (%s): %w", pending.Version, pending.Description, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) apply(ctx context.Context, pending Migration) error {
+	tx, err := m.database.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockKey); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, createMigrationsTable); err != nil {
+		return err
+	}
+
+	var applied bool
+	if err := tx.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)",
+		pending.Version).Scan(&applied); err != nil {
+		return err
+	}
+
+	if applied {
+		return nil
+	}
+
+	logger.InfoContext(ctx, "Applying migration",
+		zap.Int("version", pending.Version),
+		zap.String("description", pending.Description))
+
+	if _, err := tx.ExecContext(ctx, pending.Up); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, description, applied_at) VALUES ($1, $2, $3)",
+		pending.Version, pending.Description, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]migration.MigrationStatus, error) {
+	if _, err := m.database.ExecContext(ctx, createMigrationsTable); err != nil {
+		logger.ErrorContext(ctx, "Error trying to create schema_migrations table", err)
+		return nil, err
+	}
+
+	rows, err := m.database.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find applied migrations", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]int64)
+	for rows.Next() {
+		var version int
+		var appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			logger.ErrorContext(ctx, "Error trying to decode applied migrations", err)
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to decode applied migrations", err)
+		return nil, err
+	}
+
+	var statuses []migration.MigrationStatus
+	for _, pending := range m.migrations {
+		appliedAt, ok := applied[pending.Version]
+		status := migration.MigrationStatus{
+			Version:     pending.Version,
+			Description: pending.Description,
+			Applied:     ok,
+		}
+		if ok {
+			status.AppliedAt = time.Unix(appliedAt, 0)
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/watchlist_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type WatchlistRepository struct {
+	database *sql.DB
+}
+
+func NewWatchlistRepository(database *sql.DB) *WatchlistRepository {
+	return &WatchlistRepository{database: database}
+}
+
+func (wr *WatchlistRepository) AddToWatchlist(
+	ctx context.Context, item *watchlist_entity.WatchlistItem) *internal_error.InternalError {
+	if _, err := wr.database.ExecContext(ctx,
+		`INSERT INTO watchlists (user_id, auction_id, timestamp) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, auction_id) DO NOTHING`,
+		item.UserId, item.AuctionId, item.Timestamp.Unix()); err != nil {
+		logger.ErrorContext(ctx, "Error trying to add auction to watchlist", err)
+		return postgres_connection.NewDatabaseError("Error trying to add auction to watchlist", err)
+	}
+
+	return nil
+}
+
+func (wr *WatchlistRepository) RemoveFromWatchlist(
+	ctx context.Context, userId, auctionId string) *internal_error.InternalError {
+	result, err := wr.database.ExecContext(ctx,
+		"DELETE FROM watchlists WHERE user_id = $1 AND auction_id = $2", userId, auctionId)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to remove auction from watchlist", err)
+		return postgres_connection.NewDatabaseError("Error trying to remove auction from watchlist", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Auction %s is not in the watchlist", auctionId))
+	}
+
+	return nil
+}
+
+func (wr *WatchlistRepository) FindWatchlistByUserId(
+	ctx context.Context, userId string) ([]watchlist_entity.WatchlistItem, *internal_error.InternalError) {
+	rows, err := wr.database.QueryContext(ctx,
+		`SELECT user_id, auction_id, timestamp FROM watchlists WHERE user_id = $1
+		ORDER BY timestamp DESC, auction_id`, userId)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find watchlist", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find watchlist", err)
+	}
+	defer rows.Close()
+
+	var items []watchlist_entity.WatchlistItem
+	for rows.Next() {
+		var item watchlist_entity.WatchlistItem
+		var timestamp int64
+		if err := rows.Scan(&item.UserId, &item.AuctionId, &timestamp); err != nil {
+			logger.ErrorContext(ctx, "Error trying to find watchlist", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find watchlist", err)
+		}
+		item.Timestamp = time.Unix(timestamp, 0)
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find watchlist", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find watchlist", err)
+	}
+
+	return items, nil
+}
+
+func (wr *WatchlistRepository) FindWatchersByAuctionId(
+	ctx context.Context, auctionId string) ([]string, *internal_error.InternalError) {
+	rows, err := wr.database.QueryContext(ctx,
+		"SELECT user_id FROM watchlists WHERE auction_id = $1 ORDER BY user_id", auctionId)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find watchers", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find watchers", err)
+	}
+	defer rows.Close()
+
+	var userIds []string
+	for rows.Next() {
+		var userId string
+		if err := rows.Scan(&userId); err != nil {
+			logger.ErrorContext(ctx, "Error trying to find watchers", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find watchers", err)
+		}
+		userIds = append(userIds, userId)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find watchers", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find watchers", err)
+	}
+
+	return userIds, nil
+}
+
+const notificationColumns = "id, user_id, type, auction_id, message, amount, timestamp"
+
+type NotificationRepository struct {
+	database *sql.DB
+}
+
+func NewNotificationRepository(database *sql.DB) *NotificationRepository {
+	return &NotificationRepository{database: database}
+}
+
+func (nr *NotificationRepository) CreateNotification(
+	ctx context.Context, notification *notification_entity.Notification) (bool, *internal_error.InternalError) {
+	result, err := nr.database.ExecContext(ctx,
+		"INSERT INTO notifications ("+notificationColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7)"+
+			" ON CONFLICT (id) DO NOTHING",
+		notification.Id, notification.UserId, string(notification.Type), notification.AuctionId,
+		notification.Message, notification.Amount, notification.Timestamp.Unix())
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert notification", err)
+		return false, postgres_connection.NewDatabaseError("Error trying to insert notification", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert notification", err)
+		return false, postgres_connection.NewDatabaseError("Error trying to insert notification", err)
+	}
+
+	return affected > 0, nil
+}
+
+func (nr *NotificationRepository) FindNotificationsByUserId(
+	ctx context.Context,
+	userId string, limit int64) ([]notification_entity.Notification, *internal_error.InternalError) {
+	rows, err := nr.database.QueryContext(ctx,
+		"SELECT "+notificationColumns+" FROM notifications WHERE user_id = $1"+
+			" ORDER BY timestamp DESC, id DESC LIMIT $2", userId, limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find notifications", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find notifications", err)
+	}
+	defer rows.Close()
+
+	var notifications []notification_entity.Notification
+	for rows.Next() {
+		var notification notification_entity.Notification
+		var notificationType string
+		var timestamp int64
+		if err := rows.Scan(&notification.Id, &notification.UserId, &notificationType,
+			&notification.AuctionId, &notification.Message, &notification.Amount, &timestamp); err != nil {
+			logger.ErrorContext(ctx, "Error trying to find notifications", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find notifications", err)
+		}
+		notification.Type = notification_entity.NotificationType(notificationType)
+		notification.Timestamp = time.Unix(timestamp, 0)
+		notifications = append(notifications, notification)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find notifications", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find notifications", err)
+	}
+
+	return notifications, nil
+}
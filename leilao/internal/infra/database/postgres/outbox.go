@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// execer is satisfied by *sql.DB and *sql.Tx, so the same statements can run
+// on their own or inside the transaction of the state change they belong to.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type OutboxRepository struct {
+	database *sql.DB
+}
+
+func NewOutboxRepository(database *sql.DB) *OutboxRepository {
+	return &OutboxRepository{database: database}
+}
+
+func (or *OutboxRepository) AppendEvent(
+	ctx context.Context, event *event_entity.Event) *internal_error.InternalError {
+	if err := insertEvents(ctx, or.database, event); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert outbox event", err,
+			zap.String("event_type", string(event.Type)),
+			zap.String("aggregate_id", event.AggregateId))
+		return postgres_connection.NewDatabaseError("Error trying to insert outbox event", err)
+	}
+
+	return nil
+}
+
+// insertEvents writes the events with the given executor. Unlike the MongoDB
+// outbox, callers pass their transaction, so an event is stored if and only if
+// the state change it describes is committed.
+func insertEvents(ctx context.Context, executor execer, events ...*event_entity.Event) error {
+	for _, event := range events {
+		if _, err := executor.ExecContext(ctx,
+			`INSERT INTO outbox (id, type, aggregate_id, payload, timestamp)
+			VALUES ($1, $2, $3, $4, $5)`,
+			event.Id, string(event.Type), event.AggregateId, event.Payload,
+			event.Timestamp.UnixNano()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (or *OutboxRepository) FindUnpublishedEvents(
+	ctx context.Context, limit int64) ([]event_entity.Event, *internal_error.InternalError) {
+	rows, err := or.database.QueryContext(ctx,
+		`SELECT id, type, aggregate_id, payload, timestamp FROM outbox
+		WHERE NOT published ORDER BY timestamp LIMIT NULLIF($1, 0)`, limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find unpublished events", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find unpublished events", err)
+	}
+	defer rows.Close()
+
+	var eventEntities []event_entity.Event
+	for rows.Next() {
+		var event event_entity.Event
+		var timestamp int64
+		if err := rows.Scan(&event.Id, &event.Type, &event.AggregateId, &event.Payload, &timestamp); err != nil {
+			logger.ErrorContext(ctx, "Error trying to find unpublished events", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find unpublished events", err)
+		}
+		event.Timestamp = time.Unix(0, timestamp)
+		eventEntities = append(eventEntities, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find unpublished events", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find unpublished events", err)
+	}
+
+	return eventEntities, nil
+}
+
+func (or *OutboxRepository) MarkEventPublished(
+	ctx context.Context, eventId string) *internal_error.InternalError {
+	if _, err := or.database.ExecContext(ctx,
+		"UPDATE outbox SET published = TRUE WHERE id = $1", eventId); err != nil {
+		logger.ErrorContext(ctx, "Error trying to mark event as published", err)
+		return postgres_connection.NewDatabaseError("Error trying to mark event as published", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type ProxyBidRepository struct {
+	database *sql.DB
+}
+
+func NewProxyBidRepository(database *sql.DB) *ProxyBidRepository {
+	return &ProxyBidRepository{database: database}
+}
+
+// CreateProxyBid keeps a single proxy per user and auction: a new maximum
+// replaces the previous one but the original registration time is kept.
+func (pr *ProxyBidRepository) CreateProxyBid(
+	ctx context.Context,
+	proxyBidEntity *proxy_bid_entity.ProxyBid) *internal_error.InternalError {
+	if _, err := pr.database.ExecContext(ctx,
+		`INSERT INTO proxy_bids (id, user_id, auction_id, max_amount, timestamp)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT ON CONSTRAINT proxy_bids_auction_id_user_id
+		DO UPDATE SET max_amount = EXCLUDED.max_amount`,
+		proxyBidEntity.Id, proxyBidEntity.UserId, proxyBidEntity.AuctionId,
+		proxyBidEntity.MaxAmount, proxyBidEntity.Timestamp.Unix()); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert proxy bid", err)
+		return postgres_connection.NewDatabaseError("Error trying to insert proxy bid", err)
+	}
+
+	return nil
+}
+
+func (pr *ProxyBidRepository) FindProxyBidsByAuctionId(
+	ctx context.Context, auctionId string) ([]proxy_bid_entity.ProxyBid, *internal_error.InternalError) {
+	rows, err := pr.database.QueryContext(ctx,
+		`SELECT id, user_id, auction_id, max_amount, timestamp FROM proxy_bids
+		WHERE auction_id = $1 ORDER BY timestamp, id`, auctionId)
+	if err != nil {
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+		return nil, postgres_connection.NewDatabaseError(
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+	}
+	defer rows.Close()
+
+	var proxyBidEntities []proxy_bid_entity.ProxyBid
+	for rows.Next() {
+		var proxyBidEntity proxy_bid_entity.ProxyBid
+		var timestamp int64
+		if err := rows.Scan(&proxyBidEntity.Id, &proxyBidEntity.UserId, &proxyBidEntity.AuctionId,
+			&proxyBidEntity.MaxAmount, &timestamp); err != nil {
+			logger.ErrorContext(ctx,
+				fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+			return nil, postgres_connection.NewDatabaseError(
+				fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+		}
+		proxyBidEntity.Timestamp = time.Unix(timestamp, 0)
+		proxyBidEntities = append(proxyBidEntities, proxyBidEntity)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+		return nil, postgres_connection.NewDatabaseError(
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+	}
+
+	return proxyBidEntities, nil
+}
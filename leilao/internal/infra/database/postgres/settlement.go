@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+type SettlementRepository struct {
+	database *sql.DB
+}
+
+func NewSettlementRepository(database *sql.DB) *SettlementRepository {
+	return &SettlementRepository{database: database}
+}
+
+// CreateSettlement is keyed by the auction id and never overwrites an existing
+// settlement, so replicas closing the same auction settle it only once. The
+// AuctionClosed event is written in the same transaction.
+func (sr *SettlementRepository) CreateSettlement(
+	ctx context.Context,
+	settlementEntity *settlement_entity.Settlement) (bool, *internal_error.InternalError) {
+	event, internalErr := event_entity.CreateEvent(
+		event_entity.AuctionClosed, settlementEntity.AuctionId, settlementEntity.ClosedEvent())
+	if internalErr != nil {
+		return false, internalErr
+	}
+
+	created, err := sr.insertSettlement(ctx, settlementEntity, event)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert settlement", err)
+		return false, postgres_connection.NewDatabaseError("Error trying to insert settlement", err)
+	}
+
+	return created, nil
+}
+
+func (sr *SettlementRepository) insertSettlement(
+	ctx context.Context,
+	settlementEntity *settlement_entity.Settlement, event *event_entity.Event) (bool, error) {
+	tx, err := sr.database.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO settlements (auction_id, seller_id, outcome, winner_id, winning_bid_id,
+		final_price, reserve_price, bid_count, closed_at, settled_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (auction_id) DO NOTHING`,
+		settlementEntity.AuctionId, settlementEntity.SellerId, string(settlementEntity.Outcome),
+		settlementEntity.WinnerId, settlementEntity.WinningBidId, settlementEntity.FinalPrice,
+		settlementEntity.ReservePrice, settlementEntity.BidCount,
+		settlementEntity.ClosedAt.Unix(), settlementEntity.SettledAt.Unix())
+	if err != nil {
+		return false, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	if err := insertEvents(ctx, tx, event); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+const settlementColumns = `auction_id, seller_id, outcome, winner_id, winning_bid_id, final_price,
+	reserve_price, bid_count, closed_at, settled_at`
+
+func (sr *SettlementRepository) FindSettlementByAuctionId(
+	ctx context.Context, auctionId string) (*settlement_entity.Settlement, *internal_error.InternalError) {
+	settlementEntity, err := scanSettlement(sr.database.QueryRowContext(ctx,
+		"SELECT "+settlementColumns+" FROM settlements WHERE auction_id = $1", auctionId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("Settlement not found for auctionId %s", auctionId))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, fmt.Sprintf("Error trying to find settlement by auctionId %s", auctionId), err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find settlement", err)
+	}
+
+	return settlementEntity, nil
+}
+
+// FindSettlements pages through the matching settlements, latest closed first,
+// with a keyset on (closed_at, auction_id).
+func (sr *SettlementRepository) FindSettlements(
+	ctx context.Context,
+	settlementFilter settlement_entity.SettlementFilter) (*settlement_entity.SettlementPage, *internal_error.InternalError) {
+	var where conditions
+	if settlementFilter.WinnerId != "" {
+		where.add("winner_id = $%d", settlementFilter.WinnerId)
+	}
+
+	var stats settlement_entity.SettlementStats
+	if err := sr.database.QueryRowContext(ctx,
+		`SELECT count(*), COALESCE(sum(final_price), 0), COALESCE(avg(final_price), 0),
+		COALESCE(max(final_price), 0) FROM settlements`+where.sql(),
+		where.args...).Scan(&stats.Count, &stats.TotalPrice, &stats.AveragePrice, &stats.HighestPrice); err != nil {
+		logger.ErrorContext(ctx, "Error trying to aggregate settlements", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to aggregate settlements", err)
+	}
+
+	if settlementFilter.Cursor != "" {
+		cursor, err := decodeSettlementCursor(settlementFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+
+		where.args = append(where.args, cursor.ClosedAt, cursor.AuctionId)
+		where.clauses = append(where.clauses, fmt.Sprintf("(closed_at, auction_id) < ($%d, $%d)",
+			len(where.args)-1, len(where.args)))
+	}
+
+	limit := settlementFilter.Limit
+	if limit <= 0 {
+		limit = defaultAuctionPageSize
+	}
+
+	settlementEntities, err := sr.querySettlements(ctx, fmt.Sprintf(
+		"SELECT %s FROM settlements%s ORDER BY closed_at DESC, auction_id DESC LIMIT %d",
+		settlementColumns, where.sql(), limit+1), where.args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find settlements", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find settlements", err)
+	}
+
+	var nextCursor string
+	if len(settlementEntities) > limit {
+		settlementEntities = settlementEntities[:limit]
+		last := settlementEntities[limit-1]
+		nextCursor = encodeSettlementCursor(settlementCursor{ClosedAt: last.ClosedAt.Unix(), AuctionId: last.AuctionId})
+	}
+
+	return &settlement_entity.SettlementPage{
+		Settlements: settlementEntities,
+		Stats:       stats,
+		NextCursor:  nextCursor,
+	}, nil
+}
+
+func (sr *SettlementRepository) querySettlements(
+	ctx context.Context, query string, args ...interface{}) ([]settlement_entity.Settlement, error) {
+	rows, err := sr.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var settlementEntities []settlement_entity.Settlement
+	for rows.Next() {
+		settlementEntity, err := scanSettlement(rows)
+		if err != nil {
+			return nil, err
+		}
+		settlementEntities = append(settlementEntities, *settlementEntity)
+	}
+
+	return settlementEntities, rows.Err()
+}
+
+func scanSettlement(row scanner) (*settlement_entity.Settlement, error) {
+	var settlementEntity settlement_entity.Settlement
+	var closedAt, settledAt int64
+	if err := row.Scan(&settlementEntity.AuctionId, &settlementEntity.SellerId, &settlementEntity.Outcome,
+		&settlementEntity.WinnerId, &settlementEntity.WinningBidId, &settlementEntity.FinalPrice,
+		&settlementEntity.ReservePrice, &settlementEntity.BidCount, &closedAt, &settledAt); err != nil {
+		return nil, err
+	}
+
+	settlementEntity.ClosedAt = time.Unix(closedAt, 0)
+	settlementEntity.SettledAt = time.Unix(settledAt, 0)
+
+	return &settlementEntity, nil
+}
+
+// settlementCursor points at the last settlement of a page by its closing time
+// and auction id.
+type settlementCursor struct {
+	ClosedAt  int64  `json:"t"`
+	AuctionId string `json:"id"`
+}
+
+func encodeSettlementCursor(cursor settlementCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSettlementCursor(value string) (*settlementCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor settlementCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.AuctionId == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
+}
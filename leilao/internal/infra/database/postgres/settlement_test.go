@@ -0,0 +1,14 @@
+package postgres
+
+import (
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+	"testing"
+)
+
+func TestFindSettlementsPagesLatestClosedFirst(t *testing.T) {
+	repositorytest.FindSettlementsPagesLatestClosedFirst(t,
+		func(t *testing.T) settlement_entity.SettlementRepositoryInterface {
+			return NewSettlementRepository(newTestDatabase(t))
+		})
+}
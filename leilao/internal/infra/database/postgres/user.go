@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the SQLSTATE raised when a unique constraint rejects a
+// write.
+const uniqueViolation = "23505"
+
+const selectUser = `SELECT id, name, email, password_hash, role, active, timestamp FROM users`
+
+type UserRepository struct {
+	database *sql.DB
+}
+
+func NewUserRepository(database *sql.DB) *UserRepository {
+	return &UserRepository{database: database}
+}
+
+// CreateUser relies on the unique email constraint instead of checking first,
+// so two concurrent sign-ups with the same email cannot both succeed.
+func (ur *UserRepository) CreateUser(
+	ctx context.Context, userEntity *user_entity.User) *internal_error.InternalError {
+	_, err := ur.database.ExecContext(ctx,
+		`INSERT INTO users (id, name, email, password_hash, role, active, timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		userEntity.Id, userEntity.Name, userEntity.Email, userEntity.PasswordHash,
+		string(userEntity.Role), userEntity.Active, userEntity.Timestamp.Unix())
+	if isUniqueViolation(err, "users_email_key") {
+		return internal_error.NewConflictError("Email is already in use")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert user", err)
+		return postgres_connection.NewDatabaseError("Error trying to insert user", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) UpdateUser(
+	ctx context.Context, userEntity *user_entity.User) *internal_error.InternalError {
+	result, err := ur.database.ExecContext(ctx,
+		"UPDATE users SET name = $2, email = $3, role = $4 WHERE id = $1",
+		userEntity.Id, userEntity.Name, userEntity.Email, string(userEntity.Role))
+	if isUniqueViolation(err, "users_email_key") {
+		return internal_error.NewConflictError("Email is already in use")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to update user", err)
+		return postgres_connection.NewDatabaseError("Error trying to update user", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewNotFoundError("User not found")
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) DeactivateUser(
+	ctx context.Context, userId string) *internal_error.InternalError {
+	result, err := ur.database.ExecContext(ctx,
+		"UPDATE users SET active = FALSE WHERE id = $1", userId)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to deactivate user", err)
+		return postgres_connection.NewDatabaseError("Error trying to deactivate user", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewNotFoundError("User not found")
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	userEntity, err := scanUser(ur.database.QueryRowContext(ctx, selectUser+" WHERE id = $1", userId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("User not found with this id = %s", userId))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find user by userId", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find user by userId", err)
+	}
+
+	return userEntity, nil
+}
+
+func (ur *UserRepository) FindUserByEmail(
+	ctx context.Context, email string) (*user_entity.User, *internal_error.InternalError) {
+	userEntity, err := scanUser(ur.database.QueryRowContext(ctx, selectUser+" WHERE email = $1", email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError("User not found with this email")
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find user by email", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find user by email", err)
+	}
+
+	return userEntity, nil
+}
+
+func (ur *UserRepository) FindUsers(
+	ctx context.Context, onlyActive bool) ([]user_entity.User, *internal_error.InternalError) {
+	query := selectUser + " ORDER BY timestamp, id"
+	if onlyActive {
+		query = selectUser + " WHERE active ORDER BY timestamp, id"
+	}
+
+	rows, err := ur.database.QueryContext(ctx, query)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error finding users", err)
+		return nil, postgres_connection.NewDatabaseError("Error finding users", err)
+	}
+	defer rows.Close()
+
+	var usersEntity []user_entity.User
+	for rows.Next() {
+		userEntity, err := scanUser(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error decoding users", err)
+			return nil, postgres_connection.NewDatabaseError("Error decoding users", err)
+		}
+		usersEntity = append(usersEntity, *userEntity)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error decoding users", err)
+		return nil, postgres_connection.NewDatabaseError("Error decoding users", err)
+	}
+
+	return usersEntity, nil
+}
+
+// scanner is satisfied by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row scanner) (*user_entity.User, error) {
+	var userEntity user_entity.User
+	var timestamp int64
+	if err := row.Scan(&userEntity.Id, &userEntity.Name, &userEntity.Email,
+		&userEntity.PasswordHash, &userEntity.Role, &userEntity.Active, &timestamp); err != nil {
+		return nil, err
+	}
+	userEntity.Timestamp = time.Unix(timestamp, 0)
+
+	return &userEntity, nil
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == constraint
+}
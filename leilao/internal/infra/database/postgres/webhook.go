@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	postgres_connection "fullcycle-auction_go/configuration/database/postgres"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const subscriptionColumns = "id, url, secret, event_types, timestamp"
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_error, last_status_code, timestamp, delivered_at`
+
+type SubscriptionRepository struct {
+	database *sql.DB
+}
+
+func NewSubscriptionRepository(database *sql.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{database: database}
+}
+
+func (sr *SubscriptionRepository) CreateSubscription(
+	ctx context.Context, subscription *webhook_entity.Subscription) *internal_error.InternalError {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	if _, err := sr.database.ExecContext(ctx,
+		"INSERT INTO webhook_subscriptions ("+subscriptionColumns+") VALUES ($1, $2, $3, $4, $5)",
+		subscription.Id, subscription.Url, subscription.Secret,
+		pq.Array(eventTypes), subscription.Timestamp.Unix()); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert webhook subscription", err)
+		return postgres_connection.NewDatabaseError("Error trying to insert webhook subscription", err)
+	}
+
+	return nil
+}
+
+func (sr *SubscriptionRepository) FindSubscriptions(
+	ctx context.Context) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	return sr.findSubscriptions(ctx,
+		"SELECT "+subscriptionColumns+" FROM webhook_subscriptions ORDER BY timestamp, id")
+}
+
+func (sr *SubscriptionRepository) FindSubscriptionsByEventType(
+	ctx context.Context,
+	eventType event_entity.EventType) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	return sr.findSubscriptions(ctx,
+		"SELECT "+subscriptionColumns+" FROM webhook_subscriptions WHERE $1 = ANY(event_types) ORDER BY timestamp, id",
+		string(eventType))
+}
+
+func (sr *SubscriptionRepository) FindSubscriptionById(
+	ctx context.Context, subscriptionId string) (*webhook_entity.Subscription, *internal_error.InternalError) {
+	subscription, err := scanSubscription(sr.database.QueryRowContext(ctx,
+		"SELECT "+subscriptionColumns+" FROM webhook_subscriptions WHERE id = $1", subscriptionId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("Webhook not found with this id = %s", subscriptionId))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook subscription", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find webhook subscription", err)
+	}
+
+	return subscription, nil
+}
+
+func (sr *SubscriptionRepository) DeleteSubscription(
+	ctx context.Context, subscriptionId string) *internal_error.InternalError {
+	result, err := sr.database.ExecContext(ctx,
+		"DELETE FROM webhook_subscriptions WHERE id = $1", subscriptionId)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to delete webhook subscription", err)
+		return postgres_connection.NewDatabaseError("Error trying to delete webhook subscription", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Webhook not found with this id = %s", subscriptionId))
+	}
+
+	return nil
+}
+
+func (sr *SubscriptionRepository) findSubscriptions(
+	ctx context.Context,
+	query string, args ...interface{}) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	rows, err := sr.database.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook subscriptions", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find webhook subscriptions", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []webhook_entity.Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find webhook subscriptions", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find webhook subscriptions", err)
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook subscriptions", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find webhook subscriptions", err)
+	}
+
+	return subscriptions, nil
+}
+
+func scanSubscription(row scanner) (*webhook_entity.Subscription, error) {
+	var subscription webhook_entity.Subscription
+	var eventTypes []string
+	var timestamp int64
+	if err := row.Scan(&subscription.Id, &subscription.Url, &subscription.Secret,
+		pq.Array(&eventTypes), &timestamp); err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, event_entity.EventType(eventType))
+	}
+	subscription.Timestamp = time.Unix(timestamp, 0)
+
+	return &subscription, nil
+}
+
+type DeliveryRepository struct {
+	database *sql.DB
+}
+
+func NewDeliveryRepository(database *sql.DB) *DeliveryRepository {
+	return &DeliveryRepository{database: database}
+}
+
+func (dr *DeliveryRepository) CreateDelivery(
+	ctx context.Context, delivery *webhook_entity.Delivery) *internal_error.InternalError {
+	if _, err := dr.database.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload,
+		status, attempts, next_attempt_at, timestamp)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO NOTHING`,
+		delivery.Id, delivery.SubscriptionId, delivery.EventId, string(delivery.EventType),
+		delivery.Payload, string(delivery.Status), delivery.Attempts,
+		delivery.NextAttemptAt.Unix(), delivery.Timestamp.Unix()); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert webhook delivery", err)
+		return postgres_connection.NewDatabaseError("Error trying to insert webhook delivery", err)
+	}
+
+	return nil
+}
+
+// ClaimDueDelivery pushes the next attempt of the most overdue delivery to
+// leaseUntil. SKIP LOCKED lets concurrent dispatchers claim different
+// deliveries instead of waiting on the same row.
+func (dr *DeliveryRepository) ClaimDueDelivery(
+	ctx context.Context,
+	now, leaseUntil time.Time) (*webhook_entity.Delivery, *internal_error.InternalError) {
+	delivery, err := scanDelivery(dr.database.QueryRowContext(ctx,
+		`UPDATE webhook_deliveries SET next_attempt_at = $3 WHERE id = (
+			SELECT id FROM webhook_deliveries WHERE status = $1 AND next_attempt_at <= $2
+			ORDER BY next_attempt_at LIMIT 1 FOR UPDATE SKIP LOCKED)
+		RETURNING `+deliveryColumns,
+		string(webhook_entity.Pending), now.Unix(), leaseUntil.Unix()))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to claim webhook delivery", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to claim webhook delivery", err)
+	}
+
+	return delivery, nil
+}
+
+func (dr *DeliveryRepository) UpdateDelivery(
+	ctx context.Context, delivery *webhook_entity.Delivery) *internal_error.InternalError {
+	if _, err := dr.database.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET status = $2, attempts = $3, next_attempt_at = $4,
+		last_error = $5, last_status_code = $6, delivered_at = $7 WHERE id = $1`,
+		delivery.Id, string(delivery.Status), delivery.Attempts, delivery.NextAttemptAt.Unix(),
+		delivery.LastError, delivery.LastStatusCode, unixOrZero(delivery.DeliveredAt)); err != nil {
+		logger.ErrorContext(ctx, "Error trying to update webhook delivery", err)
+		return postgres_connection.NewDatabaseError("Error trying to update webhook delivery", err)
+	}
+
+	return nil
+}
+
+func (dr *DeliveryRepository) FindDeliveriesByStatus(
+	ctx context.Context,
+	status webhook_entity.DeliveryStatus, limit int64) ([]webhook_entity.Delivery, *internal_error.InternalError) {
+	rows, err := dr.database.QueryContext(ctx,
+		"SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE status = $1 ORDER BY timestamp DESC LIMIT NULLIF($2, 0)",
+		string(status), limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook deliveries", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find webhook deliveries", err)
+	}
+	defer rows.Close()
+
+	var deliveries []webhook_entity.Delivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error trying to find webhook deliveries", err)
+			return nil, postgres_connection.NewDatabaseError("Error trying to find webhook deliveries", err)
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook deliveries", err)
+		return nil, postgres_connection.NewDatabaseError("Error trying to find webhook deliveries", err)
+	}
+
+	return deliveries, nil
+}
+
+// RetryDeadDelivery moves a dead-lettered delivery back to the queue with a
+// fresh attempt budget.
+func (dr *DeliveryRepository) RetryDeadDelivery(
+	ctx context.Context, deliveryId string) *internal_error.InternalError {
+	result, err := dr.database.ExecContext(ctx,
+		`UPDATE webhook_deliveries SET status = $2, attempts = 0, next_attempt_at = $3
+		WHERE id = $1 AND status = $4`,
+		deliveryId, string(webhook_entity.Pending), time.Now().Unix(), string(webhook_entity.Dead))
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to retry webhook delivery", err)
+		return postgres_connection.NewDatabaseError("Error trying to retry webhook delivery", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Dead-lettered delivery not found with this id = %s", deliveryId))
+	}
+
+	return nil
+}
+
+func scanDelivery(row scanner) (*webhook_entity.Delivery, error) {
+	var delivery webhook_entity.Delivery
+	var nextAttemptAt, timestamp, deliveredAt int64
+	if err := row.Scan(&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &delivery.EventType,
+		&delivery.Payload, &delivery.Status, &delivery.Attempts, &nextAttemptAt,
+		&delivery.LastError, &delivery.LastStatusCode, &timestamp, &deliveredAt); err != nil {
+		return nil, err
+	}
+
+	delivery.NextAttemptAt = time.Unix(nextAttemptAt, 0)
+	delivery.Timestamp = time.Unix(timestamp, 0)
+	if deliveredAt != 0 {
+		delivery.DeliveredAt = time.Unix(deliveredAt, 0)
+	}
+
+	return &delivery, nil
+}
+
+func unixOrZero(value time.Time) int64 {
+	if value.IsZero() {
+		return 0
+	}
+	return value.Unix()
+}
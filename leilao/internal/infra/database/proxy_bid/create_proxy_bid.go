@@ -0,0 +1,57 @@
+package proxy_bid
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProxyBidEntityMongo struct {
+	Id        string  `bson:"_id"`
+	UserId    string  `bson:"user_id"`
+	AuctionId string  `bson:"auction_id"`
+	MaxAmount float64 `bson:"max_amount"`
+	Timestamp int64   `bson:"timestamp"`
+}
+
+type ProxyBidRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewProxyBidRepository(database *mongo.Database) *ProxyBidRepository {
+	return &ProxyBidRepository{
+		Collection: database.Collection("proxy_bids"),
+	}
+}
+
+// CreateProxyBid keeps a single proxy per user and auction: a new maximum
+// replaces the previous one but the original registration time is kept.
+func (pr *ProxyBidRepository) CreateProxyBid(
+	ctx context.Context,
+	proxyBidEntity *proxy_bid_entity.ProxyBid) *internal_error.InternalError {
+	filter := bson.M{
+		"user_id":    proxyBidEntity.UserId,
+		"auction_id": proxyBidEntity.AuctionId,
+	}
+	update := bson.M{
+		"$set": bson.M{"max_amount": proxyBidEntity.MaxAmount},
+		"$setOnInsert": bson.M{
+			"_id":       proxyBidEntity.Id,
+			"timestamp": proxyBidEntity.Timestamp.Unix(),
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := pr.Collection.UpdateOne(ctx, filter, update, opts); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert proxy bid", err)
+		return mongodb.NewDatabaseError("Error trying to insert proxy bid", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package proxy_bid
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (pr *ProxyBidRepository) FindProxyBidsByAuctionId(
+	ctx context.Context, auctionId string) ([]proxy_bid_entity.ProxyBid, *internal_error.InternalError) {
+	filter := bson.M{"auction_id": auctionId}
+
+	cursor, err := pr.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+		return nil, mongodb.NewDatabaseError(
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+	}
+	defer cursor.Close(ctx)
+
+	var proxyBidEntitiesMongo []ProxyBidEntityMongo
+	if err := cursor.All(ctx, &proxyBidEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx,
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+		return nil, mongodb.NewDatabaseError(
+			fmt.Sprintf("Error trying to find proxy bids by auctionId %s", auctionId), err)
+	}
+
+	var proxyBidEntities []proxy_bid_entity.ProxyBid
+	for _, proxyBidEntityMongo := range proxyBidEntitiesMongo {
+		proxyBidEntities = append(proxyBidEntities, proxy_bid_entity.ProxyBid{
+			Id:        proxyBidEntityMongo.Id,
+			UserId:    proxyBidEntityMongo.UserId,
+			AuctionId: proxyBidEntityMongo.AuctionId,
+			MaxAmount: proxyBidEntityMongo.MaxAmount,
+			Timestamp: time.Unix(proxyBidEntityMongo.Timestamp, 0),
+		})
+	}
+
+	return proxyBidEntities, nil
+}
@@ -0,0 +1,91 @@
+// Package repositorytest holds the checks every repository backend has to
+// pass. Each backend calls them from its own tests with a constructor that
+// stores the given fixture.
+package repositorytest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewAuctionRepository returns a repository holding the given auctions.
+type NewAuctionRepository func(
+	t *testing.T, auctions []auction_entity.Auction) auction_entity.AuctionRepositoryInterface
+
+// FindAuctionsByPriceLeavesOutActiveSealedAuctions pages by current price
+// through auctions of a fresh category and checks that neither the pages nor
+// their cursors, decoded like a client would, reveal the hidden price of an
+// active sealed auction.
+func FindAuctionsByPriceLeavesOutActiveSealedAuctions(t *testing.T, newRepository NewAuctionRepository) {
+	category := uuid.New().String()
+	now := time.Now()
+	fixture := []auction_entity.Auction{
+		{Format: auction_entity.English, Status: auction_entity.Active, CurrentPrice: 10},
+		{Format: auction_entity.SealedFirstPrice, Status: auction_entity.Active, CurrentPrice: 999},
+		{Format: auction_entity.English, Status: auction_entity.Active, CurrentPrice: 20},
+		{Format: auction_entity.SealedSecondPrice, Status: auction_entity.Completed, CurrentPrice: 30},
+	}
+	for i := range fixture {
+		fixture[i].Id = uuid.New().String()
+		fixture[i].ProductName = "Guitar"
+		fixture[i].Category = category
+		fixture[i].Description = "Vintage electric guitar"
+		fixture[i].Condition = auction_entity.Used
+		fixture[i].Timestamp = now
+		fixture[i].EndTime = now.Add(time.Hour)
+	}
+	sealed := fixture[1]
+
+	auctionRepository := newRepository(t, fixture)
+
+	var auctionIds []string
+	filter := auction_entity.AuctionFilter{
+		Category: category,
+		SortBy:   auction_entity.SortByCurrentPrice,
+		Limit:    1,
+	}
+	for {
+		page, err := auctionRepository.FindAuctions(context.Background(), filter)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if page.Total != 3 {
+			t.Errorf("Expected the active sealed auction not to be counted, got %d", page.Total)
+		}
+
+		for _, auctionEntity := range page.Auctions {
+			auctionIds = append(auctionIds, auctionEntity.Id)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+
+		data, decodeErr := base64.RawURLEncoding.DecodeString(page.NextCursor)
+		if decodeErr != nil {
+			t.Fatalf("Unexpected error: %v", decodeErr)
+		}
+		var cursor map[string]interface{}
+		if decodeErr := json.Unmarshal(data, &cursor); decodeErr != nil {
+			t.Fatalf("Unexpected error: %v", decodeErr)
+		}
+		for _, value := range cursor {
+			if value == sealed.Id || value == sealed.CurrentPrice {
+				t.Errorf("Expected the cursor not to reveal the sealed auction, got %s", data)
+			}
+		}
+
+		filter.Cursor = page.NextCursor
+	}
+
+	expected := []string{fixture[0].Id, fixture[2].Id, fixture[3].Id}
+	if !reflect.DeepEqual(auctionIds, expected) {
+		t.Errorf("Expected %v, got %v", expected, auctionIds)
+	}
+}
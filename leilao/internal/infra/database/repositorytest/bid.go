@@ -0,0 +1,105 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewBidRepository returns a repository holding the given bids.
+type NewBidRepository func(t *testing.T, bids []bid_entity.Bid) bid_entity.BidEntityRepository
+
+// FindBidsPagesWithoutOverlapOrGap stores three bids in the same second, so
+// the pages have to break the tie by id, and checks that the stats cover every
+// match on every page.
+func FindBidsPagesWithoutOverlapOrGap(t *testing.T, newRepository NewBidRepository) {
+	prefix := uuid.New().String()
+	auctionId, otherAuctionId := uuid.New().String(), uuid.New().String()
+	alice, bob, carol := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	start := time.Unix(1700000000, 0)
+	fixture := []bid_entity.Bid{
+		{Id: prefix + "a", AuctionId: auctionId, UserId: alice, Amount: 10, Timestamp: start},
+		{Id: prefix + "d", AuctionId: auctionId, UserId: bob, Amount: 20, Timestamp: start.Add(time.Second)},
+		{Id: prefix + "b", AuctionId: auctionId, UserId: alice, Amount: 30, Timestamp: start.Add(time.Second)},
+		{Id: prefix + "c", AuctionId: auctionId, UserId: carol, Amount: 40, Timestamp: start.Add(time.Second)},
+		{Id: prefix + "e", AuctionId: auctionId, UserId: bob, Amount: 50, Timestamp: start.Add(2 * time.Second)},
+		{Id: prefix + "f", AuctionId: otherAuctionId, UserId: alice, Amount: 60, Timestamp: start.Add(3 * time.Second)},
+	}
+
+	bidRepository := newRepository(t, fixture)
+
+	auctionStats := bid_entity.BidStats{
+		Count:         5,
+		Bidders:       3,
+		Auctions:      1,
+		HighestAmount: 50,
+		AverageAmount: 30,
+		FirstBidAt:    start,
+		LastBidAt:     start.Add(2 * time.Second),
+	}
+
+	tests := []struct {
+		name     string
+		filter   bid_entity.BidFilter
+		expected []string
+		stats    bid_entity.BidStats
+	}{
+		{"ascending", bid_entity.BidFilter{AuctionId: auctionId, Limit: 2},
+			[]string{"a", "b", "c", "d", "e"}, auctionStats},
+		{"descending", bid_entity.BidFilter{AuctionId: auctionId, Limit: 2, SortDescending: true},
+			[]string{"e", "d", "c", "b", "a"}, auctionStats},
+		{"by user", bid_entity.BidFilter{UserId: alice, Limit: 1},
+			[]string{"a", "b", "f"}, bid_entity.BidStats{
+				Count:         3,
+				Bidders:       1,
+				Auctions:      2,
+				HighestAmount: 60,
+				AverageAmount: 100.0 / 3,
+				FirstBidAt:    start,
+				LastBidAt:     start.Add(3 * time.Second),
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			filter := tt.filter
+			for {
+				page, err := bidRepository.FindBids(context.Background(), filter)
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(page.Stats, tt.stats) {
+					t.Errorf("Expected stats %+v on every page, got %+v", tt.stats, page.Stats)
+				}
+
+				for _, bid := range page.Bids {
+					ids = append(ids, strings.TrimPrefix(bid.Id, prefix))
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := bidRepository.FindBids(context.Background(),
+			bid_entity.BidFilter{AuctionId: auctionId, Cursor: cursor})
+		if err == nil || rest_err.ConvertError(err).Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected cursor %q to answer 422, got %v", cursor, err)
+		}
+	}
+}
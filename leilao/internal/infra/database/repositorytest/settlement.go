@@ -0,0 +1,79 @@
+package repositorytest
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NewSettlementRepository returns an empty settlement repository.
+type NewSettlementRepository func(t *testing.T) settlement_entity.SettlementRepositoryInterface
+
+// FindSettlementsPagesLatestClosedFirst stores three settlements closed in the
+// same second, so the pages have to break the tie by auction id, and checks
+// that the stats cover every match on every page.
+func FindSettlementsPagesLatestClosedFirst(t *testing.T, newRepository NewSettlementRepository) {
+	settlementRepository := newRepository(t)
+	ctx := context.Background()
+
+	prefix := uuid.New().String()
+	winnerId := uuid.New().String()
+	closedAt := time.Unix(1700000000, 0)
+	for _, settlementEntity := range []settlement_entity.Settlement{
+		{AuctionId: prefix + "a", WinnerId: winnerId, FinalPrice: 10, ClosedAt: closedAt},
+		{AuctionId: prefix + "b", WinnerId: winnerId, FinalPrice: 20, ClosedAt: closedAt.Add(time.Second)},
+		{AuctionId: prefix + "c", WinnerId: winnerId, FinalPrice: 30, ClosedAt: closedAt.Add(time.Second)},
+		{AuctionId: prefix + "d", WinnerId: winnerId, FinalPrice: 40, ClosedAt: closedAt.Add(time.Second)},
+		{AuctionId: prefix + "e", WinnerId: uuid.New().String(), FinalPrice: 50, ClosedAt: closedAt.Add(2 * time.Second)},
+	} {
+		settlementEntity := settlementEntity
+		settlementEntity.Outcome = settlement_entity.Sold
+		settlementEntity.SettledAt = closedAt
+		if _, err := settlementRepository.CreateSettlement(ctx, &settlementEntity); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	expectedStats := settlement_entity.SettlementStats{
+		Count: 4, TotalPrice: 100, AveragePrice: 25, HighestPrice: 40,
+	}
+
+	var auctionIds []string
+	filter := settlement_entity.SettlementFilter{WinnerId: winnerId, Limit: 2}
+	for {
+		page, err := settlementRepository.FindSettlements(ctx, filter)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(page.Stats, expectedStats) {
+			t.Errorf("Expected stats %+v on every page, got %+v", expectedStats, page.Stats)
+		}
+
+		for _, settlementEntity := range page.Settlements {
+			auctionIds = append(auctionIds, strings.TrimPrefix(settlementEntity.AuctionId, prefix))
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	if expected := []string{"d", "c", "b", "a"}; !reflect.DeepEqual(auctionIds, expected) {
+		t.Errorf("Expected %v, got %v", expected, auctionIds)
+	}
+
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		_, err := settlementRepository.FindSettlements(ctx,
+			settlement_entity.SettlementFilter{WinnerId: winnerId, Cursor: cursor})
+		if err == nil || rest_err.ConvertError(err).Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected cursor %q to answer 422, got %v", cursor, err)
+		}
+	}
+}
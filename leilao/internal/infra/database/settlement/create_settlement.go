@@ -0,0 +1,74 @@
+package settlement
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/infra/database/outbox"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SettlementEntityMongo struct {
+	AuctionId    string                              `bson:"_id"`
+	SellerId     string                              `bson:"seller_id"`
+	Outcome      settlement_entity.SettlementOutcome `bson:"outcome"`
+	WinnerId     string                              `bson:"winner_id"`
+	WinningBidId string                              `bson:"winning_bid_id"`
+	FinalPrice   float64                             `bson:"final_price"`
+	ReservePrice float64                             `bson:"reserve_price"`
+	BidCount     int64                               `bson:"bid_count"`
+	ClosedAt     int64                               `bson:"closed_at"`
+	SettledAt    int64                               `bson:"settled_at"`
+}
+
+type SettlementRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewSettlementRepository(database *mongo.Database) *SettlementRepository {
+	return &SettlementRepository{
+		Collection: database.Collection("settlements"),
+	}
+}
+
+// CreateSettlement is keyed by the auction id and never overwrites an existing
+// settlement, so replicas closing the same auction settle it only once. The
+// "auction closed" event is inserted along with the settlement.
+func (sr *SettlementRepository) CreateSettlement(
+	ctx context.Context,
+	settlementEntity *settlement_entity.Settlement) (bool, *internal_error.InternalError) {
+	closedEvent, eventErr := outbox.NewPendingEvent(
+		event_entity.AuctionClosed, settlementEntity.AuctionId, settlementEntity.ClosedEvent())
+	if eventErr != nil {
+		return false, eventErr
+	}
+
+	filter := bson.M{"_id": settlementEntity.AuctionId}
+	update := bson.M{"$setOnInsert": bson.M{
+		"seller_id":      settlementEntity.SellerId,
+		"outcome":        settlementEntity.Outcome,
+		"winner_id":      settlementEntity.WinnerId,
+		"winning_bid_id": settlementEntity.WinningBidId,
+		"final_price":    settlementEntity.FinalPrice,
+		"reserve_price":  settlementEntity.ReservePrice,
+		"bid_count":      settlementEntity.BidCount,
+		"closed_at":      settlementEntity.ClosedAt.Unix(),
+		"settled_at":     settlementEntity.SettledAt.Unix(),
+		"outbox":         bson.A{closedEvent},
+	}}
+
+	opts := options.Update().SetUpsert(true)
+	result, err := sr.Collection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert settlement", err)
+		return false, mongodb.NewDatabaseError("Error trying to insert settlement", err)
+	}
+
+	return result.UpsertedCount > 0, nil
+}
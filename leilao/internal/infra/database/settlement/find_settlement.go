@@ -0,0 +1,194 @@
+package settlement
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultSettlementPageSize = 20
+
+// settlementCursor points at the last settlement of a page by its closing time
+// and auction id.
+type settlementCursor struct {
+	ClosedAt  int64  `json:"t"`
+	AuctionId string `json:"id"`
+}
+
+func (sr *SettlementRepository) FindSettlementByAuctionId(
+	ctx context.Context, auctionId string) (*settlement_entity.Settlement, *internal_error.InternalError) {
+	filter := bson.M{"_id": auctionId}
+
+	var settlementEntityMongo SettlementEntityMongo
+	if err := sr.Collection.FindOne(ctx, filter).Decode(&settlementEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Settlement not found for auctionId %s", auctionId))
+		}
+
+		logger.ErrorContext(ctx, fmt.Sprintf("Error trying to find settlement by auctionId %s", auctionId), err)
+		return nil, mongodb.NewDatabaseError("Error trying to find settlement", err)
+	}
+
+	settlementEntity := toSettlementEntity(settlementEntityMongo)
+	return &settlementEntity, nil
+}
+
+// FindSettlements pages through the matching settlements, latest closed first,
+// and aggregates their stats over every match.
+func (sr *SettlementRepository) FindSettlements(
+	ctx context.Context,
+	settlementFilter settlement_entity.SettlementFilter) (*settlement_entity.SettlementPage, *internal_error.InternalError) {
+	filter := bson.M{}
+	if settlementFilter.WinnerId != "" {
+		filter["winner_id"] = settlementFilter.WinnerId
+	}
+
+	stats, err := sr.findSettlementStats(ctx, filter)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to aggregate settlements", err)
+		return nil, mongodb.NewDatabaseError("Error trying to aggregate settlements", err)
+	}
+
+	pageFilter := filter
+	if settlementFilter.Cursor != "" {
+		cursor, err := decodeSettlementCursor(settlementFilter.Cursor)
+		if err != nil {
+			return nil, internal_error.NewValidationError("Invalid pagination cursor",
+				internal_error.Cause{Field: "cursor", Message: "is not a valid cursor"})
+		}
+
+		pageFilter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{"closed_at": bson.M{"$lt": cursor.ClosedAt}},
+			bson.M{"closed_at": cursor.ClosedAt, "_id": bson.M{"$lt": cursor.AuctionId}},
+		}}}}
+	}
+
+	limit := settlementFilter.Limit
+	if limit <= 0 {
+		limit = defaultSettlementPageSize
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "closed_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit + 1))
+
+	cursor, err := sr.Collection.Find(ctx, pageFilter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find settlements", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find settlements", err)
+	}
+	defer cursor.Close(ctx)
+
+	var settlementEntitiesMongo []SettlementEntityMongo
+	if err := cursor.All(ctx, &settlementEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find settlements", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find settlements", err)
+	}
+
+	var nextCursor string
+	if len(settlementEntitiesMongo) > limit {
+		settlementEntitiesMongo = settlementEntitiesMongo[:limit]
+		last := settlementEntitiesMongo[limit-1]
+		nextCursor = encodeSettlementCursor(settlementCursor{ClosedAt: last.ClosedAt, AuctionId: last.AuctionId})
+	}
+
+	var settlementEntities []settlement_entity.Settlement
+	for _, settlementEntityMongo := range settlementEntitiesMongo {
+		settlementEntities = append(settlementEntities, toSettlementEntity(settlementEntityMongo))
+	}
+
+	return &settlement_entity.SettlementPage{
+		Settlements: settlementEntities,
+		Stats:       *stats,
+		NextCursor:  nextCursor,
+	}, nil
+}
+
+func (sr *SettlementRepository) findSettlementStats(
+	ctx context.Context, filter bson.M) (*settlement_entity.SettlementStats, error) {
+	cursor, err := sr.Collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           nil,
+			"count":         bson.M{"$sum": 1},
+			"total_price":   bson.M{"$sum": "$final_price"},
+			"average_price": bson.M{"$avg": "$final_price"},
+			"highest_price": bson.M{"$max": "$final_price"},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Count        int64   `bson:"count"`
+		TotalPrice   float64 `bson:"total_price"`
+		AveragePrice float64 `bson:"average_price"`
+		HighestPrice float64 `bson:"highest_price"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return &settlement_entity.SettlementStats{}, nil
+	}
+
+	return &settlement_entity.SettlementStats{
+		Count:        results[0].Count,
+		TotalPrice:   results[0].TotalPrice,
+		AveragePrice: results[0].AveragePrice,
+		HighestPrice: results[0].HighestPrice,
+	}, nil
+}
+
+func toSettlementEntity(settlementEntityMongo SettlementEntityMongo) settlement_entity.Settlement {
+	return settlement_entity.Settlement{
+		AuctionId:    settlementEntityMongo.AuctionId,
+		SellerId:     settlementEntityMongo.SellerId,
+		Outcome:      settlementEntityMongo.Outcome,
+		WinnerId:     settlementEntityMongo.WinnerId,
+		WinningBidId: settlementEntityMongo.WinningBidId,
+		FinalPrice:   settlementEntityMongo.FinalPrice,
+		ReservePrice: settlementEntityMongo.ReservePrice,
+		BidCount:     settlementEntityMongo.BidCount,
+		ClosedAt:     time.Unix(settlementEntityMongo.ClosedAt, 0),
+		SettledAt:    time.Unix(settlementEntityMongo.SettledAt, 0),
+	}
+}
+
+func encodeSettlementCursor(cursor settlementCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSettlementCursor(value string) (*settlementCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor settlementCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+
+	if cursor.AuctionId == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,51 @@
+package settlement
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/infra/database/repositorytest"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSettlementCursorRoundTrip(t *testing.T) {
+	cursor := settlementCursor{ClosedAt: 1700000000, AuctionId: "auction"}
+
+	decoded, err := decodeSettlementCursor(encodeSettlementCursor(cursor))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *decoded != cursor {
+		t.Errorf("Expected %+v, got %+v", cursor, *decoded)
+	}
+}
+
+// TestFindSettlementsPagesLatestClosedFirst uses a fresh database of the
+// MongoDB at MONGODB_URL.
+func TestFindSettlementsPagesLatestClosedFirst(t *testing.T) {
+	repositorytest.FindSettlementsPagesLatestClosedFirst(t,
+		func(t *testing.T) settlement_entity.SettlementRepositoryInterface {
+			mongoURL := os.Getenv("MONGODB_URL")
+			if mongoURL == "" {
+				t.Skip("MONGODB_URL is not set")
+			}
+
+			ctx := context.Background()
+			client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			database := client.Database("auctions_test_" + uuid.New().String()[:8])
+			t.Cleanup(func() {
+				database.Drop(ctx)
+				client.Disconnect(ctx)
+			})
+
+			return NewSettlementRepository(database)
+		})
+}
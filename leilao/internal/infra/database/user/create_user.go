@@ -0,0 +1,99 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (ur *UserRepository) CreateUser(
+	ctx context.Context, userEntity *user_entity.User) *internal_error.InternalError {
+	if err := ur.checkEmailAvailable(ctx, userEntity); err != nil {
+		return err
+	}
+
+	userEntityMongo := &UserEntityMongo{
+		Id:           userEntity.Id,
+		Name:         userEntity.Name,
+		Email:        userEntity.Email,
+		PasswordHash: userEntity.PasswordHash,
+		Role:         string(userEntity.Role),
+		Deactivated:  !userEntity.Active,
+		Timestamp:    userEntity.Timestamp.Unix(),
+	}
+
+	if _, err := ur.Collection.InsertOne(ctx, userEntityMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert user", err)
+		return mongodb.NewDatabaseError("Error trying to insert user", err)
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) UpdateUser(
+	ctx context.Context, userEntity *user_entity.User) *internal_error.InternalError {
+	if err := ur.checkEmailAvailable(ctx, userEntity); err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": userEntity.Id}
+	update := bson.M{"$set": bson.M{
+		"name":  userEntity.Name,
+		"email": userEntity.Email,
+		"role":  string(userEntity.Role),
+	}}
+
+	result, err := ur.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to update user", err)
+		return mongodb.NewDatabaseError("Error trying to update user", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("User not found")
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) DeactivateUser(
+	ctx context.Context, userId string) *internal_error.InternalError {
+	filter := bson.M{"_id": userId}
+	update := bson.M{"$set": bson.M{"deactivated": true}}
+
+	result, err := ur.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to deactivate user", err)
+		return mongodb.NewDatabaseError("Error trying to deactivate user", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError("User not found")
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) checkEmailAvailable(
+	ctx context.Context, userEntity *user_entity.User) *internal_error.InternalError {
+	filter := bson.M{"email": userEntity.Email, "_id": bson.M{"$ne": userEntity.Id}}
+
+	var userEntityMongo UserEntityMongo
+	err := ur.Collection.FindOne(ctx, filter).Decode(&userEntityMongo)
+	if err == nil {
+		return internal_error.NewConflictError("Email is already in use")
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		logger.ErrorContext(ctx, "Error trying to find user by email", err)
+		return mongodb.NewDatabaseError("Error trying to find user by email", err)
+	}
+
+	return nil
+}
@@ -4,17 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity/user_entity"
 	"fullcycle-auction_go/internal/internal_error"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type UserEntityMongo struct {
-	Id   string `bson:"_id"`
-	Name string `bson:"name"`
+	Id           string `bson:"_id"`
+	Name         string `bson:"name"`
+	Email        string `bson:"email"`
+	PasswordHash string `bson:"password_hash"`
+	Role         string `bson:"role"`
+	Deactivated  bool   `bson:"deactivated"`
+	Timestamp    int64  `bson:"timestamp"`
 }
 
 type UserRepository struct {
@@ -35,19 +42,81 @@ func (ur *UserRepository) FindUserById(
 	err := ur.Collection.FindOne(ctx, filter).Decode(&userEntityMongo)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			logger.Error(fmt.Sprintf("User not found with this id = %d", userId), err)
+			logger.ErrorContext(ctx, fmt.Sprintf("User not found with this id = %s", userId), err)
 			return nil, internal_error.NewNotFoundError(
-				fmt.Sprintf("User not found with this id = %d", userId))
+				fmt.Sprintf("User not found with this id = %s", userId))
 		}
 
-		logger.Error("Error trying to find user by userId", err)
-		return nil, internal_error.NewInternalServerError("Error trying to find user by userId")
+		logger.ErrorContext(ctx, "Error trying to find user by userId", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find user by userId", err)
 	}
 
-	userEntity := &user_entity.User{
-		Id:   userEntityMongo.Id,
-		Name: userEntityMongo.Name,
+	userEntity := toUserEntity(userEntityMongo)
+	return &userEntity, nil
+}
+
+func (ur *UserRepository) FindUserByEmail(
+	ctx context.Context, email string) (*user_entity.User, *internal_error.InternalError) {
+	filter := bson.M{"email": email}
+
+	var userEntityMongo UserEntityMongo
+	err := ur.Collection.FindOne(ctx, filter).Decode(&userEntityMongo)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("User not found with this email")
+		}
+
+		logger.ErrorContext(ctx, "Error trying to find user by email", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find user by email", err)
+	}
+
+	userEntity := toUserEntity(userEntityMongo)
+	return &userEntity, nil
+}
+
+func (ur *UserRepository) FindUsers(
+	ctx context.Context, onlyActive bool) ([]user_entity.User, *internal_error.InternalError) {
+	filter := bson.M{}
+
+	if onlyActive {
+		filter["deactivated"] = bson.M{"$ne": true}
+	}
+
+	cursor, err := ur.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error finding users", err)
+		return nil, mongodb.NewDatabaseError("Error finding users", err)
 	}
+	defer cursor.Close(ctx)
 
-	return userEntity, nil
+	var usersMongo []UserEntityMongo
+	if err := cursor.All(ctx, &usersMongo); err != nil {
+		logger.ErrorContext(ctx, "Error decoding users", err)
+		return nil, mongodb.NewDatabaseError("Error decoding users", err)
+	}
+
+	var usersEntity []user_entity.User
+	for _, user := range usersMongo {
+		usersEntity = append(usersEntity, toUserEntity(user))
+	}
+
+	return usersEntity, nil
+}
+
+// toUserEntity treats users inserted before roles existed as bidders.
+func toUserEntity(userEntityMongo UserEntityMongo) user_entity.User {
+	role := user_entity.UserRole(userEntityMongo.Role)
+	if role == "" {
+		role = user_entity.Bidder
+	}
+
+	return user_entity.User{
+		Id:           userEntityMongo.Id,
+		Name:         userEntityMongo.Name,
+		Email:        userEntityMongo.Email,
+		PasswordHash: userEntityMongo.PasswordHash,
+		Role:         role,
+		Active:       !userEntityMongo.Deactivated,
+		Timestamp:    time.Unix(userEntityMongo.Timestamp, 0),
+	}
 }
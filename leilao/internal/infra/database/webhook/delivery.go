@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DeliveryEntityMongo struct {
+	Id             string                        `bson:"_id"`
+	SubscriptionId string                        `bson:"subscription_id"`
+	EventId        string                        `bson:"event_id"`
+	EventType      event_entity.EventType        `bson:"event_type"`
+	Payload        string                        `bson:"payload"`
+	Status         webhook_entity.DeliveryStatus `bson:"status"`
+	Attempts       int                           `bson:"attempts"`
+	NextAttemptAt  int64                         `bson:"next_attempt_at"`
+	LastError      string                        `bson:"last_error"`
+	LastStatusCode int                           `bson:"last_status_code"`
+	Timestamp      int64                         `bson:"timestamp"`
+	DeliveredAt    int64                         `bson:"delivered_at"`
+}
+
+type DeliveryRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewDeliveryRepository(database *mongo.Database) *DeliveryRepository {
+	return &DeliveryRepository{
+		Collection: database.Collection("webhook_deliveries"),
+	}
+}
+
+func (dr *DeliveryRepository) CreateDelivery(
+	ctx context.Context, delivery *webhook_entity.Delivery) *internal_error.InternalError {
+	filter := bson.M{"_id": delivery.Id}
+	update := bson.M{"$setOnInsert": bson.M{
+		"subscription_id":  delivery.SubscriptionId,
+		"event_id":         delivery.EventId,
+		"event_type":       delivery.EventType,
+		"payload":          string(delivery.Payload),
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt.Unix(),
+		"last_error":       "",
+		"last_status_code": 0,
+		"timestamp":        delivery.Timestamp.Unix(),
+		"delivered_at":     int64(0),
+	}}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := dr.Collection.UpdateOne(ctx, filter, update, opts); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert webhook delivery", err)
+		return mongodb.NewDatabaseError("Error trying to insert webhook delivery", err)
+	}
+
+	return nil
+}
+
+func (dr *DeliveryRepository) ClaimDueDelivery(
+	ctx context.Context,
+	now, leaseUntil time.Time) (*webhook_entity.Delivery, *internal_error.InternalError) {
+	filter := bson.M{
+		"status":          webhook_entity.Pending,
+		"next_attempt_at": bson.M{"$lte": now.Unix()},
+	}
+	update := bson.M{"$set": bson.M{"next_attempt_at": leaseUntil.Unix()}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var deliveryEntityMongo DeliveryEntityMongo
+	if err := dr.Collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&deliveryEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		logger.ErrorContext(ctx, "Error trying to claim webhook delivery", err)
+		return nil, mongodb.NewDatabaseError("Error trying to claim webhook delivery", err)
+	}
+
+	delivery := toDeliveryEntity(deliveryEntityMongo)
+	return &delivery, nil
+}
+
+func (dr *DeliveryRepository) UpdateDelivery(
+	ctx context.Context, delivery *webhook_entity.Delivery) *internal_error.InternalError {
+	filter := bson.M{"_id": delivery.Id}
+	update := bson.M{"$set": bson.M{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt.Unix(),
+		"last_error":       delivery.LastError,
+		"last_status_code": delivery.LastStatusCode,
+		"delivered_at":     unixOrZero(delivery.DeliveredAt),
+	}}
+
+	if _, err := dr.Collection.UpdateOne(ctx, filter, update); err != nil {
+		logger.ErrorContext(ctx, "Error trying to update webhook delivery", err)
+		return mongodb.NewDatabaseError("Error trying to update webhook delivery", err)
+	}
+
+	return nil
+}
+
+func (dr *DeliveryRepository) FindDeliveriesByStatus(
+	ctx context.Context,
+	status webhook_entity.DeliveryStatus, limit int64) ([]webhook_entity.Delivery, *internal_error.InternalError) {
+	filter := bson.M{"status": status}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}).SetLimit(limit)
+
+	cursor, err := dr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook deliveries", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find webhook deliveries", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deliveryEntitiesMongo []DeliveryEntityMongo
+	if err := cursor.All(ctx, &deliveryEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook deliveries", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find webhook deliveries", err)
+	}
+
+	var deliveries []webhook_entity.Delivery
+	for _, deliveryEntityMongo := range deliveryEntitiesMongo {
+		deliveries = append(deliveries, toDeliveryEntity(deliveryEntityMongo))
+	}
+
+	return deliveries, nil
+}
+
+// RetryDeadDelivery moves a dead-lettered delivery back to the queue with a
+// fresh attempt budget.
+func (dr *DeliveryRepository) RetryDeadDelivery(
+	ctx context.Context, deliveryId string) *internal_error.InternalError {
+	filter := bson.M{"_id": deliveryId, "status": webhook_entity.Dead}
+	update := bson.M{"$set": bson.M{
+		"status":          webhook_entity.Pending,
+		"attempts":        0,
+		"next_attempt_at": time.Now().Unix(),
+	}}
+
+	result, err := dr.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to retry webhook delivery", err)
+		return mongodb.NewDatabaseError("Error trying to retry webhook delivery", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Dead-lettered delivery not found with this id = %s", deliveryId))
+	}
+
+	return nil
+}
+
+func toDeliveryEntity(delivery DeliveryEntityMongo) webhook_entity.Delivery {
+	deliveryEntity := webhook_entity.Delivery{
+		Id:             delivery.Id,
+		SubscriptionId: delivery.SubscriptionId,
+		EventId:        delivery.EventId,
+		EventType:      delivery.EventType,
+		Payload:        []byte(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  time.Unix(delivery.NextAttemptAt, 0),
+		LastError:      delivery.LastError,
+		LastStatusCode: delivery.LastStatusCode,
+		Timestamp:      time.Unix(delivery.Timestamp, 0),
+	}
+
+	if delivery.DeliveredAt != 0 {
+		deliveryEntity.DeliveredAt = time.Unix(delivery.DeliveredAt, 0)
+	}
+
+	return deliveryEntity
+}
+
+func unixOrZero(value time.Time) int64 {
+	if value.IsZero() {
+		return 0
+	}
+	return value.Unix()
+}
@@ -0,0 +1,131 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/database/mongodb"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SubscriptionEntityMongo struct {
+	Id         string                   `bson:"_id"`
+	Url        string                   `bson:"url"`
+	Secret     string                   `bson:"secret"`
+	EventTypes []event_entity.EventType `bson:"event_types"`
+	Timestamp  int64                    `bson:"timestamp"`
+}
+
+type SubscriptionRepository struct {
+	Collection *mongo.Collection
+}
+
+func NewSubscriptionRepository(database *mongo.Database) *SubscriptionRepository {
+	return &SubscriptionRepository{
+		Collection: database.Collection("webhook_subscriptions"),
+	}
+}
+
+func (sr *SubscriptionRepository) CreateSubscription(
+	ctx context.Context, subscription *webhook_entity.Subscription) *internal_error.InternalError {
+	subscriptionEntityMongo := &SubscriptionEntityMongo{
+		Id:         subscription.Id,
+		Url:        subscription.Url,
+		Secret:     subscription.Secret,
+		EventTypes: subscription.EventTypes,
+		Timestamp:  subscription.Timestamp.Unix(),
+	}
+
+	if _, err := sr.Collection.InsertOne(ctx, subscriptionEntityMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to insert webhook subscription", err)
+		return mongodb.NewDatabaseError("Error trying to insert webhook subscription", err)
+	}
+
+	return nil
+}
+
+func (sr *SubscriptionRepository) FindSubscriptions(
+	ctx context.Context) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	return sr.findSubscriptions(ctx, bson.M{})
+}
+
+func (sr *SubscriptionRepository) FindSubscriptionsByEventType(
+	ctx context.Context,
+	eventType event_entity.EventType) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	return sr.findSubscriptions(ctx, bson.M{"event_types": eventType})
+}
+
+func (sr *SubscriptionRepository) FindSubscriptionById(
+	ctx context.Context, subscriptionId string) (*webhook_entity.Subscription, *internal_error.InternalError) {
+	filter := bson.M{"_id": subscriptionId}
+
+	var subscriptionEntityMongo SubscriptionEntityMongo
+	if err := sr.Collection.FindOne(ctx, filter).Decode(&subscriptionEntityMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(
+				fmt.Sprintf("Webhook not found with this id = %s", subscriptionId))
+		}
+
+		logger.ErrorContext(ctx, "Error trying to find webhook subscription", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find webhook subscription", err)
+	}
+
+	subscription := toSubscriptionEntity(subscriptionEntityMongo)
+	return &subscription, nil
+}
+
+func (sr *SubscriptionRepository) DeleteSubscription(
+	ctx context.Context, subscriptionId string) *internal_error.InternalError {
+	result, err := sr.Collection.DeleteOne(ctx, bson.M{"_id": subscriptionId})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to delete webhook subscription", err)
+		return mongodb.NewDatabaseError("Error trying to delete webhook subscription", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(
+			fmt.Sprintf("Webhook not found with this id = %s", subscriptionId))
+	}
+
+	return nil
+}
+
+func (sr *SubscriptionRepository) findSubscriptions(
+	ctx context.Context, filter bson.M) ([]webhook_entity.Subscription, *internal_error.InternalError) {
+	cursor, err := sr.Collection.Find(ctx, filter)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook subscriptions", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find webhook subscriptions", err)
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptionEntitiesMongo []SubscriptionEntityMongo
+	if err := cursor.All(ctx, &subscriptionEntitiesMongo); err != nil {
+		logger.ErrorContext(ctx, "Error trying to find webhook subscriptions", err)
+		return nil, mongodb.NewDatabaseError("Error trying to find webhook subscriptions", err)
+	}
+
+	var subscriptions []webhook_entity.Subscription
+	for _, subscriptionEntityMongo := range subscriptionEntitiesMongo {
+		subscriptions = append(subscriptions, toSubscriptionEntity(subscriptionEntityMongo))
+	}
+
+	return subscriptions, nil
+}
+
+func toSubscriptionEntity(subscription SubscriptionEntityMongo) webhook_entity.Subscription {
+	return webhook_entity.Subscription{
+		Id:         subscription.Id,
+		Url:        subscription.Url,
+		Secret:     subscription.Secret,
+		EventTypes: subscription.EventTypes,
+		Timestamp:  time.Unix(subscription.Timestamp, 0),
+	}
+}
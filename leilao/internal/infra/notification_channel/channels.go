@@ -0,0 +1,51 @@
+package notification_channel
+
+import (
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"time"
+)
+
+type Options struct {
+	// Names lists "log", "email" and "webhook".
+	Names []string
+
+	SMTPAddr     string
+	SMTPFrom     string
+	SMTPUsername string
+	SMTPPassword string
+
+	WebhookURL     string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+}
+
+// NewChannels builds the channels listed in the options. Notifications are
+// always recorded and readable through the API, whatever the channels.
+func NewChannels(options Options) ([]notification_entity.Channel, error) {
+	var channels []notification_entity.Channel
+	for _, name := range options.Names {
+		switch name {
+		case "log":
+			channels = append(channels, NewLogChannel())
+		case "email":
+			channel, err := NewSMTPEmailChannel(
+				options.SMTPAddr, options.SMTPFrom, options.SMTPUsername, options.SMTPPassword)
+			if err != nil {
+				return nil, err
+			}
+			channels = append(channels, channel)
+		case "webhook":
+			if options.WebhookURL == "" {
+				return nil, errors.New("a webhook URL must be set to send notifications by webhook")
+			}
+			channels = append(channels,
+				NewWebhookChannel(options.WebhookURL, options.WebhookSecret, options.WebhookTimeout))
+		default:
+			return nil, fmt.Errorf("unknown notification channel %q", name)
+		}
+	}
+
+	return channels, nil
+}
@@ -0,0 +1,85 @@
+package notification_channel
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// EmailChannel sends notifications through an SMTP server. Locally it points
+// to Mailpit, which keeps the messages in a web inbox instead of sending them.
+type EmailChannel struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewEmailChannel(addr, from string, auth smtp.Auth) *EmailChannel {
+	return &EmailChannel{addr: addr, from: from, auth: auth}
+}
+
+// NewSMTPEmailChannel sends through the server at addr, a host:port,
+// authenticating only when a username is given.
+func NewSMTPEmailChannel(addr, from, username, password string) (*EmailChannel, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("SMTP address must be host:port, got %q", addr)
+	}
+
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return NewEmailChannel(addr, from, auth), nil
+}
+
+func (ec *EmailChannel) Name() string {
+	return "email"
+}
+
+// Send skips recipients without an email address.
+func (ec *EmailChannel) Send(
+	ctx context.Context,
+	recipient notification_entity.Recipient, notification *notification_entity.Notification) error {
+	if recipient.Email == "" {
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return smtp.SendMail(ec.addr, ec.auth, ec.from, []string{recipient.Email},
+		buildMessage(ec.from, recipient, notification))
+}
+
+func buildMessage(
+	from string, recipient notification_entity.Recipient, notification *notification_entity.Notification) []byte {
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", recipient.Email)
+	fmt.Fprintf(&message, "Subject: %s\r\n", subjects[notification.Type])
+	fmt.Fprintf(&message, "Date: %s\r\n", notification.Timestamp.Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%s@leilao>\r\n", notification.Id)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	if recipient.Name != "" {
+		fmt.Fprintf(&message, "Hello %s,\r\n\r\n", recipient.Name)
+	}
+	message.WriteString(notification.Message)
+	message.WriteString("\r\n")
+
+	return []byte(message.String())
+}
+
+var subjects = map[notification_entity.NotificationType]string{
+	notification_entity.Outbid:      "You were outbid",
+	notification_entity.EndingSoon:  "Auction ending soon",
+	notification_entity.AuctionWon:  "You won the auction",
+	notification_entity.AuctionLost: "Auction closed",
+}
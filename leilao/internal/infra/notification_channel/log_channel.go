@@ -0,0 +1,33 @@
+package notification_channel
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+
+	"go.uber.org/zap"
+)
+
+// LogChannel writes notifications to the application log, which is enough to
+// follow them while developing.
+type LogChannel struct{}
+
+func NewLogChannel() *LogChannel {
+	return &LogChannel{}
+}
+
+func (lc *LogChannel) Name() string {
+	return "log"
+}
+
+func (lc *LogChannel) Send(
+	ctx context.Context,
+	recipient notification_entity.Recipient, notification *notification_entity.Notification) error {
+	logger.InfoContext(ctx, "Notification sent",
+		zap.String("notification_id", notification.Id),
+		zap.String("type", string(notification.Type)),
+		zap.String("user_id", recipient.UserId),
+		zap.String("message", notification.Message))
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package notification_channel
+
+import (
+	"context"
+	"encoding/json"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestNotification() *notification_entity.Notification {
+	notification := notification_entity.CreateNotification(
+		notification_entity.AuctionWon, "user", "auction", "", "You won Guitar for 130.00", 130)
+	notification.Timestamp = time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	return notification
+}
+
+func TestBuildMessage(t *testing.T) {
+	notification := newTestNotification()
+	recipient := notification_entity.Recipient{UserId: "user", Name: "Alice", Email: "alice@example.com"}
+
+	message := string(buildMessage("leilao@example.com", recipient, notification))
+
+	for _, expected := range []string{
+		"From: leilao@example.com\r\n",
+		"To: alice@example.com\r\n",
+		"Subject: You won the auction\r\n",
+		"Date: Fri, 02 Jan 2026 15:04:05 +0000\r\n",
+		"Message-ID: <" + notification.Id + "@leilao>\r\n",
+		"\r\n\r\nHello Alice,\r\n\r\nYou won Guitar for 130.00\r\n",
+	} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("Expected the message to contain %q, got %q", expected, message)
+		}
+	}
+
+	recipient.Name = ""
+	message = string(buildMessage("leilao@example.com", recipient, notification))
+	if strings.Contains(message, "Hello") {
+		t.Errorf("Expected no greeting without a name, got %q", message)
+	}
+}
+
+func TestWebhookChannelSignsPayload(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	notification := newTestNotification()
+	channel := NewWebhookChannel(server.URL, "secret", time.Second)
+
+	err := channel.Send(context.Background(), notification_entity.Recipient{UserId: "user"}, notification)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Expected a JSON payload: %v", err)
+	}
+	if payload.Id != notification.Id || payload.UserId != "user" || payload.Amount != 130 {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+
+	if received.Header.Get("X-Notification-Id") != notification.Id {
+		t.Errorf("Expected the notification id header, got %q", received.Header.Get("X-Notification-Id"))
+	}
+	timestamp, err := strconv.ParseInt(received.Header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("Expected a unix timestamp header: %v", err)
+	}
+	expectedSignature := webhook_entity.Sign("secret", time.Unix(timestamp, 0), body)
+	if signature := received.Header.Get("X-Webhook-Signature"); signature != expectedSignature {
+		t.Errorf("Expected signature %s, got %s", expectedSignature, signature)
+	}
+}
+
+func TestWebhookChannelStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"ok", http.StatusOK, false},
+		{"no content", http.StatusNoContent, false},
+		{"redirect", http.StatusNotModified, true},
+		{"client error", http.StatusBadRequest, true},
+		{"server error", http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			channel := NewWebhookChannel(server.URL, "secret", time.Second)
+			err := channel.Send(context.Background(), notification_entity.Recipient{UserId: "user"}, newTestNotification())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %t for status %d, got %v", tt.wantErr, tt.status, err)
+			}
+		})
+	}
+}
+
+func TestNewChannels(t *testing.T) {
+	channels, err := NewChannels(Options{
+		Names:      []string{"log", "email", "webhook"},
+		SMTPAddr:   "localhost:1025",
+		WebhookURL: "http://localhost/notifications",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(channels) != 3 || channels[0].Name() != "log" || channels[1].Name() != "email" ||
+		channels[2].Name() != "webhook" {
+		t.Errorf("Expected the log, email and webhook channels, got %v", channels)
+	}
+
+	for _, options := range []Options{
+		{Names: []string{"sms"}},
+		{Names: []string{"email"}, SMTPAddr: "localhost"},
+		{Names: []string{"webhook"}},
+	} {
+		if _, err := NewChannels(options); err == nil {
+			t.Errorf("Expected %+v to be rejected", options)
+		}
+	}
+}
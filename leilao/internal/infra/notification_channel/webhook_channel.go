@@ -0,0 +1,79 @@
+package notification_channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type webhookPayload struct {
+	Id        string                               `json:"id"`
+	Type      notification_entity.NotificationType `json:"type"`
+	UserId    string                               `json:"user_id"`
+	AuctionId string                               `json:"auction_id"`
+	Message   string                               `json:"message"`
+	Amount    float64                              `json:"amount,omitempty"`
+	Timestamp time.Time                            `json:"timestamp"`
+}
+
+// WebhookChannel posts every notification to a single URL, signed like the
+// webhook subscriptions so receivers can verify both the same way.
+type WebhookChannel struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookChannel(url, secret string, timeout time.Duration) *WebhookChannel {
+	return &WebhookChannel{url: url, secret: secret, client: &http.Client{Timeout: timeout}}
+}
+
+func (wc *WebhookChannel) Name() string {
+	return "webhook"
+}
+
+func (wc *WebhookChannel) Send(
+	ctx context.Context,
+	recipient notification_entity.Recipient, notification *notification_entity.Notification) error {
+	payload, err := json.Marshal(webhookPayload{
+		Id:        notification.Id,
+		Type:      notification.Type,
+		UserId:    recipient.UserId,
+		AuctionId: notification.AuctionId,
+		Message:   notification.Message,
+		Amount:    notification.Amount,
+		Timestamp: notification.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, wc.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Notification-Id", notification.Id)
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+	request.Header.Set("X-Webhook-Signature", webhook_entity.Sign(wc.secret, timestamp, payload))
+
+	response, err := wc.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook answered with status %d", response.StatusCode)
+	}
+
+	return nil
+}
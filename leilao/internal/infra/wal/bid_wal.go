@@ -0,0 +1,337 @@
+package wal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// compactionSize is the file size above which committed records are
+	// dropped by rewriting the log with only the pending bids.
+	compactionSize = 8 << 20
+)
+
+// SyncPolicy controls when appended records are fsynced to disk.
+type SyncPolicy string
+
+const (
+	// SyncAlways fsyncs before every append returns: no acknowledged bid is
+	// lost, at the cost of one fsync per bid.
+	SyncAlways SyncPolicy = "always"
+	// SyncInterval fsyncs in the background, so a crash may lose the bids
+	// acknowledged during the last interval.
+	SyncInterval SyncPolicy = "interval"
+	// SyncNone leaves flushing to the operating system.
+	SyncNone SyncPolicy = "none"
+)
+
+type recordType string
+
+const (
+	bidRecord    recordType = "bid"
+	commitRecord recordType = "commit"
+)
+
+type record struct {
+	Type   recordType      `json:"type"`
+	Bid    *bid_entity.Bid `json:"bid,omitempty"`
+	BidIds []string        `json:"bid_ids,omitempty"`
+}
+
+// BidWAL is an append-only log of the bids accepted but not yet stored. Each
+// bid is appended before it is acknowledged and a commit record is appended
+// once its batch is written, so the bids without a commit are the ones to
+// replay after a crash.
+type BidWAL struct {
+	path       string
+	syncPolicy SyncPolicy
+	file       *os.File
+	size       int64
+	pending    map[string]bid_entity.Bid
+	mutex      *sync.Mutex
+	stop       chan struct{}
+	stopped    chan struct{}
+}
+
+func NewBidWAL(path string, syncPolicy SyncPolicy, syncInterval time.Duration) (*BidWAL, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("error trying to create bid wal directory: %w", err)
+	}
+
+	pending, validLength, err := readPendingBids(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to open bid wal: %w", err)
+	}
+
+	if err := file.Truncate(validLength); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error trying to open bid wal: %w", err)
+	}
+
+	bidWAL := &BidWAL{
+		path:       path,
+		syncPolicy: syncPolicy,
+		file:       file,
+		size:       validLength,
+		pending:    pending,
+		mutex:      &sync.Mutex{},
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+
+	if syncPolicy == SyncInterval {
+		go bidWAL.syncEvery(syncInterval)
+	} else {
+		close(bidWAL.stopped)
+	}
+
+	return bidWAL, nil
+}
+
+// Append records a bid and returns once it is durable according to the sync
+// policy.
+func (w *BidWAL) Append(bid bid_entity.Bid) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.write(record{Type: bidRecord, Bid: &bid}); err != nil {
+		return err
+	}
+
+	w.pending[bid.Id] = bid
+	return nil
+}
+
+// Commit marks bids as stored. Once nothing is pending the log is truncated;
+// otherwise it is compacted when it grows past compactionSize.
+func (w *BidWAL) Commit(bids []bid_entity.Bid) error {
+	if len(bids) == 0 {
+		return nil
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	bidIds := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		bidIds = append(bidIds, bid.Id)
+	}
+
+	if err := w.write(record{Type: commitRecord, BidIds: bidIds}); err != nil {
+		return err
+	}
+
+	for _, bidId := range bidIds {
+		delete(w.pending, bidId)
+	}
+
+	if len(w.pending) == 0 {
+		return w.truncate()
+	}
+
+	if w.size > compactionSize {
+		return w.compact()
+	}
+
+	return nil
+}
+
+// Pending returns the bids appended but never committed, oldest first.
+func (w *BidWAL) Pending() []bid_entity.Bid {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return sortedBids(w.pending)
+}
+
+func (w *BidWAL) Close() error {
+	close(w.stop)
+	<-w.stopped
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+	return w.file.Close()
+}
+
+func (w *BidWAL) write(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err := w.file.Write(line); err != nil {
+		// Drop a partial record so the next append starts on a clean line.
+		w.file.Truncate(w.size)
+		return fmt.Errorf("error trying to append to bid wal: %w", err)
+	}
+	w.size += int64(len(line))
+
+	if w.syncPolicy == SyncAlways {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("error trying to sync bid wal: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *BidWAL) truncate() error {
+	if err := w.file.Truncate(0); err != nil {
+		return fmt.Errorf("error trying to truncate bid wal: %w", err)
+	}
+	w.size = 0
+
+	if w.syncPolicy == SyncAlways {
+		return w.file.Sync()
+	}
+	return nil
+}
+
+// compact rewrites the log with only the pending bids. The new file is
+// synced and renamed over the old one so a crash leaves either version.
+func (w *BidWAL) compact() error {
+	tmpPath := w.path + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("error trying to compact bid wal: %w", err)
+	}
+
+	var size int64
+	writer := bufio.NewWriter(tmpFile)
+	for _, bid := range sortedBids(w.pending) {
+		bid := bid
+		line, err := json.Marshal(record{Type: bidRecord, Bid: &bid})
+		if err != nil {
+			tmpFile.Close()
+			return err
+		}
+		n, _ := writer.Write(append(line, '\n'))
+		size += int64(n)
+	}
+
+	if err := writer.Flush(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("error trying to compact bid wal: %w", err)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("error trying to compact bid wal: %w", err)
+	}
+	tmpFile.Close()
+
+	if err := os.Rename(tmpPath, w.path); err != nil {
+		return fmt.Errorf("error trying to compact bid wal: %w", err)
+	}
+
+	file, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error trying to reopen bid wal: %w", err)
+	}
+
+	w.file.Close()
+	w.file = file
+	w.size = size
+	return nil
+}
+
+func (w *BidWAL) syncEvery(interval time.Duration) {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.mutex.Lock()
+			if err := w.file.Sync(); err != nil {
+				logger.Error("Error trying to sync bid wal", err)
+			}
+			w.mutex.Unlock()
+		}
+	}
+}
+
+// readPendingBids replays the log file and returns the length of its complete
+// records. A torn last line, left by a crash in the middle of a write, is
+// not part of that length so it can be cut before appending again.
+func readPendingBids(path string) (map[string]bid_entity.Bid, int64, error) {
+	pending := make(map[string]bid_entity.Bid)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return pending, 0, nil
+		}
+		return nil, 0, fmt.Errorf("error trying to read bid wal: %w", err)
+	}
+
+	validLength := bytes.LastIndexByte(content, '\n') + 1
+	for _, line := range bytes.Split(content[:validLength], []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			logger.Info("Ignoring unreadable bid wal record", zap.String("path", path))
+			continue
+		}
+
+		switch rec.Type {
+		case bidRecord:
+			if rec.Bid != nil {
+				pending[rec.Bid.Id] = *rec.Bid
+			}
+		case commitRecord:
+			for _, bidId := range rec.BidIds {
+				delete(pending, bidId)
+			}
+		}
+	}
+
+	if validLength < len(content) {
+		logger.Info("Ignoring torn bid wal record", zap.String("path", path))
+	}
+
+	return pending, int64(validLength), nil
+}
+
+func sortedBids(bidsById map[string]bid_entity.Bid) []bid_entity.Bid {
+	bids := make([]bid_entity.Bid, 0, len(bidsById))
+	for _, bid := range bidsById {
+		bids = append(bids, bid)
+	}
+
+	sort.Slice(bids, func(i, j int) bool {
+		if bids[i].Timestamp.Equal(bids[j].Timestamp) {
+			return bids[i].Id < bids[j].Id
+		}
+		return bids[i].Timestamp.Before(bids[j].Timestamp)
+	})
+	return bids
+}
@@ -0,0 +1,138 @@
+package wal
+
+import (
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testBid(id string, offset time.Duration) bid_entity.Bid {
+	return bid_entity.Bid{
+		Id:        id,
+		UserId:    "user",
+		AuctionId: "auction",
+		Amount:    10,
+		Timestamp: time.Unix(1700000000, 0).Add(offset),
+	}
+}
+
+func TestBidWALReplaysUncommittedBids(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.wal")
+
+	bidWAL, err := NewBidWAL(path, SyncAlways, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i, id := range []string{"a", "b", "c"} {
+		if err := bidWAL.Append(testBid(id, time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("Unexpected append error: %v", err)
+		}
+	}
+	if err := bidWAL.Commit([]bid_entity.Bid{testBid("b", 0)}); err != nil {
+		t.Fatalf("Unexpected commit error: %v", err)
+	}
+	bidWAL.Close()
+
+	reopened, err := NewBidWAL(path, SyncAlways, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	pending := reopened.Pending()
+	if len(pending) != 2 || pending[0].Id != "a" || pending[1].Id != "c" {
+		t.Fatalf("Expected bids a and c to be pending, got %+v", pending)
+	}
+	if !pending[1].Timestamp.Equal(testBid("c", 2*time.Second).Timestamp) || pending[1].Amount != 10 {
+		t.Errorf("Expected the bid fields to survive the round trip, got %+v", pending[1])
+	}
+}
+
+func TestBidWALTruncatesWhenEverythingIsCommitted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.wal")
+
+	bidWAL, err := NewBidWAL(path, SyncNone, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer bidWAL.Close()
+
+	bids := []bid_entity.Bid{testBid("a", 0), testBid("b", time.Second)}
+	for _, bid := range bids {
+		bidWAL.Append(bid)
+	}
+	bidWAL.Commit(bids)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Size() != 0 {
+		t.Errorf("Expected an empty log, got %d bytes", info.Size())
+	}
+}
+
+func TestBidWALIgnoresTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.wal")
+
+	bidWAL, err := NewBidWAL(path, SyncAlways, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	bidWAL.Append(testBid("a", 0))
+	bidWAL.Close()
+
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	file.WriteString(`{"type":"bid","bid":{"Id":"tor`)
+	file.Close()
+
+	reopened, err := NewBidWAL(path, SyncAlways, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	reopened.Append(testBid("b", time.Second))
+	reopened.Close()
+
+	final, err := NewBidWAL(path, SyncAlways, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer final.Close()
+
+	pending := final.Pending()
+	if len(pending) != 2 || pending[0].Id != "a" || pending[1].Id != "b" {
+		t.Fatalf("Expected bids a and b to be pending, got %+v", pending)
+	}
+}
+
+func TestBidWALCompactKeepsPendingBids(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bids.wal")
+
+	bidWAL, err := NewBidWAL(path, SyncNone, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	bidWAL.Append(testBid("a", 0))
+	bidWAL.Append(testBid("b", time.Second))
+	bidWAL.Commit([]bid_entity.Bid{testBid("a", 0)})
+
+	if err := bidWAL.compact(); err != nil {
+		t.Fatalf("Unexpected compaction error: %v", err)
+	}
+	bidWAL.Append(testBid("c", 2*time.Second))
+	bidWAL.Close()
+
+	reopened, err := NewBidWAL(path, SyncNone, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer reopened.Close()
+
+	pending := reopened.Pending()
+	if len(pending) != 2 || pending[0].Id != "b" || pending[1].Id != "c" {
+		t.Fatalf("Expected bids b and c to be pending, got %+v", pending)
+	}
+}
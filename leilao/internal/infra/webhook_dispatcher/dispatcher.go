@@ -0,0 +1,208 @@
+package webhook_dispatcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const dispatchBatchSize = 100
+
+// Dispatcher relays outbox events into one delivery per matching subscription
+// and sends due deliveries, retrying failures with exponential backoff until
+// they are dead-lettered.
+type Dispatcher struct {
+	outboxRepository       event_entity.OutboxRepositoryInterface
+	subscriptionRepository webhook_entity.SubscriptionRepositoryInterface
+	deliveryRepository     webhook_entity.DeliveryRepositoryInterface
+	client                 *http.Client
+	retryPolicy            webhook_entity.RetryPolicy
+	pollInterval           time.Duration
+	stop                   chan struct{}
+	stopOnce               *sync.Once
+	stopped                chan struct{}
+}
+
+type Options struct {
+	// Timeout bounds each delivery request.
+	Timeout      time.Duration
+	RetryPolicy  webhook_entity.RetryPolicy
+	PollInterval time.Duration
+}
+
+func NewDispatcher(
+	outboxRepository event_entity.OutboxRepositoryInterface,
+	subscriptionRepository webhook_entity.SubscriptionRepositoryInterface,
+	deliveryRepository webhook_entity.DeliveryRepositoryInterface,
+	options Options) *Dispatcher {
+	return &Dispatcher{
+		outboxRepository:       outboxRepository,
+		subscriptionRepository: subscriptionRepository,
+		deliveryRepository:     deliveryRepository,
+		client:                 &http.Client{Timeout: options.Timeout},
+		retryPolicy:            options.RetryPolicy,
+		pollInterval:           options.PollInterval,
+		stop:                   make(chan struct{}),
+		stopOnce:               &sync.Once{},
+		stopped:                make(chan struct{}),
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	go func() {
+		defer close(d.stopped)
+
+		ticker := time.NewTicker(d.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				d.relayEvents(ctx)
+				d.deliverDue(ctx)
+			}
+		}
+	}()
+}
+
+// Shutdown lets the current round of deliveries finish so no attempt is cut
+// in the middle, then stops the dispatcher. Calling it again is harmless.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stop) })
+
+	select {
+	case <-d.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// relayEvents fans each unpublished event out to its subscriptions. Delivery
+// ids are deterministic, so an event relayed twice is only delivered once.
+func (d *Dispatcher) relayEvents(ctx context.Context) {
+	events, err := d.outboxRepository.FindUnpublishedEvents(ctx, dispatchBatchSize)
+	if err != nil {
+		return
+	}
+
+	for _, event := range events {
+		subscriptions, err := d.subscriptionRepository.FindSubscriptionsByEventType(ctx, event.Type)
+		if err != nil {
+			return
+		}
+
+		for _, subscription := range subscriptions {
+			delivery := &webhook_entity.Delivery{
+				Id:             webhook_entity.DeliveryId(event.Id, subscription.Id),
+				SubscriptionId: subscription.Id,
+				EventId:        event.Id,
+				EventType:      event.Type,
+				Payload:        event.Payload,
+				Status:         webhook_entity.Pending,
+				NextAttemptAt:  time.Now(),
+				Timestamp:      time.Now(),
+			}
+
+			if err := d.deliveryRepository.CreateDelivery(ctx, delivery); err != nil {
+				return
+			}
+		}
+
+		if err := d.outboxRepository.MarkEventPublished(ctx, event.Id); err != nil {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for i := 0; i < dispatchBatchSize; i++ {
+		now := time.Now()
+		delivery, err := d.deliveryRepository.ClaimDueDelivery(ctx, now, now.Add(2*d.client.Timeout))
+		if err != nil || delivery == nil {
+			return
+		}
+
+		d.deliver(ctx, delivery)
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *webhook_entity.Delivery) {
+	subscription, err := d.subscriptionRepository.FindSubscriptionById(ctx, delivery.SubscriptionId)
+	if err != nil {
+		if err.Code != internal_error.NotFound {
+			return
+		}
+
+		delivery.Status = webhook_entity.Dead
+		delivery.LastError = "webhook subscription was deleted"
+		d.deliveryRepository.UpdateDelivery(ctx, delivery)
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := d.send(ctx, subscription, delivery)
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = webhook_entity.Delivered
+		delivery.DeliveredAt = time.Now()
+		delivery.LastError = ""
+	case d.retryPolicy.Exhausted(delivery.Attempts):
+		delivery.Status = webhook_entity.Dead
+		delivery.LastError = sendErr.Error()
+
+		logger.InfoContext(ctx, "Webhook delivery dead-lettered",
+			zap.String("delivery_id", delivery.Id),
+			zap.Int("attempts", delivery.Attempts),
+			zap.String("error", sendErr.Error()))
+	default:
+		delivery.NextAttemptAt = time.Now().Add(d.retryPolicy.NextDelay(delivery.Attempts))
+		delivery.LastError = sendErr.Error()
+	}
+
+	d.deliveryRepository.UpdateDelivery(ctx, delivery)
+}
+
+// send posts the event payload. Any non-2xx answer counts as a failure.
+func (d *Dispatcher) send(
+	ctx context.Context,
+	subscription *webhook_entity.Subscription, delivery *webhook_entity.Delivery) (int, error) {
+	request, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Webhook-Id", delivery.Id)
+	request.Header.Set("X-Webhook-Event", string(delivery.EventType))
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+	request.Header.Set("X-Webhook-Signature", webhook_entity.Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook answered with status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
@@ -0,0 +1,141 @@
+package webhook_dispatcher
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type fakeSubscriptionRepository struct {
+	webhook_entity.SubscriptionRepositoryInterface
+	subscription *webhook_entity.Subscription
+}
+
+func (f *fakeSubscriptionRepository) FindSubscriptionById(
+	ctx context.Context, subscriptionId string) (*webhook_entity.Subscription, *internal_error.InternalError) {
+	if f.subscription == nil {
+		return nil, internal_error.NewNotFoundError("not found")
+	}
+	return f.subscription, nil
+}
+
+type fakeDeliveryRepository struct {
+	webhook_entity.DeliveryRepositoryInterface
+	updated *webhook_entity.Delivery
+}
+
+func (f *fakeDeliveryRepository) UpdateDelivery(
+	ctx context.Context, delivery *webhook_entity.Delivery) *internal_error.InternalError {
+	updated := *delivery
+	f.updated = &updated
+	return nil
+}
+
+func newTestDispatcher(subscription *webhook_entity.Subscription) (*Dispatcher, *fakeDeliveryRepository) {
+	deliveryRepository := &fakeDeliveryRepository{}
+	return &Dispatcher{
+		subscriptionRepository: &fakeSubscriptionRepository{subscription: subscription},
+		deliveryRepository:     deliveryRepository,
+		client:                 &http.Client{Timeout: time.Second},
+		retryPolicy: webhook_entity.RetryPolicy{
+			MaxAttempts: 2, BaseDelay: time.Minute, MaxDelay: time.Hour,
+		},
+	}, deliveryRepository
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	payload := []byte(`{"type":"bid.accepted"}`)
+
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	subscription := &webhook_entity.Subscription{Id: "sub", Url: server.URL, Secret: "secret"}
+	dispatcher, deliveryRepository := newTestDispatcher(subscription)
+
+	dispatcher.deliver(context.Background(), &webhook_entity.Delivery{
+		Id: "delivery", SubscriptionId: "sub", EventType: event_entity.BidAccepted,
+		Payload: payload, Status: webhook_entity.Pending,
+	})
+
+	if string(body) != string(payload) {
+		t.Fatalf("Expected payload %s, got %s", payload, body)
+	}
+
+	timestamp, err := strconv.ParseInt(received.Header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("Expected a unix timestamp header: %v", err)
+	}
+	expectedSignature := webhook_entity.Sign("secret", time.Unix(timestamp, 0), payload)
+	if received.Header.Get("X-Webhook-Signature") != expectedSignature {
+		t.Errorf("Expected signature %s, got %s", expectedSignature, received.Header.Get("X-Webhook-Signature"))
+	}
+	if received.Header.Get("X-Webhook-Event") != string(event_entity.BidAccepted) {
+		t.Errorf("Expected event header %s, got %s", event_entity.BidAccepted, received.Header.Get("X-Webhook-Event"))
+	}
+
+	if deliveryRepository.updated.Status != webhook_entity.Delivered || deliveryRepository.updated.Attempts != 1 {
+		t.Errorf("Expected a delivered delivery after one attempt, got %+v", deliveryRepository.updated)
+	}
+}
+
+func TestDeliverRetriesThenDeadLetters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	subscription := &webhook_entity.Subscription{Id: "sub", Url: server.URL, Secret: "secret"}
+	dispatcher, deliveryRepository := newTestDispatcher(subscription)
+
+	delivery := &webhook_entity.Delivery{Id: "delivery", SubscriptionId: "sub", Status: webhook_entity.Pending}
+	dispatcher.deliver(context.Background(), delivery)
+
+	updated := deliveryRepository.updated
+	if updated.Status != webhook_entity.Pending || updated.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected a pending delivery after the first failure, got %+v", updated)
+	}
+	if wait := time.Until(updated.NextAttemptAt); wait < 50*time.Second || wait > time.Minute {
+		t.Errorf("Expected the next attempt about one base delay away, got %v", wait)
+	}
+
+	dispatcher.deliver(context.Background(), updated)
+	if deliveryRepository.updated.Status != webhook_entity.Dead {
+		t.Errorf("Expected the delivery to be dead-lettered, got %s", deliveryRepository.updated.Status)
+	}
+}
+
+func TestDeliverDeadLettersWhenSubscriptionIsDeleted(t *testing.T) {
+	dispatcher, deliveryRepository := newTestDispatcher(nil)
+
+	dispatcher.deliver(context.Background(), &webhook_entity.Delivery{Id: "delivery", Status: webhook_entity.Pending})
+
+	if deliveryRepository.updated.Status != webhook_entity.Dead {
+		t.Errorf("Expected the delivery to be dead-lettered, got %s", deliveryRepository.updated.Status)
+	}
+}
+
+func TestShutdownCanBeCalledTwice(t *testing.T) {
+	dispatcher := NewDispatcher(nil, &fakeSubscriptionRepository{}, &fakeDeliveryRepository{},
+		Options{Timeout: time.Second, PollInterval: time.Hour})
+	dispatcher.Start(context.Background())
+
+	for i := 0; i < 2; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if err := dispatcher.Shutdown(ctx); err != nil {
+			t.Errorf("Expected shutdown %d to succeed, got %v", i+1, err)
+		}
+		cancel()
+	}
+}
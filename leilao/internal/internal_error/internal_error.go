@@ -1,31 +1,131 @@
 package internal_error
 
+import "errors"
+
+// ErrorCode is the machine-readable kind of an error. rest_err maps each code
+// to a single HTTP status.
+type ErrorCode string
+
+const (
+	BadRequest   ErrorCode = "bad_request"
+	Validation   ErrorCode = "validation"
+	Unauthorized ErrorCode = "unauthorized"
+	Forbidden    ErrorCode = "forbidden"
+	NotFound     ErrorCode = "not_found"
+	Conflict     ErrorCode = "conflict"
+	Unavailable  ErrorCode = "unavailable"
+	Internal     ErrorCode = "internal_server_error"
+)
+
+// Cause names an input field that failed validation.
+type Cause struct {
+	Field   string
+	Message string
+}
+
 type InternalError struct {
 	Message string
-	Err     string
+	Code    ErrorCode
+	Causes  []Cause
+	cause   error
 }
 
 func (ie *InternalError) Error() string {
 	return ie.Message
 }
 
+// Unwrap returns the error that caused this one, if any, so errors.Is and
+// errors.As can reach driver errors through it.
+func (ie *InternalError) Unwrap() error {
+	return ie.cause
+}
+
+// Wrap records err as the cause of ie and returns ie.
+func (ie *InternalError) Wrap(err error) *InternalError {
+	ie.cause = err
+	return ie
+}
+
+// Wrap adds context to err. The code and causes of err are kept when it is an
+// InternalError; any other error becomes an internal server error.
+func Wrap(err error, message string) *InternalError {
+	wrapped := &InternalError{Message: message, Code: Internal, cause: err}
+
+	var internalError *InternalError
+	if errors.As(err, &internalError) {
+		wrapped.Code = internalError.Code
+		wrapped.Causes = internalError.Causes
+	}
+
+	return wrapped
+}
+
+// Is reports whether err, or any error it wraps, is an InternalError with the
+// given code.
+func Is(err error, code ErrorCode) bool {
+	var internalError *InternalError
+	return errors.As(err, &internalError) && internalError.Code == code
+}
+
 func NewNotFoundError(message string) *InternalError {
 	return &InternalError{
 		Message: message,
-		Err:     "not_found",
+		Code:    NotFound,
 	}
 }
 
 func NewInternalServerError(message string) *InternalError {
 	return &InternalError{
 		Message: message,
-		Err:     "internal_server_error",
+		Code:    Internal,
 	}
 }
 
 func NewBadRequestError(message string) *InternalError {
 	return &InternalError{
 		Message: message,
-		Err:     "bad_request",
+		Code:    BadRequest,
+	}
+}
+
+// NewValidationError reports input that is well formed but invalid, with the
+// fields at fault.
+func NewValidationError(message string, causes ...Cause) *InternalError {
+	return &InternalError{
+		Message: message,
+		Code:    Validation,
+		Causes:  causes,
+	}
+}
+
+func NewUnauthorizedError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Code:    Unauthorized,
+	}
+}
+
+func NewForbiddenError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Code:    Forbidden,
+	}
+}
+
+// NewConflictError reports a request that clashes with the current state of
+// a resource, like a duplicate email or editing a closed auction.
+func NewConflictError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Code:    Conflict,
+	}
+}
+
+// NewUnavailableError reports a dependency that is temporarily unreachable;
+// the same request may succeed if retried.
+func NewUnavailableError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Code:    Unavailable,
 	}
 }
@@ -0,0 +1,265 @@
+package auction_usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// AttachmentInputDTO is an uploaded file. Content is only read up to the size
+// limit of its kind.
+type AttachmentInputDTO struct {
+	FileName string
+	Content  io.Reader
+}
+
+type AttachmentOutputDTO struct {
+	Id           string    `json:"id"`
+	FileName     string    `json:"file_name"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	Timestamp    time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+}
+
+// AttachmentContentOutputDTO is a stored file or thumbnail; the caller must
+// close Content. Size is -1 when it is not known in advance.
+type AttachmentContentOutputDTO struct {
+	FileName    string
+	ContentType string
+	Size        int64
+	Content     io.ReadCloser
+}
+
+func (au *AuctionUseCase) UploadAttachment(
+	ctx context.Context,
+	auctionId string,
+	requester RequesterDTO,
+	attachmentInput AttachmentInputDTO) (*AttachmentOutputDTO, *internal_error.InternalError) {
+	auctionEntity, err := au.findManagedAuction(ctx, auctionId, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	if auctionEntity.Status != auction_entity.Active {
+		return nil, internal_error.NewConflictError("Attachments can only be added to active auctions")
+	}
+
+	existing, err := au.attachmentRepositoryInterface.FindAttachmentsByAuctionIds(ctx, []string{auctionId})
+	if err != nil {
+		return nil, err
+	}
+	if maxAttachments := au.attachmentLimits.MaxPerAuction; len(existing) >= maxAttachments {
+		return nil, internal_error.NewConflictError(
+			fmt.Sprintf("Auctions can have at most %d attachments", maxAttachments))
+	}
+
+	content, readErr := io.ReadAll(io.LimitReader(attachmentInput.Content, au.MaxAttachmentSize()+1))
+	if readErr != nil {
+		return nil, internal_error.NewBadRequestError("Error trying to read the uploaded file").Wrap(readErr)
+	}
+
+	attachment, err := attachment_entity.CreateAttachment(
+		auctionId, attachmentInput.FileName, sniffContentType(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	if maxSize := au.attachmentLimits.maxSize(attachment.Kind); attachment.Size > maxSize {
+		return nil, internal_error.NewValidationError("invalid attachment", internal_error.Cause{
+			Field: "file", Message: fmt.Sprintf("must not be larger than %d bytes", maxSize)})
+	}
+
+	var thumbnail []byte
+	if attachment.HasThumbnail() {
+		var thumbnailErr error
+		if thumbnail, thumbnailErr = generateThumbnail(content); thumbnailErr != nil {
+			return nil, internal_error.NewValidationError("invalid attachment", internal_error.Cause{
+				Field: "file", Message: "must be a readable image of at most 40 megapixels"}).Wrap(thumbnailErr)
+		}
+	}
+
+	// Blobs are stored before the attachment so a listed attachment always
+	// has its contents; they are removed again if it cannot be created.
+	if err := au.blobStore.Put(ctx, attachment.BlobKey(), bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+
+	if thumbnail != nil {
+		if err := au.blobStore.Put(ctx, attachment.ThumbnailKey(), bytes.NewReader(thumbnail)); err != nil {
+			au.deleteBlobs(ctx, attachment)
+			return nil, err
+		}
+	}
+
+	if err := au.attachmentRepositoryInterface.CreateAttachment(ctx, attachment); err != nil {
+		au.deleteBlobs(ctx, attachment)
+		return nil, err
+	}
+
+	attachmentOutputDTO := toAttachmentOutputDTO(*attachment)
+	return &attachmentOutputDTO, nil
+}
+
+// OpenAttachment returns the contents of an attachment of the auction, or its
+// thumbnail, which is always a JPEG.
+func (au *AuctionUseCase) OpenAttachment(
+	ctx context.Context,
+	auctionId, attachmentId string,
+	thumbnail bool) (*AttachmentContentOutputDTO, *internal_error.InternalError) {
+	attachment, err := au.findAuctionAttachment(ctx, auctionId, attachmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	key, contentType, size := attachment.BlobKey(), attachment.ContentType, attachment.Size
+	if thumbnail {
+		if !attachment.HasThumbnail() {
+			return nil, internal_error.NewNotFoundError("Only image attachments have thumbnails")
+		}
+		key, contentType, size = attachment.ThumbnailKey(), "image/jpeg", -1
+	}
+
+	content, err := au.blobStore.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttachmentContentOutputDTO{
+		FileName:    attachment.FileName,
+		ContentType: contentType,
+		Size:        size,
+		Content:     content,
+	}, nil
+}
+
+func (au *AuctionUseCase) DeleteAttachment(
+	ctx context.Context,
+	auctionId, attachmentId string,
+	requester RequesterDTO) *internal_error.InternalError {
+	if _, err := au.findManagedAuction(ctx, auctionId, requester); err != nil {
+		return err
+	}
+
+	attachment, err := au.findAuctionAttachment(ctx, auctionId, attachmentId)
+	if err != nil {
+		return err
+	}
+
+	if err := au.attachmentRepositoryInterface.DeleteAttachment(ctx, attachment.Id); err != nil {
+		return err
+	}
+
+	au.deleteBlobs(ctx, attachment)
+	return nil
+}
+
+func (au *AuctionUseCase) findAuctionAttachment(
+	ctx context.Context,
+	auctionId, attachmentId string) (*attachment_entity.Attachment, *internal_error.InternalError) {
+	attachment, err := au.attachmentRepositoryInterface.FindAttachmentById(ctx, attachmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.AuctionId != auctionId {
+		return nil, internal_error.NewNotFoundError(
+			fmt.Sprintf("Attachment not found with this id = %s", attachmentId))
+	}
+
+	return attachment, nil
+}
+
+// deleteBlobs is best effort: the blob store logs the failures and a leftover
+// blob is only wasted space.
+func (au *AuctionUseCase) deleteBlobs(ctx context.Context, attachment *attachment_entity.Attachment) {
+	au.blobStore.Delete(ctx, attachment.BlobKey())
+	if attachment.HasThumbnail() {
+		au.blobStore.Delete(ctx, attachment.ThumbnailKey())
+	}
+}
+
+// findAttachments groups the attachments of the given auctions by auction.
+// When the lookup fails the auctions are still shown, without their files.
+func (au *AuctionUseCase) findAttachments(
+	ctx context.Context, auctionIds ...string) map[string][]attachment_entity.Attachment {
+	if len(auctionIds) == 0 {
+		return nil
+	}
+
+	attachments, err := au.attachmentRepositoryInterface.FindAttachmentsByAuctionIds(ctx, auctionIds)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error trying to find attachments", err)
+		return nil
+	}
+
+	attachmentsByAuction := make(map[string][]attachment_entity.Attachment)
+	for _, attachment := range attachments {
+		attachmentsByAuction[attachment.AuctionId] = append(attachmentsByAuction[attachment.AuctionId], attachment)
+	}
+
+	return attachmentsByAuction
+}
+
+func toAttachmentOutputDTO(attachment attachment_entity.Attachment) AttachmentOutputDTO {
+	url := fmt.Sprintf("/auction/%s/attachments/%s", attachment.AuctionId, attachment.Id)
+
+	attachmentOutputDTO := AttachmentOutputDTO{
+		Id:          attachment.Id,
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		Size:        attachment.Size,
+		URL:         url,
+		Timestamp:   attachment.Timestamp,
+	}
+	if attachment.HasThumbnail() {
+		attachmentOutputDTO.ThumbnailURL = url + "/thumbnail"
+	}
+
+	return attachmentOutputDTO
+}
+
+// sniffContentType detects the content type from the first bytes of the file,
+// without parameters like the charset.
+func sniffContentType(content []byte) string {
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil {
+		return ""
+	}
+
+	return contentType
+}
+
+// AttachmentLimits bounds the size of each kind of attachment, in bytes, and
+// their number per auction.
+type AttachmentLimits struct {
+	MaxImageSize    int64
+	MaxDocumentSize int64
+	MaxPerAuction   int
+}
+
+func (l AttachmentLimits) maxSize(kind attachment_entity.AttachmentKind) int64 {
+	if kind == attachment_entity.Image {
+		return l.MaxImageSize
+	}
+
+	return l.MaxDocumentSize
+}
+
+// MaxAttachmentSize is the largest file accepted for any kind of attachment.
+func (au *AuctionUseCase) MaxAttachmentSize() int64 {
+	if au.attachmentLimits.MaxImageSize > au.attachmentLimits.MaxDocumentSize {
+		return au.attachmentLimits.MaxImageSize
+	}
+
+	return au.attachmentLimits.MaxDocumentSize
+}
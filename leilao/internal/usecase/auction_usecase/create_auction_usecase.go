@@ -2,6 +2,7 @@ package auction_usecase
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/internal_error"
@@ -10,20 +11,65 @@ import (
 )
 
 type AuctionInputDTO struct {
-	ProductName string           `json:"product_name" binding:"required,min=1"`
-	Category    string           `json:"category" binding:"required,min=2"`
-	Description string           `json:"description" binding:"required,min=10,max=200"`
-	Condition   ProductCondition `json:"condition" binding:"oneof=0 1 2"`
+	SellerId     string           `json:"-"`
+	ProductName  string           `json:"product_name" binding:"required,min=1"`
+	Category     string           `json:"category" binding:"required,min=2"`
+	Description  string           `json:"description" binding:"required,min=10,max=200"`
+	Condition    ProductCondition `json:"condition" binding:"oneof=0 1 2"`
+	ReservePrice float64          `json:"reserve_price" binding:"omitempty,gte=0"`
+
+	Format        AuctionFormat     `json:"format" binding:"omitempty,oneof=english sealed_first_price sealed_second_price dutch"`
+	BuyItNowPrice float64           `json:"buy_it_now_price" binding:"omitempty,gt=0"`
+	Dutch         *DutchScheduleDTO `json:"dutch"`
+}
+
+type DutchScheduleDTO struct {
+	StartPrice      float64 `json:"start_price"`
+	Decrement       float64 `json:"decrement"`
+	IntervalSeconds int64   `json:"interval_seconds"`
+	FloorPrice      float64 `json:"floor_price"`
 }
 
 type AuctionOutputDTO struct {
-	Id          string           `json:"id"`
-	ProductName string           `json:"product_name"`
-	Category    string           `json:"category"`
-	Description string           `json:"description"`
-	Condition   ProductCondition `json:"condition"`
-	Status      AuctionStatus    `json:"status"`
-	Timestamp   time.Time        `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	Id              string           `json:"id"`
+	SellerId        string           `json:"seller_id"`
+	ProductName     string           `json:"product_name"`
+	Category        string           `json:"category"`
+	Description     string           `json:"description"`
+	Condition       ProductCondition `json:"condition"`
+	Status          AuctionStatus    `json:"status"`
+	Timestamp       time.Time        `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	EndTime         time.Time        `json:"end_time" time_format:"2006-01-02 15:04:05"`
+	CurrentPrice    float64          `json:"current_price"`
+	HighestBidderId string           `json:"highest_bidder_id,omitempty"`
+	BidCount        int64            `json:"bid_count"`
+
+	Format        AuctionFormat     `json:"format"`
+	BuyItNowPrice float64           `json:"buy_it_now_price,omitempty"`
+	Dutch         *DutchScheduleDTO `json:"dutch,omitempty"`
+
+	Images    []AttachmentOutputDTO `json:"images"`
+	Documents []AttachmentOutputDTO `json:"documents"`
+}
+
+type AuctionFilterInputDTO struct {
+	Status       *AuctionStatus    `form:"status" binding:"omitempty,oneof=0 1 2"`
+	Category     string            `form:"category"`
+	ProductName  string            `form:"product_name"`
+	Condition    *ProductCondition `form:"condition" binding:"omitempty,oneof=0 1 2 3"`
+	MinPrice     *float64          `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice     *float64          `form:"max_price" binding:"omitempty,gte=0"`
+	EndingBefore *time.Time        `form:"ending_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort         string            `form:"sort" binding:"omitempty,oneof=created end_time current_price"`
+	Order        string            `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit        int               `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor       string            `form:"cursor"`
+}
+
+type AuctionPageOutputDTO struct {
+	Items      []AuctionOutputDTO `json:"items"`
+	Total      int64              `json:"total"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 type WinningInfoOutputDTO struct {
@@ -33,10 +79,16 @@ type WinningInfoOutputDTO struct {
 
 func NewAuctionUseCase(
 	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface,
-	bidRepositoryInterface bid_entity.BidEntityRepository) AuctionUseCaseInterface {
+	bidRepositoryInterface bid_entity.BidEntityRepository,
+	attachmentRepositoryInterface attachment_entity.AttachmentRepositoryInterface,
+	blobStore attachment_entity.BlobStore,
+	attachmentLimits AttachmentLimits) AuctionUseCaseInterface {
 	return &AuctionUseCase{
-		auctionRepositoryInterface: auctionRepositoryInterface,
-		bidRepositoryInterface:     bidRepositoryInterface,
+		auctionRepositoryInterface:    auctionRepositoryInterface,
+		bidRepositoryInterface:        bidRepositoryInterface,
+		attachmentRepositoryInterface: attachmentRepositoryInterface,
+		blobStore:                     blobStore,
+		attachmentLimits:              attachmentLimits,
 	}
 }
 
@@ -50,30 +102,81 @@ type AuctionUseCaseInterface interface {
 
 	FindAuctions(
 		ctx context.Context,
-		status AuctionStatus,
-		category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError)
+		filterInput AuctionFilterInputDTO) (*AuctionPageOutputDTO, *internal_error.InternalError)
 
 	FindWinningBidByAuctionId(
 		ctx context.Context,
 		auctionId string) (*WinningInfoOutputDTO, *internal_error.InternalError)
+
+	SearchAuctions(
+		ctx context.Context,
+		searchInput AuctionSearchInputDTO) (*AuctionSearchOutputDTO, *internal_error.InternalError)
+
+	UpdateAuction(
+		ctx context.Context,
+		auctionId string,
+		requester RequesterDTO,
+		auctionInput AuctionUpdateInputDTO) (*AuctionOutputDTO, *internal_error.InternalError)
+
+	CancelAuction(
+		ctx context.Context,
+		auctionId string,
+		requester RequesterDTO) *internal_error.InternalError
+
+	UploadAttachment(
+		ctx context.Context,
+		auctionId string,
+		requester RequesterDTO,
+		attachmentInput AttachmentInputDTO) (*AttachmentOutputDTO, *internal_error.InternalError)
+
+	OpenAttachment(
+		ctx context.Context,
+		auctionId, attachmentId string,
+		thumbnail bool) (*AttachmentContentOutputDTO, *internal_error.InternalError)
+
+	DeleteAttachment(
+		ctx context.Context,
+		auctionId, attachmentId string,
+		requester RequesterDTO) *internal_error.InternalError
+
+	MaxAttachmentSize() int64
 }
 
 type ProductCondition int64
 type AuctionStatus int64
+type AuctionFormat string
 
 type AuctionUseCase struct {
-	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface
-	bidRepositoryInterface     bid_entity.BidEntityRepository
+	auctionRepositoryInterface    auction_entity.AuctionRepositoryInterface
+	bidRepositoryInterface        bid_entity.BidEntityRepository
+	attachmentRepositoryInterface attachment_entity.AttachmentRepositoryInterface
+	blobStore                     attachment_entity.BlobStore
+	attachmentLimits              AttachmentLimits
 }
 
 func (au *AuctionUseCase) CreateAuction(
 	ctx context.Context,
 	auctionInput AuctionInputDTO) *internal_error.InternalError {
+	var dutchSchedule auction_entity.DutchSchedule
+	if auctionInput.Dutch != nil {
+		dutchSchedule = auction_entity.DutchSchedule{
+			StartPrice: auctionInput.Dutch.StartPrice,
+			Decrement:  auctionInput.Dutch.Decrement,
+			Interval:   time.Duration(auctionInput.Dutch.IntervalSeconds) * time.Second,
+			FloorPrice: auctionInput.Dutch.FloorPrice,
+		}
+	}
+
 	auction, err := auction_entity.CreateAuction(
+		auctionInput.SellerId,
 		auctionInput.ProductName,
 		auctionInput.Category,
 		auctionInput.Description,
-		auction_entity.ProductCondition(auctionInput.Condition))
+		auction_entity.ProductCondition(auctionInput.Condition),
+		auctionInput.ReservePrice,
+		auction_entity.AuctionFormat(auctionInput.Format),
+		auctionInput.BuyItNowPrice,
+		dutchSchedule)
 	if err != nil {
 		return err
 	}
@@ -3,9 +3,11 @@ package auction_usecase
 import (
 	"context"
 	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/attachment_entity"
 	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/usecase/bid_usecase"
+	"time"
 )
 
 func (au *AuctionUseCase) FindAuctionById(
@@ -15,41 +17,62 @@ func (au *AuctionUseCase) FindAuctionById(
 		return nil, err
 	}
 
-	return &AuctionOutputDTO{
-		Id:          auctionEntity.Id,
-		ProductName: auctionEntity.ProductName,
-		Category:    auctionEntity.Category,
-		Description: auctionEntity.Description,
-		Condition:   ProductCondition(auctionEntity.Condition),
-		Status:      AuctionStatus(auctionEntity.Status),
-		Timestamp:   auctionEntity.Timestamp,
-	}, nil
+	auctionOutputDTO := toAuctionOutputDTO(auctionEntity, au.findAttachments(ctx, id)[id])
+	return &auctionOutputDTO, nil
 }
 
 func (au *AuctionUseCase) FindAuctions(
 	ctx context.Context,
-	status AuctionStatus,
-	category, productName string) ([]AuctionOutputDTO, *internal_error.InternalError) {
-	auctionEntities, err := au.auctionRepositoryInterface.FindAuctions(
-		ctx, auction_entity.AuctionStatus(status), category, productName)
+	filterInput AuctionFilterInputDTO) (*AuctionPageOutputDTO, *internal_error.InternalError) {
+	if filterInput.MinPrice != nil && filterInput.MaxPrice != nil &&
+		*filterInput.MinPrice > *filterInput.MaxPrice {
+		return nil, internal_error.NewValidationError("min_price must not be greater than max_price",
+			internal_error.Cause{Field: "min_price", Message: "must not be greater than max_price"})
+	}
+
+	filter := auction_entity.AuctionFilter{
+		Category:       filterInput.Category,
+		ProductName:    filterInput.ProductName,
+		MinPrice:       filterInput.MinPrice,
+		MaxPrice:       filterInput.MaxPrice,
+		EndingBefore:   filterInput.EndingBefore,
+		SortBy:         auction_entity.AuctionSortField(filterInput.Sort),
+		SortDescending: filterInput.Order != "asc",
+		Limit:          filterInput.Limit,
+		Cursor:         filterInput.Cursor,
+	}
+
+	if filterInput.Status != nil {
+		status := auction_entity.AuctionStatus(*filterInput.Status)
+		filter.Status = &status
+	}
+
+	if filterInput.Condition != nil {
+		condition := auction_entity.ProductCondition(*filterInput.Condition)
+		filter.Condition = &condition
+	}
+
+	auctionPage, err := au.auctionRepositoryInterface.FindAuctions(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var auctionOutputs []AuctionOutputDTO
-	for _, value := range auctionEntities {
-		auctionOutputs = append(auctionOutputs, AuctionOutputDTO{
-			Id:          value.Id,
-			ProductName: value.ProductName,
-			Category:    value.Category,
-			Description: value.Description,
-			Condition:   ProductCondition(value.Condition),
-			Status:      AuctionStatus(value.Status),
-			Timestamp:   value.Timestamp,
-		})
+	var auctionIds []string
+	for _, value := range auctionPage.Auctions {
+		auctionIds = append(auctionIds, value.Id)
+	}
+	attachments := au.findAttachments(ctx, auctionIds...)
+
+	auctionOutputs := []AuctionOutputDTO{}
+	for _, value := range auctionPage.Auctions {
+		auctionOutputs = append(auctionOutputs, toAuctionOutputDTO(&value, attachments[value.Id]))
 	}
 
-	return auctionOutputs, nil
+	return &AuctionPageOutputDTO{
+		Items:      auctionOutputs,
+		Total:      auctionPage.Total,
+		NextCursor: auctionPage.NextCursor,
+	}, nil
 }
 
 func (au *AuctionUseCase) FindWinningBidByAuctionId(
@@ -60,19 +83,18 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		return nil, err
 	}
 
-	auctionOutputDTO := AuctionOutputDTO{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   ProductCondition(auction.Condition),
-		Status:      AuctionStatus(auction.Status),
-		Timestamp:   auction.Timestamp,
+	auctionOutputDTO := toAuctionOutputDTO(auction, au.findAttachments(ctx, auction.Id)[auction.Id])
+
+	if auction.HighestBidId == "" || auction.BidsSealed() {
+		return &WinningInfoOutputDTO{
+			Auction: auctionOutputDTO,
+			Bid:     nil,
+		}, nil
 	}
 
 	bidWinning, err := au.bidRepositoryInterface.FindWinningBidByAuctionId(ctx, auction.Id)
 	if err != nil {
-		logger.Error("", err)
+		logger.ErrorContext(ctx, "", err)
 		return &WinningInfoOutputDTO{
 			Auction: auctionOutputDTO,
 			Bid:     nil,
@@ -84,6 +106,7 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		UserId:    bidWinning.UserId,
 		AuctionId: bidWinning.AuctionId,
 		Amount:    bidWinning.Amount,
+		Automatic: bidWinning.Automatic,
 		Timestamp: bidWinning.Timestamp,
 	}
 
@@ -92,3 +115,52 @@ func (au *AuctionUseCase) FindWinningBidByAuctionId(
 		Bid:     bidOutputDTO,
 	}, nil
 }
+
+// toAuctionOutputDTO shows the asking price of Dutch auctions and hides the
+// leading bid of sealed auctions until they end. The attachments are split
+// into images and documents.
+func toAuctionOutputDTO(
+	auctionEntity *auction_entity.Auction, attachments []attachment_entity.Attachment) AuctionOutputDTO {
+	auctionOutputDTO := AuctionOutputDTO{
+		Id:              auctionEntity.Id,
+		SellerId:        auctionEntity.SellerId,
+		ProductName:     auctionEntity.ProductName,
+		Category:        auctionEntity.Category,
+		Description:     auctionEntity.Description,
+		Condition:       ProductCondition(auctionEntity.Condition),
+		Status:          AuctionStatus(auctionEntity.Status),
+		Timestamp:       auctionEntity.Timestamp,
+		EndTime:         auctionEntity.EndTime,
+		CurrentPrice:    auctionEntity.AskingPrice(time.Now()),
+		HighestBidderId: auctionEntity.HighestBidderId,
+		BidCount:        auctionEntity.BidCount,
+		Format:          AuctionFormat(auctionEntity.Format),
+		BuyItNowPrice:   auctionEntity.BuyItNowPrice,
+		Images:          []AttachmentOutputDTO{},
+		Documents:       []AttachmentOutputDTO{},
+	}
+
+	for _, attachment := range attachments {
+		if attachment.Kind == attachment_entity.Image {
+			auctionOutputDTO.Images = append(auctionOutputDTO.Images, toAttachmentOutputDTO(attachment))
+		} else {
+			auctionOutputDTO.Documents = append(auctionOutputDTO.Documents, toAttachmentOutputDTO(attachment))
+		}
+	}
+
+	if auctionEntity.BidsSealed() {
+		auctionOutputDTO.CurrentPrice = 0
+		auctionOutputDTO.HighestBidderId = ""
+	}
+
+	if auctionEntity.Format == auction_entity.Dutch {
+		auctionOutputDTO.Dutch = &DutchScheduleDTO{
+			StartPrice:      auctionEntity.DutchSchedule.StartPrice,
+			Decrement:       auctionEntity.DutchSchedule.Decrement,
+			IntervalSeconds: int64(auctionEntity.DutchSchedule.Interval / time.Second),
+			FloorPrice:      auctionEntity.DutchSchedule.FloorPrice,
+		}
+	}
+
+	return auctionOutputDTO
+}
@@ -0,0 +1,155 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"html"
+	"regexp"
+	"strings"
+)
+
+type AuctionSearchInputDTO struct {
+	Query    string         `form:"q" binding:"required,min=2,max=100"`
+	Category string         `form:"category"`
+	Status   *AuctionStatus `form:"status" binding:"omitempty,oneof=0 1 2"`
+	Limit    int            `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset   int            `form:"offset" binding:"omitempty,min=0"`
+}
+
+type AuctionSearchItemOutputDTO struct {
+	AuctionOutputDTO
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+type CategoryFacetOutputDTO struct {
+	Category string `json:"category"`
+	Count    int64  `json:"count"`
+}
+
+type AuctionSearchOutputDTO struct {
+	Items      []AuctionSearchItemOutputDTO `json:"items"`
+	Total      int64                        `json:"total"`
+	Categories []CategoryFacetOutputDTO     `json:"categories"`
+}
+
+func (au *AuctionUseCase) SearchAuctions(
+	ctx context.Context,
+	searchInput AuctionSearchInputDTO) (*AuctionSearchOutputDTO, *internal_error.InternalError) {
+	search := auction_entity.AuctionSearch{
+		Query:    searchInput.Query,
+		Category: searchInput.Category,
+		Limit:    searchInput.Limit,
+		Offset:   searchInput.Offset,
+	}
+
+	if searchInput.Status != nil {
+		status := auction_entity.AuctionStatus(*searchInput.Status)
+		search.Status = &status
+	}
+
+	searchPage, err := au.auctionRepositoryInterface.SearchAuctions(ctx, search)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(searchInput.Query)
+
+	output := &AuctionSearchOutputDTO{
+		Items:      []AuctionSearchItemOutputDTO{},
+		Total:      searchPage.Total,
+		Categories: []CategoryFacetOutputDTO{},
+	}
+
+	var auctionIds []string
+	for _, result := range searchPage.Results {
+		auctionIds = append(auctionIds, result.Auction.Id)
+	}
+	attachments := au.findAttachments(ctx, auctionIds...)
+
+	for _, result := range searchPage.Results {
+		output.Items = append(output.Items, AuctionSearchItemOutputDTO{
+			AuctionOutputDTO: toAuctionOutputDTO(&result.Auction, attachments[result.Auction.Id]),
+			Score:            result.Score,
+			Highlights:       highlightAuction(result.Auction, terms),
+		})
+	}
+
+	for _, facet := range searchPage.Facets {
+		output.Categories = append(output.Categories, CategoryFacetOutputDTO{
+			Category: facet.Category,
+			Count:    facet.Count,
+		})
+	}
+
+	return output, nil
+}
+
+// searchTerms extracts the words of a MongoDB $search string, dropping
+// negated terms and the quotes around phrases.
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(strings.ReplaceAll(query, `"`, " ")) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		terms = append(terms, field)
+	}
+
+	return terms
+}
+
+func highlightAuction(auction auction_entity.Auction, terms []string) map[string]string {
+	highlights := make(map[string]string)
+	fields := map[string]string{
+		"product_name": auction.ProductName,
+		"category":     auction.Category,
+		"description":  auction.Description,
+	}
+
+	for field, value := range fields {
+		if highlighted, ok := highlight(value, terms); ok {
+			highlights[field] = highlighted
+		}
+	}
+
+	return highlights
+}
+
+// highlight wraps every word starting with one of the terms in <mark> tags.
+// Matching by prefix approximates the stemming done by the text index, so
+// searching "notebook" also marks "notebooks". The text is written by sellers,
+// so it is HTML-escaped and the only markup in the result is the tags added
+// here. Matching runs on the raw text so terms never match inside an entity.
+func highlight(text string, terms []string) (string, bool) {
+	if len(terms) == 0 {
+		return text, false
+	}
+
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+
+	pattern := regexp.MustCompile(
+		`(?i)(^|[^\p{L}\p{N}])((?:` + strings.Join(quoted, "|") + `)[\p{L}\p{N}]*)`)
+	matches := pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return html.EscapeString(text), false
+	}
+
+	var highlighted strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[4], match[5]
+		highlighted.WriteString(html.EscapeString(text[last:start]))
+		highlighted.WriteString("<mark>")
+		highlighted.WriteString(html.EscapeString(text[start:end]))
+		highlighted.WriteString("</mark>")
+		last = end
+	}
+	highlighted.WriteString(html.EscapeString(text[last:]))
+
+	return highlighted.String(), true
+}
@@ -0,0 +1,80 @@
+package auction_usecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	terms := searchTerms(`notebook "dell inspiron" -usado`)
+	expected := []string{"notebook", "dell", "inspiron"}
+
+	if !reflect.DeepEqual(terms, expected) {
+		t.Errorf("Expected %v, got %v", expected, terms)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		terms         []string
+		expectedText  string
+		expectedMatch bool
+	}{
+		{
+			name:          "marks whole words case-insensitively",
+			text:          "Notebook Dell Inspiron",
+			terms:         []string{"notebook", "dell"},
+			expectedText:  "<mark>Notebook</mark> <mark>Dell</mark> Inspiron",
+			expectedMatch: true,
+		},
+		{
+			name:          "marks words starting with the term",
+			text:          "Dois notebooks usados",
+			terms:         []string{"notebook"},
+			expectedText:  "Dois <mark>notebooks</mark> usados",
+			expectedMatch: true,
+		},
+		{
+			name:          "handles accented words",
+			text:          "Eletrônicos e eletrodomésticos",
+			terms:         []string{"eletrônicos"},
+			expectedText:  "<mark>Eletrônicos</mark> e eletrodomésticos",
+			expectedMatch: true,
+		},
+		{
+			name:          "does not mark the middle of a word",
+			text:          "Ultrabook",
+			terms:         []string{"book"},
+			expectedText:  "Ultrabook",
+			expectedMatch: false,
+		},
+		{
+			name:          "escapes markup written by the seller",
+			text:          `Notebook <script>alert("x")</script>`,
+			terms:         []string{"notebook", "script"},
+			expectedText:  "<mark>Notebook</mark> &lt;<mark>script</mark>&gt;alert(&#34;x&#34;)&lt;/<mark>script</mark>&gt;",
+			expectedMatch: true,
+		},
+		{
+			name:          "does not mark inside escaped entities",
+			text:          "Tom & Jerry",
+			terms:         []string{"amp", "tom"},
+			expectedText:  "<mark>Tom</mark> &amp; Jerry",
+			expectedMatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, matched := highlight(tt.text, tt.terms)
+			if matched != tt.expectedMatch {
+				t.Errorf("Expected match %v, got %v", tt.expectedMatch, matched)
+			}
+			if text != tt.expectedText {
+				t.Errorf("Expected %q, got %q", tt.expectedText, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "fullcycle-auction_go/internal/usecase/auction_usecase"
+
+// ObserveActiveAuctions reports the number of active auctions on every metric
+// collection. The count comes from the repository, so it is shared by every
+// replica and survives restarts.
+func ObserveActiveAuctions(
+	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface) error {
+	activeStatus := auction_entity.Active
+
+	_, err := otel.Meter(instrumentationName).Int64ObservableGauge("auction.active",
+		metric.WithDescription("Auctions currently accepting bids"),
+		metric.WithUnit("{auction}"),
+		metric.WithInt64Callback(func(ctx context.Context, observer metric.Int64Observer) error {
+			page, err := auctionRepositoryInterface.FindAuctions(ctx, auction_entity.AuctionFilter{
+				Status: &activeStatus,
+				Limit:  1,
+			})
+			if err != nil {
+				return err
+			}
+
+			observer.Observe(page.Total)
+			return nil
+		}))
+
+	return err
+}
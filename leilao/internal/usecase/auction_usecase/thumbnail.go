@@ -0,0 +1,105 @@
+package auction_usecase
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	// thumbnailSize is the largest side of a thumbnail, in pixels.
+	thumbnailSize = 256
+	// maxImagePixels bounds the images decoded for thumbnails, so a small
+	// file declaring huge dimensions cannot exhaust the memory.
+	maxImagePixels = 40_000_000
+	// maxSamplesPerSide bounds the source pixels averaged into each side of
+	// a thumbnail pixel.
+	maxSamplesPerSide = 4
+)
+
+// generateThumbnail decodes a JPEG, PNG or GIF image and encodes a JPEG that
+// fits in a thumbnailSize square with the same aspect ratio. Smaller images
+// are not scaled up, and transparent pixels are drawn over white.
+func generateThumbnail(content []byte) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 ||
+		int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return nil, fmt.Errorf("image of %dx%d pixels is too large", config.Width, config.Height)
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, downscale(source, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// downscale averages the source pixels covered by each pixel of the result,
+// sampling at most maxSamplesPerSide of them in each direction.
+func downscale(source image.Image, maxSide int) *image.RGBA {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	scaledWidth, scaledHeight := width, height
+	if width >= height && width > maxSide {
+		scaledWidth, scaledHeight = maxSide, (height*maxSide+width-1)/width
+	} else if height > width && height > maxSide {
+		scaledWidth, scaledHeight = (width*maxSide+height-1)/height, maxSide
+	}
+
+	// Each result pixel covers at least one source pixel because the result
+	// is never larger than the source.
+	scaled := image.NewRGBA(image.Rect(0, 0, scaledWidth, scaledHeight))
+	for y := 0; y < scaledHeight; y++ {
+		top, bottom := y*height/scaledHeight, (y+1)*height/scaledHeight
+		for x := 0; x < scaledWidth; x++ {
+			left, right := x*width/scaledWidth, (x+1)*width/scaledWidth
+			scaled.SetRGBA(x, y, averageOverWhite(source, bounds.Min, left, right, top, bottom))
+		}
+	}
+
+	return scaled
+}
+
+func averageOverWhite(source image.Image, origin image.Point, left, right, top, bottom int) color.RGBA {
+	columns, rows := right-left, bottom-top
+	if columns > maxSamplesPerSide {
+		columns = maxSamplesPerSide
+	}
+	if rows > maxSamplesPerSide {
+		rows = maxSamplesPerSide
+	}
+
+	var red, green, blue uint64
+	for row := 0; row < rows; row++ {
+		sourceY := origin.Y + top + row*(bottom-top)/rows
+		for column := 0; column < columns; column++ {
+			sourceX := origin.X + left + column*(right-left)/columns
+			r, g, b, a := source.At(sourceX, sourceY).RGBA()
+			red += uint64(r + 0xffff - a)
+			green += uint64(g + 0xffff - a)
+			blue += uint64(b + 0xffff - a)
+		}
+	}
+
+	samples := uint64(columns * rows)
+	return color.RGBA{
+		R: uint8(red / samples >> 8),
+		G: uint8(green / samples >> 8),
+		B: uint8(blue / samples >> 8),
+		A: 0xff,
+	}
+}
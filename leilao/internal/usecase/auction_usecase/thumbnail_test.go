@@ -0,0 +1,65 @@
+package auction_usecase
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestGenerateThumbnail(t *testing.T) {
+	tests := []struct {
+		name           string
+		width, height  int
+		expectedWidth  int
+		expectedHeight int
+	}{
+		{name: "landscape fits the width", width: 1000, height: 500, expectedWidth: 256, expectedHeight: 128},
+		{name: "portrait fits the height", width: 300, height: 900, expectedWidth: 86, expectedHeight: 256},
+		{name: "small images are not scaled up", width: 40, height: 30, expectedWidth: 40, expectedHeight: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var content bytes.Buffer
+			png.Encode(&content, image.NewNRGBA(image.Rect(0, 0, tt.width, tt.height)))
+
+			thumbnail, err := generateThumbnail(content.Bytes())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			decoded, err := jpeg.Decode(bytes.NewReader(thumbnail))
+			if err != nil {
+				t.Fatalf("Expected a JPEG thumbnail, got %v", err)
+			}
+
+			bounds := decoded.Bounds()
+			if bounds.Dx() != tt.expectedWidth || bounds.Dy() != tt.expectedHeight {
+				t.Errorf("Expected %dx%d, got %dx%d",
+					tt.expectedWidth, tt.expectedHeight, bounds.Dx(), bounds.Dy())
+			}
+
+			if r, g, b, _ := decoded.At(0, 0).RGBA(); r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+				t.Errorf("Expected transparent pixels to turn white, got %v", color.RGBA64{
+					R: uint16(r), G: uint16(g), B: uint16(b), A: 0xffff})
+			}
+		})
+	}
+}
+
+func TestGenerateThumbnailRefusesHugeImages(t *testing.T) {
+	var content bytes.Buffer
+	gif.Encode(&content, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.Black}), nil)
+
+	// The logical screen size of a GIF follows its 6 byte signature.
+	huge := content.Bytes()
+	copy(huge[6:10], []byte{0xff, 0xff, 0xff, 0xff})
+
+	if _, err := generateThumbnail(huge); err == nil {
+		t.Error("Expected an image declaring 65535x65535 pixels to be refused")
+	}
+}
@@ -0,0 +1,79 @@
+package auction_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+type AuctionUpdateInputDTO struct {
+	ProductName *string           `json:"product_name" binding:"omitempty,min=1"`
+	Category    *string           `json:"category" binding:"omitempty,min=2"`
+	Description *string           `json:"description" binding:"omitempty,min=10,max=200"`
+	Condition   *ProductCondition `json:"condition" binding:"omitempty,oneof=0 1 2 3"`
+}
+
+// RequesterDTO identifies the authenticated user acting on an auction.
+type RequesterDTO struct {
+	UserId  string
+	IsAdmin bool
+}
+
+func (au *AuctionUseCase) UpdateAuction(
+	ctx context.Context,
+	auctionId string,
+	requester RequesterDTO,
+	auctionInput AuctionUpdateInputDTO) (*AuctionOutputDTO, *internal_error.InternalError) {
+	auctionEntity, err := au.findManagedAuction(ctx, auctionId, requester)
+	if err != nil {
+		return nil, err
+	}
+
+	var condition *auction_entity.ProductCondition
+	if auctionInput.Condition != nil {
+		value := auction_entity.ProductCondition(*auctionInput.Condition)
+		condition = &value
+	}
+
+	if err := auctionEntity.Update(
+		auctionInput.ProductName,
+		auctionInput.Category,
+		auctionInput.Description,
+		condition); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepositoryInterface.UpdateAuction(ctx, auctionEntity); err != nil {
+		return nil, err
+	}
+
+	auctionOutputDTO := toAuctionOutputDTO(auctionEntity, au.findAttachments(ctx, auctionId)[auctionId])
+	return &auctionOutputDTO, nil
+}
+
+func (au *AuctionUseCase) CancelAuction(
+	ctx context.Context,
+	auctionId string,
+	requester RequesterDTO) *internal_error.InternalError {
+	if _, err := au.findManagedAuction(ctx, auctionId, requester); err != nil {
+		return err
+	}
+
+	return au.auctionRepositoryInterface.CancelAuction(ctx, auctionId)
+}
+
+func (au *AuctionUseCase) findManagedAuction(
+	ctx context.Context,
+	auctionId string,
+	requester RequesterDTO) (*auction_entity.Auction, *internal_error.InternalError) {
+	auctionEntity, err := au.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auctionEntity.CanBeManagedBy(requester.UserId, requester.IsAdmin) {
+		return nil, internal_error.NewForbiddenError("Only the seller or an admin can manage this auction")
+	}
+
+	return auctionEntity, nil
+}
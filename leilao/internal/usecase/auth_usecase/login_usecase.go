@@ -0,0 +1,75 @@
+package auth_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+	"time"
+)
+
+type LoginInputDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type LoginOutputDTO struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type TokenGenerator interface {
+	GenerateToken(
+		userId string, role user_entity.UserRole) (string, time.Time, error)
+}
+
+type AuthUseCaseInterface interface {
+	Login(
+		ctx context.Context,
+		loginInput LoginInputDTO) (*LoginOutputDTO, *internal_error.InternalError)
+}
+
+type AuthUseCase struct {
+	userRepository user_entity.UserRepositoryInterface
+	tokenGenerator TokenGenerator
+}
+
+func NewAuthUseCase(
+	userRepository user_entity.UserRepositoryInterface,
+	tokenGenerator TokenGenerator) AuthUseCaseInterface {
+	return &AuthUseCase{
+		userRepository: userRepository,
+		tokenGenerator: tokenGenerator,
+	}
+}
+
+func (au *AuthUseCase) Login(
+	ctx context.Context,
+	loginInput LoginInputDTO) (*LoginOutputDTO, *internal_error.InternalError) {
+	userEntity, err := au.userRepository.FindUserByEmail(
+		ctx, strings.ToLower(strings.TrimSpace(loginInput.Email)))
+	if err != nil {
+		if err.Code == internal_error.NotFound {
+			return nil, internal_error.NewUnauthorizedError("Invalid email or password")
+		}
+		return nil, err
+	}
+
+	if !userEntity.Active || !userEntity.CheckPassword(loginInput.Password) {
+		return nil, internal_error.NewUnauthorizedError("Invalid email or password")
+	}
+
+	token, expiresAt, errToken := au.tokenGenerator.GenerateToken(userEntity.Id, userEntity.Role)
+	if errToken != nil {
+		logger.ErrorContext(ctx, "Error trying to generate access token", errToken)
+		return nil, internal_error.NewInternalServerError("Error trying to generate access token").Wrap(errToken)
+	}
+
+	return &LoginOutputDTO{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   expiresAt,
+	}, nil
+}
@@ -3,15 +3,22 @@ package bid_usecase
 import (
 	"context"
 	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
 	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
 	"fullcycle-auction_go/internal/internal_error"
-	"os"
-	"strconv"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 type BidInputDTO struct {
-	UserId    string  `json:"user_id"`
+	UserId    string  `json:"-"`
 	AuctionId string  `json:"auction_id"`
 	Amount    float64 `json:"amount"`
 }
@@ -19,39 +26,112 @@ type BidInputDTO struct {
 type BidOutputDTO struct {
 	Id        string    `json:"id"`
 	UserId    string    `json:"user_id"`
+	UserName  string    `json:"user_name,omitempty"`
 	AuctionId string    `json:"auction_id"`
 	Amount    float64   `json:"amount"`
+	Automatic bool      `json:"automatic"`
 	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
 }
 
+type BidFilterInputDTO struct {
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type BidStatsOutputDTO struct {
+	Count         int64      `json:"count"`
+	Bidders       int64      `json:"bidders"`
+	Auctions      int64      `json:"auctions"`
+	HighestAmount float64    `json:"highest_amount"`
+	AverageAmount float64    `json:"average_amount"`
+	FirstBidAt    *time.Time `json:"first_bid_at,omitempty"`
+	LastBidAt     *time.Time `json:"last_bid_at,omitempty"`
+}
+
+type BidPageOutputDTO struct {
+	Items      []BidOutputDTO    `json:"items"`
+	Stats      BidStatsOutputDTO `json:"stats"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// BidLog durably keeps the bids acknowledged to clients until the batch
+// holding them is stored, so they can be replayed after a crash.
+type BidLog interface {
+	Append(bid bid_entity.Bid) error
+	Commit(bids []bid_entity.Bid) error
+	Pending() []bid_entity.Bid
+}
+
 type BidUseCase struct {
-	BidRepository bid_entity.BidEntityRepository
+	BidRepository      bid_entity.BidEntityRepository
+	AuctionRepository  auction_entity.AuctionRepositoryInterface
+	ProxyBidRepository proxy_bid_entity.ProxyBidRepositoryInterface
+	UserRepository     user_entity.UserRepositoryInterface
+	BidLog             BidLog
 
 	timer               *time.Timer
 	maxBatchSize        int
 	batchInsertInterval time.Duration
+	batchTimeout        time.Duration
 	bidChannel          chan bid_entity.Bid
+	minBidIncrement     float64
+	proxyBidMutex       *sync.Mutex
+	metrics             *bidMetrics
+
+	outbidListeners      []func(event bid_entity.OutbidEvent)
+	outbidListenersMutex *sync.RWMutex
+
+	// bidChannelMutex guards shuttingDown so no bid is sent on bidChannel
+	// after Shutdown closes it.
+	bidChannelMutex *sync.RWMutex
+	shuttingDown    bool
+	batchDone       chan struct{}
 }
 
-func NewBidUseCase(bidRepository bid_entity.BidEntityRepository) BidUseCaseInterface {
-	maxSizeInterval := getMaxBatchSizeInterval()
-	maxBatchSize := getMaxBatchSize()
+// Options controls the batching of bids: a batch is stored once it holds
+// MaxBatchSize bids or BatchInsertInterval after its first bid.
+type Options struct {
+	BatchInsertInterval time.Duration
+	MaxBatchSize        int
+	BatchTimeout        time.Duration
+	MinBidIncrement     float64
+}
 
+func NewBidUseCase(
+	bidRepository bid_entity.BidEntityRepository,
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	proxyBidRepository proxy_bid_entity.ProxyBidRepositoryInterface,
+	userRepository user_entity.UserRepositoryInterface,
+	bidLog BidLog,
+	options Options) BidUseCaseInterface {
 	bidUseCase := &BidUseCase{
-		BidRepository:       bidRepository,
-		maxBatchSize:        maxBatchSize,
-		batchInsertInterval: maxSizeInterval,
-		timer:               time.NewTimer(maxSizeInterval),
-		bidChannel:          make(chan bid_entity.Bid, maxBatchSize),
+		BidRepository:        bidRepository,
+		AuctionRepository:    auctionRepository,
+		ProxyBidRepository:   proxyBidRepository,
+		UserRepository:       userRepository,
+		BidLog:               bidLog,
+		minBidIncrement:      options.MinBidIncrement,
+		proxyBidMutex:        &sync.Mutex{},
+		metrics:              newBidMetrics(),
+		outbidListenersMutex: &sync.RWMutex{},
+		maxBatchSize:         options.MaxBatchSize,
+		batchInsertInterval:  options.BatchInsertInterval,
+		batchTimeout:         options.BatchTimeout,
+		timer:                time.NewTimer(options.BatchInsertInterval),
+		bidChannel:           make(chan bid_entity.Bid, options.MaxBatchSize),
+		bidChannelMutex:      &sync.RWMutex{},
+		batchDone:            make(chan struct{}),
 	}
 
-	bidUseCase.triggerCreateRoutine(context.Background())
+	// Pending bids are read before any new bid can reach the log, so only
+	// the bids left by a previous run are replayed. Batches outlive the
+	// requests that filled them, so they run on their own context.
+	bidUseCase.triggerCreateRoutine(context.Background(), bidLog.Pending())
 
 	return bidUseCase
 }
 
-var bidBatch []bid_entity.Bid
-
 type BidUseCaseInterface interface {
 	CreateBid(
 		ctx context.Context,
@@ -61,39 +141,51 @@ type BidUseCaseInterface interface {
 		ctx context.Context, auctionId string) (*BidOutputDTO, *internal_error.InternalError)
 
 	FindBidByAuctionId(
-		ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError)
+		ctx context.Context,
+		auctionId string, filterInput BidFilterInputDTO) (*BidPageOutputDTO, *internal_error.InternalError)
+
+	FindBidsByUserId(
+		ctx context.Context,
+		userId string, filterInput BidFilterInputDTO) (*BidPageOutputDTO, *internal_error.InternalError)
+
+	CreateProxyBid(
+		ctx context.Context,
+		proxyBidInputDTO ProxyBidInputDTO) *internal_error.InternalError
+
+	OnOutbid(listener func(event bid_entity.OutbidEvent))
+
+	Shutdown(ctx context.Context) error
 }
 
-func (bu *BidUseCase) triggerCreateRoutine(ctx context.Context) {
+func (bu *BidUseCase) triggerCreateRoutine(ctx context.Context, pendingBids []bid_entity.Bid) {
 	go func() {
-		defer close(bu.bidChannel)
+		defer close(bu.batchDone)
+
+		if len(pendingBids) > 0 {
+			logger.InfoContext(ctx, "Replaying bids from the write-ahead log", zap.Int("count", len(pendingBids)))
+			bu.processBatch(ctx, pendingBids)
+		}
 
+		var bidBatch []bid_entity.Bid
 		for {
 			select {
 			case bidEntity, ok := <-bu.bidChannel:
 				if !ok {
-					if len(bidBatch) > 0 {
-						if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-							logger.Error("error trying to process bid batch list", err)
-						}
-					}
+					bu.timer.Stop()
+					bu.processBatch(ctx, bidBatch)
 					return
 				}
 
 				bidBatch = append(bidBatch, bidEntity)
 
 				if len(bidBatch) >= bu.maxBatchSize {
-					if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-						logger.Error("error trying to process bid batch list", err)
-					}
+					bu.processBatch(ctx, bidBatch)
 
 					bidBatch = nil
 					bu.timer.Reset(bu.batchInsertInterval)
 				}
 			case <-bu.timer.C:
-				if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-					logger.Error("error trying to process bid batch list", err)
-				}
+				bu.processBatch(ctx, bidBatch)
 				bidBatch = nil
 				bu.timer.Reset(bu.batchInsertInterval)
 			}
@@ -101,6 +193,105 @@ func (bu *BidUseCase) triggerCreateRoutine(ctx context.Context) {
 	}()
 }
 
+// Shutdown stops accepting bids, drains bidChannel and waits until the last
+// batch has been written, or until ctx is done.
+func (bu *BidUseCase) Shutdown(ctx context.Context) error {
+	bu.bidChannelMutex.Lock()
+	if !bu.shuttingDown {
+		bu.shuttingDown = true
+		close(bu.bidChannel)
+	}
+	bu.bidChannelMutex.Unlock()
+
+	select {
+	case <-bu.batchDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// processBatch stores a batch of bids, lets the proxies of every auction
+// touched by it answer with automatic bids and reports the users outbid. Bids
+// that failed for a transient reason are left uncommitted in the write-ahead
+// log so they are replayed on the next start; rejected bids are committed like
+// stored ones.
+func (bu *BidUseCase) processBatch(ctx context.Context, batch []bid_entity.Bid) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bu.batchTimeout)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "BidUseCase.processBatch",
+		trace.WithAttributes(attribute.Int("bid.batch_size", len(batch))))
+	defer span.End()
+
+	auctionIds := make([]string, 0, len(batch))
+	seenAuctions := make(map[string]bool)
+	for _, bid := range batch {
+		if !seenAuctions[bid.AuctionId] {
+			seenAuctions[bid.AuctionId] = true
+			auctionIds = append(auctionIds, bid.AuctionId)
+		}
+	}
+
+	notifyOutbid := bu.hasOutbidListeners()
+	previousLeaders := make(map[string]string)
+	if notifyOutbid {
+		for _, auctionId := range auctionIds {
+			previousLeaders[auctionId] = bu.findLeader(logger.WithAuctionId(ctx, auctionId), auctionId)
+		}
+	}
+
+	start := time.Now()
+	failures, err := bu.BidRepository.CreateBid(ctx, batch)
+	if err != nil {
+		logger.ErrorContext(ctx, "error trying to process bid batch list", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	bu.metrics.recordBatch(ctx, len(batch), time.Since(start), failures, err == nil)
+	span.SetAttributes(attribute.Int("bid.failures", len(failures)))
+
+	retry := make(map[int]bool)
+	for _, failure := range failures {
+		logger.InfoContext(ctx, "Bid not stored",
+			zap.String("bid_id", failure.BidId),
+			zap.String("reason", string(failure.Reason)))
+
+		if failure.Retryable() {
+			retry[failure.Index] = true
+		}
+	}
+
+	committed := make([]bid_entity.Bid, 0, len(batch))
+	for index, bid := range batch {
+		if !retry[index] {
+			committed = append(committed, bid)
+		}
+	}
+
+	if err := bu.BidLog.Commit(committed); err != nil {
+		logger.ErrorContext(ctx, "error trying to commit bid batch to the write-ahead log", err)
+	}
+
+	stored := storedBids(batch, failures)
+	if err != nil {
+		stored = nil
+	}
+
+	for _, auctionId := range auctionIds {
+		auctionCtx := logger.WithAuctionId(ctx, auctionId)
+		automaticBids := bu.resolveProxyBids(auctionCtx, auctionId)
+
+		if notifyOutbid {
+			bu.emitOutbid(auctionCtx, auctionId, previousLeaders[auctionId], append(stored, automaticBids...))
+		}
+	}
+}
+
 func (bu *BidUseCase) CreateBid(
 	ctx context.Context,
 	bidInputDTO BidInputDTO) *internal_error.InternalError {
@@ -110,26 +301,46 @@ func (bu *BidUseCase) CreateBid(
 		return err
 	}
 
+	if err := bu.validateBidder(ctx, bidEntity.UserId); err != nil {
+		return err
+	}
+
+	bu.bidChannelMutex.RLock()
+	defer bu.bidChannelMutex.RUnlock()
+
+	if bu.shuttingDown {
+		return internal_error.NewUnavailableError("Bids are not being accepted, the server is shutting down")
+	}
+
+	// Once acknowledged a bid is stored even if the client goes away, so a
+	// request that was already cancelled must not reach the log.
+	if err := ctx.Err(); err != nil {
+		return internal_error.NewUnavailableError("Request was cancelled before the bid was recorded").Wrap(err)
+	}
+
+	if err := bu.BidLog.Append(*bidEntity); err != nil {
+		logger.ErrorContext(ctx, "error trying to append bid to the write-ahead log", err)
+		return internal_error.NewInternalServerError("Error trying to record bid").Wrap(err)
+	}
+
 	bu.bidChannel <- *bidEntity
 
 	return nil
 }
 
-func getMaxBatchSizeInterval() time.Duration {
-	batchInsertInterval := os.Getenv("BATCH_INSERT_INTERVAL")
-	duration, err := time.ParseDuration(batchInsertInterval)
+// validateBidder rejects bids from users that do not exist or were deactivated.
+func (bu *BidUseCase) validateBidder(ctx context.Context, userId string) *internal_error.InternalError {
+	userEntity, err := bu.UserRepository.FindUserById(ctx, userId)
 	if err != nil {
-		return 3 * time.Minute
+		if err.Code == internal_error.NotFound {
+			return internal_error.NewBadRequestError("UserId does not refer to an existing user")
+		}
+		return err
 	}
 
-	return duration
-}
-
-func getMaxBatchSize() int {
-	value, err := strconv.Atoi(os.Getenv("MAX_BATCH_SIZE"))
-	if err != nil {
-		return 5
+	if !userEntity.Active {
+		return internal_error.NewForbiddenError("UserId refers to a deactivated user")
 	}
 
-	return value
+	return nil
 }
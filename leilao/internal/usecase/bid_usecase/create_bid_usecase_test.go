@@ -0,0 +1,311 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type fakeBidRepository struct {
+	bid_entity.BidEntityRepository
+	mu       sync.Mutex
+	bids     []bid_entity.Bid
+	failures map[string]bid_entity.BidFailureReason
+}
+
+func (f *fakeBidRepository) CreateBid(
+	ctx context.Context, bidEntities []bid_entity.Bid) ([]bid_entity.BidFailure, *internal_error.InternalError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var failures []bid_entity.BidFailure
+	for index, bid := range bidEntities {
+		if reason, ok := f.failures[bid.Id]; ok {
+			failures = append(failures, bid_entity.BidFailure{Index: index, BidId: bid.Id, Reason: reason})
+			continue
+		}
+		f.bids = append(f.bids, bid)
+	}
+	return failures, nil
+}
+
+func (f *fakeBidRepository) FindWinningBidByAuctionId(
+	ctx context.Context, auctionId string) (*bid_entity.Bid, *internal_error.InternalError) {
+	return nil, internal_error.NewNotFoundError("No bids found")
+}
+
+func (f *fakeBidRepository) FindBids(
+	ctx context.Context, filter bid_entity.BidFilter) (*bid_entity.BidPage, *internal_error.InternalError) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	page := &bid_entity.BidPage{}
+	for _, bid := range f.bids {
+		if bid.AuctionId == filter.AuctionId {
+			page.Bids = append(page.Bids, bid)
+		}
+	}
+	return page, nil
+}
+
+type fakeAuctionRepository struct {
+	auction_entity.AuctionRepositoryInterface
+	auctions map[string]*auction_entity.Auction
+}
+
+func (f *fakeAuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	auctionEntity, ok := f.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Auction not found")
+	}
+	return auctionEntity, nil
+}
+
+type fakeProxyBidRepository struct {
+	proxy_bid_entity.ProxyBidRepositoryInterface
+	proxyBids []proxy_bid_entity.ProxyBid
+}
+
+func (f *fakeProxyBidRepository) FindProxyBidsByAuctionId(
+	ctx context.Context, auctionId string) ([]proxy_bid_entity.ProxyBid, *internal_error.InternalError) {
+	return f.proxyBids, nil
+}
+
+func (f *fakeProxyBidRepository) CreateProxyBid(
+	ctx context.Context, proxyBidEntity *proxy_bid_entity.ProxyBid) *internal_error.InternalError {
+	f.proxyBids = append(f.proxyBids, *proxyBidEntity)
+	return nil
+}
+
+// fakeUserRepository treats every user as active unless listed as missing or
+// deactivated.
+type fakeUserRepository struct {
+	user_entity.UserRepositoryInterface
+	missing     map[string]bool
+	deactivated map[string]bool
+}
+
+func (f *fakeUserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	if f.missing[userId] {
+		return nil, internal_error.NewNotFoundError("User not found")
+	}
+	return &user_entity.User{Id: userId, Active: !f.deactivated[userId]}, nil
+}
+
+type fakeBidLog struct {
+	mu      sync.Mutex
+	pending map[string]bid_entity.Bid
+}
+
+func newFakeBidLog(bids ...bid_entity.Bid) *fakeBidLog {
+	bidLog := &fakeBidLog{pending: make(map[string]bid_entity.Bid)}
+	for _, bid := range bids {
+		bidLog.pending[bid.Id] = bid
+	}
+	return bidLog
+}
+
+func (f *fakeBidLog) Append(bid bid_entity.Bid) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.pending[bid.Id] = bid
+	return nil
+}
+
+func (f *fakeBidLog) Commit(bids []bid_entity.Bid) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, bid := range bids {
+		delete(f.pending, bid.Id)
+	}
+	return nil
+}
+
+func (f *fakeBidLog) Pending() []bid_entity.Bid {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var bids []bid_entity.Bid
+	for _, bid := range f.pending {
+		bids = append(bids, bid)
+	}
+	return bids
+}
+
+// testOptions only stores batches on shutdown or replay.
+var testOptions = Options{
+	BatchInsertInterval: time.Hour,
+	MaxBatchSize:        10,
+	BatchTimeout:        30 * time.Second,
+	MinBidIncrement:     1,
+}
+
+func TestShutdownFlushesPendingBids(t *testing.T) {
+	bidRepository := &fakeBidRepository{}
+	bidLog := newFakeBidLog()
+	bidUseCase := NewBidUseCase(bidRepository, &fakeAuctionRepository{}, &fakeProxyBidRepository{}, &fakeUserRepository{}, bidLog, testOptions)
+
+	auctionId := uuid.New().String()
+	for _, amount := range []float64{10, 20, 30} {
+		if err := bidUseCase.CreateBid(context.Background(), BidInputDTO{
+			UserId: uuid.New().String(), AuctionId: auctionId, Amount: amount,
+		}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if err := bidUseCase.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+
+	if len(bidRepository.bids) != 3 {
+		t.Fatalf("Expected the 3 buffered bids to be flushed, got %d", len(bidRepository.bids))
+	}
+	if len(bidLog.Pending()) != 0 {
+		t.Errorf("Expected the flushed bids to be committed, got %d pending", len(bidLog.Pending()))
+	}
+
+	err := bidUseCase.CreateBid(context.Background(), BidInputDTO{
+		UserId: uuid.New().String(), AuctionId: auctionId, Amount: 40,
+	})
+	if err == nil {
+		t.Error("Expected bids to be refused after shutdown")
+	}
+
+	if err := bidUseCase.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected a second shutdown to be harmless, got %v", err)
+	}
+}
+
+func TestNewBidUseCaseReplaysPendingBids(t *testing.T) {
+	pendingBid, _ := bid_entity.CreateBid(uuid.New().String(), uuid.New().String(), 10)
+	bidLog := newFakeBidLog(*pendingBid)
+	bidRepository := &fakeBidRepository{}
+
+	bidUseCase := NewBidUseCase(bidRepository, &fakeAuctionRepository{}, &fakeProxyBidRepository{}, &fakeUserRepository{}, bidLog, testOptions)
+	if err := bidUseCase.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+
+	if len(bidRepository.bids) != 1 || bidRepository.bids[0].Id != pendingBid.Id {
+		t.Fatalf("Expected the pending bid to be replayed, got %+v", bidRepository.bids)
+	}
+	if len(bidLog.Pending()) != 0 {
+		t.Errorf("Expected the replayed bid to be committed")
+	}
+}
+
+func TestProcessBatchKeepsRetryableFailuresPending(t *testing.T) {
+	auctionId := uuid.New().String()
+	stored, _ := bid_entity.CreateBid(uuid.New().String(), auctionId, 30)
+	rejected, _ := bid_entity.CreateBid(uuid.New().String(), auctionId, 10)
+	failed, _ := bid_entity.CreateBid(uuid.New().String(), auctionId, 40)
+
+	bidLog := newFakeBidLog(*stored, *rejected, *failed)
+	bidRepository := &fakeBidRepository{failures: map[string]bid_entity.BidFailureReason{
+		rejected.Id: bid_entity.BidRejected,
+		failed.Id:   bid_entity.InsertFailed,
+	}}
+
+	bidUseCase := NewBidUseCase(bidRepository, &fakeAuctionRepository{}, &fakeProxyBidRepository{}, &fakeUserRepository{}, bidLog, testOptions)
+	if err := bidUseCase.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+
+	pending := bidLog.Pending()
+	if len(pending) != 1 || pending[0].Id != failed.Id {
+		t.Errorf("Expected only the bid that failed to insert to stay pending, got %+v", pending)
+	}
+}
+
+func TestResolveProxyBidsSkipsMissingAndDeactivatedOwners(t *testing.T) {
+	auctionId := uuid.New().String()
+	active, deactivated, missing := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	var proxyBids []proxy_bid_entity.ProxyBid
+	for userId, maxAmount := range map[string]float64{active: 50, deactivated: 100, missing: 80} {
+		proxyBid, _ := proxy_bid_entity.CreateProxyBid(userId, auctionId, maxAmount)
+		proxyBids = append(proxyBids, *proxyBid)
+	}
+
+	bidRepository := &fakeBidRepository{}
+	bidUseCase := NewBidUseCase(bidRepository, &fakeAuctionRepository{},
+		&fakeProxyBidRepository{proxyBids: proxyBids},
+		&fakeUserRepository{missing: map[string]bool{missing: true}, deactivated: map[string]bool{deactivated: true}},
+		newFakeBidLog(), testOptions).(*BidUseCase)
+	defer bidUseCase.Shutdown(context.Background())
+
+	automaticBids := bidUseCase.resolveProxyBids(context.Background(), auctionId)
+
+	if len(automaticBids) != 1 || automaticBids[0].UserId != active || automaticBids[0].Amount != 1 {
+		t.Errorf("Expected only the active user's proxy to bid the minimum increment, got %+v", automaticBids)
+	}
+}
+
+func TestCreateProxyBidRequiresAnOpenAuctionAndTheAskingPrice(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		auction   auction_entity.Auction
+		maxAmount float64
+		expected  internal_error.ErrorCode
+	}{
+		{"open auction", auction_entity.Auction{Status: auction_entity.Active, EndTime: now.Add(time.Hour), CurrentPrice: 50},
+			60, ""},
+		{"max at the asking price", auction_entity.Auction{Status: auction_entity.Active, EndTime: now.Add(time.Hour), CurrentPrice: 50},
+			50, ""},
+		{"max below the asking price", auction_entity.Auction{Status: auction_entity.Active, EndTime: now.Add(time.Hour), CurrentPrice: 50},
+			40, internal_error.BadRequest},
+		{"closed auction", auction_entity.Auction{Status: auction_entity.Completed, EndTime: now.Add(time.Hour)},
+			60, internal_error.BadRequest},
+		{"ended auction", auction_entity.Auction{Status: auction_entity.Active, EndTime: now.Add(-time.Minute)},
+			60, internal_error.BadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionEntity := tt.auction
+			auctionEntity.Id = uuid.New().String()
+			auctionEntity.Format = auction_entity.English
+
+			proxyBidRepository := &fakeProxyBidRepository{}
+			bidUseCase := NewBidUseCase(&fakeBidRepository{},
+				&fakeAuctionRepository{auctions: map[string]*auction_entity.Auction{auctionEntity.Id: &auctionEntity}},
+				proxyBidRepository, &fakeUserRepository{}, newFakeBidLog(), testOptions).(*BidUseCase)
+			defer bidUseCase.Shutdown(context.Background())
+
+			err := bidUseCase.CreateProxyBid(context.Background(), ProxyBidInputDTO{
+				UserId: uuid.New().String(), AuctionId: auctionEntity.Id, MaxAmount: tt.maxAmount,
+			})
+
+			if tt.expected == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				if len(proxyBidRepository.proxyBids) != 1 {
+					t.Errorf("Expected the proxy bid to be stored, got %+v", proxyBidRepository.proxyBids)
+				}
+				return
+			}
+
+			if err == nil || err.Code != tt.expected {
+				t.Fatalf("Expected a %s error, got %v", tt.expected, err)
+			}
+			if len(proxyBidRepository.proxyBids) != 0 {
+				t.Errorf("Expected no proxy bid to be stored, got %+v", proxyBidRepository.proxyBids)
+			}
+		})
+	}
+}
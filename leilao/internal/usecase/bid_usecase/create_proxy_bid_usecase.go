@@ -0,0 +1,151 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/proxy_bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type ProxyBidInputDTO struct {
+	UserId    string  `json:"-"`
+	AuctionId string  `json:"auction_id"`
+	MaxAmount float64 `json:"max_amount"`
+}
+
+func (bu *BidUseCase) CreateProxyBid(
+	ctx context.Context,
+	proxyBidInputDTO ProxyBidInputDTO) *internal_error.InternalError {
+	proxyBidEntity, err := proxy_bid_entity.CreateProxyBid(
+		proxyBidInputDTO.UserId, proxyBidInputDTO.AuctionId, proxyBidInputDTO.MaxAmount)
+	if err != nil {
+		return err
+	}
+
+	if err := bu.validateBidder(ctx, proxyBidEntity.UserId); err != nil {
+		return err
+	}
+
+	auctionEntity, err := bu.AuctionRepository.FindAuctionById(ctx, proxyBidEntity.AuctionId)
+	if err != nil {
+		return err
+	}
+
+	if auctionEntity.Format != auction_entity.English {
+		return internal_error.NewConflictError("Proxy bids are only supported on english auctions")
+	}
+
+	now := time.Now()
+	if auctionEntity.Status != auction_entity.Active || now.After(auctionEntity.EndTime) {
+		return internal_error.NewBadRequestError("Auction is not open for bids")
+	}
+
+	if proxyBidEntity.MaxAmount < auctionEntity.AskingPrice(now) {
+		return internal_error.NewBadRequestError("MaxAmount is below the asking price of the auction")
+	}
+
+	if err := bu.ProxyBidRepository.CreateProxyBid(ctx, proxyBidEntity); err != nil {
+		return err
+	}
+
+	automaticBids := bu.resolveProxyBids(ctx, proxyBidEntity.AuctionId)
+
+	if bu.hasOutbidListeners() {
+		bu.emitOutbid(ctx, proxyBidEntity.AuctionId, auctionEntity.HighestBidderId, automaticBids)
+	}
+
+	return nil
+}
+
+// resolveProxyBids places the automatic bids the registered proxies need to
+// answer the current highest bid of an auction and returns the ones stored.
+// Resolutions are serialized so the batch routine and new proxies never bid on
+// stale state.
+func (bu *BidUseCase) resolveProxyBids(ctx context.Context, auctionId string) []bid_entity.Bid {
+	ctx, span := tracer.Start(ctx, "BidUseCase.resolveProxyBids",
+		trace.WithAttributes(attribute.String("auction.id", auctionId)))
+	defer span.End()
+
+	bu.proxyBidMutex.Lock()
+	defer bu.proxyBidMutex.Unlock()
+
+	proxyBids, err := bu.ProxyBidRepository.FindProxyBidsByAuctionId(ctx, auctionId)
+	if err != nil {
+		logger.ErrorContext(ctx, "error trying to find proxy bids", err)
+		return nil
+	}
+
+	proxyBids, err = bu.activeProxyBids(ctx, proxyBids)
+	if err != nil {
+		logger.ErrorContext(ctx, "error trying to find the owners of proxy bids", err)
+		return nil
+	}
+
+	if len(proxyBids) == 0 {
+		return nil
+	}
+
+	highestBid, err := bu.BidRepository.FindWinningBidByAuctionId(ctx, auctionId)
+	if err != nil && err.Code != internal_error.NotFound {
+		logger.ErrorContext(ctx, "error trying to find the highest bid", err)
+		return nil
+	}
+
+	automaticBids := proxy_bid_entity.ResolveProxyBids(highestBid, proxyBids, bu.minBidIncrement)
+	if len(automaticBids) == 0 {
+		return nil
+	}
+
+	failures, err := bu.BidRepository.CreateBid(ctx, automaticBids)
+	if err != nil {
+		logger.ErrorContext(ctx, "error trying to create automatic bids", err)
+		return nil
+	}
+
+	stored := storedBids(automaticBids, failures)
+	logger.InfoContext(ctx, "Automatic bids placed",
+		zap.String("auction_id", auctionId),
+		zap.Int("count", len(stored)))
+
+	return stored
+}
+
+// activeProxyBids drops the proxies of users that no longer exist or were
+// deactivated, which validateBidder would refuse as manual bids.
+func (bu *BidUseCase) activeProxyBids(
+	ctx context.Context, proxyBids []proxy_bid_entity.ProxyBid) ([]proxy_bid_entity.ProxyBid, *internal_error.InternalError) {
+	activeUsers := make(map[string]bool)
+
+	var active []proxy_bid_entity.ProxyBid
+	for _, proxyBid := range proxyBids {
+		isActive, checked := activeUsers[proxyBid.UserId]
+		if !checked {
+			userEntity, err := bu.UserRepository.FindUserById(ctx, proxyBid.UserId)
+			if err != nil && err.Code != internal_error.NotFound {
+				return nil, err
+			}
+
+			isActive = err == nil && userEntity.Active
+			activeUsers[proxyBid.UserId] = isActive
+
+			if !isActive {
+				logger.InfoContext(ctx, "Skipping proxy bids of a missing or deactivated user",
+					zap.String("auction_id", proxyBid.AuctionId),
+					zap.String("user_id", proxyBid.UserId))
+			}
+		}
+
+		if isActive {
+			active = append(active, proxyBid)
+		}
+	}
+
+	return active, nil
+}
@@ -2,32 +2,108 @@ package bid_usecase
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity/bid_entity"
 	"fullcycle-auction_go/internal/internal_error"
 )
 
+// FindBidByAuctionId pages through the bid history of the auction, newest
+// first unless order=asc.
 func (bu *BidUseCase) FindBidByAuctionId(
-	ctx context.Context, auctionId string) ([]BidOutputDTO, *internal_error.InternalError) {
-	bidList, err := bu.BidRepository.FindBidByAuctionId(ctx, auctionId)
+	ctx context.Context,
+	auctionId string, filterInput BidFilterInputDTO) (*BidPageOutputDTO, *internal_error.InternalError) {
+	if err := bu.checkBidsRevealed(ctx, auctionId); err != nil {
+		return nil, err
+	}
+
+	return bu.findBids(ctx, bid_entity.BidFilter{AuctionId: auctionId}, filterInput)
+}
+
+// FindBidsByUserId pages through every bid placed by the user.
+func (bu *BidUseCase) FindBidsByUserId(
+	ctx context.Context,
+	userId string, filterInput BidFilterInputDTO) (*BidPageOutputDTO, *internal_error.InternalError) {
+	return bu.findBids(ctx, bid_entity.BidFilter{UserId: userId}, filterInput)
+}
+
+func (bu *BidUseCase) findBids(
+	ctx context.Context,
+	filter bid_entity.BidFilter, filterInput BidFilterInputDTO) (*BidPageOutputDTO, *internal_error.InternalError) {
+	filter.SortDescending = filterInput.Order != "asc"
+	filter.Limit = filterInput.Limit
+	filter.Cursor = filterInput.Cursor
+
+	bidPage, err := bu.BidRepository.FindBids(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
-	var bidOutputList []BidOutputDTO
-	for _, bid := range bidList {
+	userNames, err := bu.findUserNames(ctx, bidPage.Bids)
+	if err != nil {
+		return nil, err
+	}
+
+	bidOutputList := make([]BidOutputDTO, 0, len(bidPage.Bids))
+	for _, bid := range bidPage.Bids {
 		bidOutputList = append(bidOutputList, BidOutputDTO{
 			Id:        bid.Id,
 			UserId:    bid.UserId,
+			UserName:  userNames[bid.UserId],
 			AuctionId: bid.AuctionId,
 			Amount:    bid.Amount,
+			Automatic: bid.Automatic,
 			Timestamp: bid.Timestamp,
 		})
 	}
 
-	return bidOutputList, nil
+	stats := BidStatsOutputDTO{
+		Count:         bidPage.Stats.Count,
+		Bidders:       bidPage.Stats.Bidders,
+		Auctions:      bidPage.Stats.Auctions,
+		HighestAmount: bidPage.Stats.HighestAmount,
+		AverageAmount: bidPage.Stats.AverageAmount,
+	}
+	if bidPage.Stats.Count > 0 {
+		stats.FirstBidAt = &bidPage.Stats.FirstBidAt
+		stats.LastBidAt = &bidPage.Stats.LastBidAt
+	}
+
+	return &BidPageOutputDTO{
+		Items:      bidOutputList,
+		Stats:      stats,
+		NextCursor: bidPage.NextCursor,
+	}, nil
+}
+
+// findUserNames looks up the display name of each bidder of the page once.
+// Bidders that no longer exist are left without a name.
+func (bu *BidUseCase) findUserNames(
+	ctx context.Context, bids []bid_entity.Bid) (map[string]string, *internal_error.InternalError) {
+	userNames := make(map[string]string)
+	for _, bid := range bids {
+		if _, ok := userNames[bid.UserId]; ok {
+			continue
+		}
+
+		userEntity, err := bu.UserRepository.FindUserById(ctx, bid.UserId)
+		if err != nil {
+			if err.Code != internal_error.NotFound {
+				return nil, err
+			}
+			userNames[bid.UserId] = ""
+			continue
+		}
+		userNames[bid.UserId] = userEntity.Name
+	}
+
+	return userNames, nil
 }
 
 func (bu *BidUseCase) FindWinningBidByAuctionId(
 	ctx context.Context, auctionId string) (*BidOutputDTO, *internal_error.InternalError) {
+	if err := bu.checkBidsRevealed(ctx, auctionId); err != nil {
+		return nil, err
+	}
+
 	bidEntity, err := bu.BidRepository.FindWinningBidByAuctionId(ctx, auctionId)
 	if err != nil {
 		return nil, err
@@ -38,8 +114,25 @@ func (bu *BidUseCase) FindWinningBidByAuctionId(
 		UserId:    bidEntity.UserId,
 		AuctionId: bidEntity.AuctionId,
 		Amount:    bidEntity.Amount,
+		Automatic: bidEntity.Automatic,
 		Timestamp: bidEntity.Timestamp,
 	}
 
 	return bidOutput, nil
 }
+
+// checkBidsRevealed refuses to list the bids of a sealed auction before it
+// ends.
+func (bu *BidUseCase) checkBidsRevealed(
+	ctx context.Context, auctionId string) *internal_error.InternalError {
+	auctionEntity, err := bu.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	if auctionEntity.BidsSealed() {
+		return internal_error.NewConflictError("Bids of sealed auctions are revealed when the auction ends")
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestFindBidsOfSealedAuctionsOnlyOnceEnded(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   auction_entity.AuctionFormat
+		status   auction_entity.AuctionStatus
+		expected internal_error.ErrorCode
+	}{
+		{"active english", auction_entity.English, auction_entity.Active, ""},
+		{"active sealed first price", auction_entity.SealedFirstPrice, auction_entity.Active, internal_error.Conflict},
+		{"active sealed second price", auction_entity.SealedSecondPrice, auction_entity.Active, internal_error.Conflict},
+		{"completed sealed", auction_entity.SealedFirstPrice, auction_entity.Completed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionId := uuid.New().String()
+			bidRepository := &fakeBidRepository{bids: []bid_entity.Bid{
+				{Id: uuid.New().String(), AuctionId: auctionId, UserId: "alice", Amount: 10},
+			}}
+			bidUseCase := NewBidUseCase(bidRepository,
+				&fakeAuctionRepository{auctions: map[string]*auction_entity.Auction{
+					auctionId: {Id: auctionId, Format: tt.format, Status: tt.status},
+				}},
+				&fakeProxyBidRepository{}, &fakeUserRepository{}, newFakeBidLog(), testOptions).(*BidUseCase)
+			defer bidUseCase.Shutdown(context.Background())
+
+			bidPage, err := bidUseCase.FindBidByAuctionId(context.Background(), auctionId, BidFilterInputDTO{})
+			if tt.expected == "" {
+				if err != nil || len(bidPage.Items) != 1 {
+					t.Errorf("Expected the bids to be listed, got %+v and %v", bidPage, err)
+				}
+			} else if err == nil || err.Code != tt.expected {
+				t.Errorf("Expected a %s error listing the bids, got %v", tt.expected, err)
+			}
+
+			_, err = bidUseCase.FindWinningBidByAuctionId(context.Background(), auctionId)
+			if tt.expected == "" {
+				if err == nil || err.Code != internal_error.NotFound {
+					t.Errorf("Expected the winning bid lookup to reach the repository, got %v", err)
+				}
+			} else if err == nil || err.Code != tt.expected {
+				t.Errorf("Expected a %s error finding the winning bid, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	bidUseCase := NewBidUseCase(&fakeBidRepository{}, &fakeAuctionRepository{},
+		&fakeProxyBidRepository{}, &fakeUserRepository{}, newFakeBidLog(), testOptions).(*BidUseCase)
+	defer bidUseCase.Shutdown(context.Background())
+
+	if _, err := bidUseCase.FindBidByAuctionId(
+		context.Background(), uuid.New().String(), BidFilterInputDTO{}); err == nil || err.Code != internal_error.NotFound {
+		t.Errorf("Expected the bids of a missing auction to be not found, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"sort"
+)
+
+// OnOutbid registers a listener called for every user that lost the lead of
+// an english auction. Listeners run on the routine storing the bids and must
+// not block.
+func (bu *BidUseCase) OnOutbid(listener func(event bid_entity.OutbidEvent)) {
+	bu.outbidListenersMutex.Lock()
+	defer bu.outbidListenersMutex.Unlock()
+
+	bu.outbidListeners = append(bu.outbidListeners, listener)
+}
+
+func (bu *BidUseCase) hasOutbidListeners() bool {
+	bu.outbidListenersMutex.RLock()
+	defer bu.outbidListenersMutex.RUnlock()
+
+	return len(bu.outbidListeners) > 0
+}
+
+// findLeader returns the highest bidder of an english auction, or an empty
+// string when it has none or bidders must not learn they were outbid.
+func (bu *BidUseCase) findLeader(ctx context.Context, auctionId string) string {
+	auctionEntity, err := bu.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		logger.ErrorContext(ctx, "error trying to find the auction leader", err)
+		return ""
+	}
+
+	if auctionEntity.Format != auction_entity.English {
+		return ""
+	}
+
+	return auctionEntity.HighestBidderId
+}
+
+// emitOutbid tells the previous leader of an auction and the users whose bids
+// were just stored on it that they were outbid, unless they lead it now.
+func (bu *BidUseCase) emitOutbid(
+	ctx context.Context, auctionId, previousLeader string, storedBids []bid_entity.Bid) {
+	auctionEntity, err := bu.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		logger.ErrorContext(ctx, "error trying to find the auction leader", err)
+		return
+	}
+
+	if auctionEntity.Format != auction_entity.English || auctionEntity.HighestBidderId == "" {
+		return
+	}
+
+	candidates := make(map[string]bool)
+	if previousLeader != "" {
+		candidates[previousLeader] = true
+	}
+	for _, bid := range storedBids {
+		if bid.AuctionId == auctionId {
+			candidates[bid.UserId] = true
+		}
+	}
+	delete(candidates, auctionEntity.HighestBidderId)
+
+	userIds := make([]string, 0, len(candidates))
+	for userId := range candidates {
+		userIds = append(userIds, userId)
+	}
+	sort.Strings(userIds)
+
+	bu.outbidListenersMutex.RLock()
+	defer bu.outbidListenersMutex.RUnlock()
+
+	for _, userId := range userIds {
+		event := bid_entity.OutbidEvent{
+			AuctionId:    auctionId,
+			UserId:       userId,
+			LeadingBidId: auctionEntity.HighestBidId,
+			CurrentPrice: auctionEntity.CurrentPrice,
+		}
+
+		for _, listener := range bu.outbidListeners {
+			listener(event)
+		}
+	}
+}
+
+// storedBids returns the bids of a batch that are not among its failures.
+func storedBids(batch []bid_entity.Bid, failures []bid_entity.BidFailure) []bid_entity.Bid {
+	failed := make(map[int]bool, len(failures))
+	for _, failure := range failures {
+		failed[failure.Index] = true
+	}
+
+	stored := make([]bid_entity.Bid, 0, len(batch)-len(failed))
+	for index, bid := range batch {
+		if !failed[index] {
+			stored = append(stored, bid)
+		}
+	}
+
+	return stored
+}
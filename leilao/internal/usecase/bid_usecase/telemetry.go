@@ -0,0 +1,69 @@
+package bid_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "fullcycle-auction_go/internal/usecase/bid_usecase"
+
+var tracer = otel.Tracer(instrumentationName)
+
+// bidMetrics records the outcome of every processed batch. The instruments come
+// from the global meter provider, so they are no-ops until telemetry is set up.
+type bidMetrics struct {
+	accepted      metric.Int64Counter
+	rejected      metric.Int64Counter
+	batchSize     metric.Int64Histogram
+	flushDuration metric.Float64Histogram
+}
+
+func newBidMetrics() *bidMetrics {
+	meter := otel.Meter(instrumentationName)
+	metrics := &bidMetrics{}
+
+	var err error
+	if metrics.accepted, err = meter.Int64Counter("auction.bids.accepted",
+		metric.WithDescription("Bids stored by batch processing"),
+		metric.WithUnit("{bid}")); err != nil {
+		logger.Error("Error trying to create bids accepted counter", err)
+	}
+	if metrics.rejected, err = meter.Int64Counter("auction.bids.rejected",
+		metric.WithDescription("Bids not stored by batch processing, by reason"),
+		metric.WithUnit("{bid}")); err != nil {
+		logger.Error("Error trying to create bids rejected counter", err)
+	}
+	if metrics.batchSize, err = meter.Int64Histogram("auction.bid_batch.size",
+		metric.WithDescription("Bids per stored batch"),
+		metric.WithUnit("{bid}")); err != nil {
+		logger.Error("Error trying to create bid batch size histogram", err)
+	}
+	if metrics.flushDuration, err = meter.Float64Histogram("auction.bid_batch.duration",
+		metric.WithDescription("Time taken to store a batch of bids"),
+		metric.WithUnit("s")); err != nil {
+		logger.Error("Error trying to create bid batch duration histogram", err)
+	}
+
+	return metrics
+}
+
+// recordBatch counts the bids of a batch that were not reported as failures
+// as accepted, unless the whole batch failed to be stored.
+func (bm *bidMetrics) recordBatch(ctx context.Context,
+	batchSize int, duration time.Duration, failures []bid_entity.BidFailure, stored bool) {
+	bm.batchSize.Record(ctx, int64(batchSize))
+	bm.flushDuration.Record(ctx, duration.Seconds())
+	if stored {
+		bm.accepted.Add(ctx, int64(batchSize-len(failures)))
+	}
+
+	for _, failure := range failures {
+		bm.rejected.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", string(failure.Reason))))
+	}
+}
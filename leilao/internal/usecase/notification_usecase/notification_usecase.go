@@ -0,0 +1,429 @@
+package notification_usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/entity/watchlist_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 200
+	endingSoonPageSize       = 100
+)
+
+type WatchlistInputDTO struct {
+	AuctionId string `json:"auction_id" binding:"required,uuid"`
+}
+
+type WatchlistOutputDTO struct {
+	AuctionId string    `json:"auction_id"`
+	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+}
+
+type NotificationOutputDTO struct {
+	Id        string                               `json:"id"`
+	Type      notification_entity.NotificationType `json:"type"`
+	AuctionId string                               `json:"auction_id"`
+	Message   string                               `json:"message"`
+	Amount    float64                              `json:"amount,omitempty"`
+	Timestamp time.Time                            `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+}
+
+type NotificationUseCaseInterface interface {
+	AddToWatchlist(
+		ctx context.Context,
+		userId string, watchlistInput WatchlistInputDTO) *internal_error.InternalError
+
+	RemoveFromWatchlist(ctx context.Context, userId, auctionId string) *internal_error.InternalError
+
+	FindWatchlist(ctx context.Context, userId string) ([]WatchlistOutputDTO, *internal_error.InternalError)
+
+	FindNotifications(
+		ctx context.Context, userId string, limit int) ([]NotificationOutputDTO, *internal_error.InternalError)
+
+	NotifyOutbid(event bid_entity.OutbidEvent)
+
+	NotifyAuctionClosed(event settlement_entity.AuctionClosedEvent)
+
+	Start(ctx context.Context)
+
+	Shutdown(ctx context.Context) error
+}
+
+// NotificationUseCase records notifications and sends the new ones through
+// the configured channels. Events are queued and handled by a single worker,
+// so the bid batch and the settlement never wait on a slow channel; the
+// ending soon notifications come from a periodic scan of the active auctions.
+type NotificationUseCase struct {
+	auctionRepository      auction_entity.AuctionRepositoryInterface
+	bidRepository          bid_entity.BidEntityRepository
+	userRepository         user_entity.UserRepositoryInterface
+	watchlistRepository    watchlist_entity.WatchlistRepositoryInterface
+	notificationRepository notification_entity.NotificationRepositoryInterface
+	channels               []notification_entity.Channel
+
+	scanInterval     time.Duration
+	endingSoonWindow time.Duration
+
+	jobs      chan func(ctx context.Context)
+	jobsMutex *sync.RWMutex
+	closed    bool
+	stop      chan struct{}
+	stopped   *sync.WaitGroup
+}
+
+type Options struct {
+	// ScanInterval is how often auctions ending within EndingSoonWindow are
+	// looked for.
+	ScanInterval     time.Duration
+	EndingSoonWindow time.Duration
+	QueueSize        int
+}
+
+func NewNotificationUseCase(
+	auctionRepository auction_entity.AuctionRepositoryInterface,
+	bidRepository bid_entity.BidEntityRepository,
+	userRepository user_entity.UserRepositoryInterface,
+	watchlistRepository watchlist_entity.WatchlistRepositoryInterface,
+	notificationRepository notification_entity.NotificationRepositoryInterface,
+	channels []notification_entity.Channel,
+	options Options) *NotificationUseCase {
+	return &NotificationUseCase{
+		auctionRepository:      auctionRepository,
+		bidRepository:          bidRepository,
+		userRepository:         userRepository,
+		watchlistRepository:    watchlistRepository,
+		notificationRepository: notificationRepository,
+		channels:               channels,
+		scanInterval:           options.ScanInterval,
+		endingSoonWindow:       options.EndingSoonWindow,
+		jobs:                   make(chan func(ctx context.Context), options.QueueSize),
+		jobsMutex:              &sync.RWMutex{},
+		stop:                   make(chan struct{}),
+		stopped:                &sync.WaitGroup{},
+	}
+}
+
+func (nu *NotificationUseCase) AddToWatchlist(
+	ctx context.Context,
+	userId string, watchlistInput WatchlistInputDTO) *internal_error.InternalError {
+	if _, err := nu.auctionRepository.FindAuctionById(ctx, watchlistInput.AuctionId); err != nil {
+		return err
+	}
+
+	return nu.watchlistRepository.AddToWatchlist(
+		ctx, watchlist_entity.CreateWatchlistItem(userId, watchlistInput.AuctionId))
+}
+
+func (nu *NotificationUseCase) RemoveFromWatchlist(
+	ctx context.Context, userId, auctionId string) *internal_error.InternalError {
+	return nu.watchlistRepository.RemoveFromWatchlist(ctx, userId, auctionId)
+}
+
+func (nu *NotificationUseCase) FindWatchlist(
+	ctx context.Context, userId string) ([]WatchlistOutputDTO, *internal_error.InternalError) {
+	items, err := nu.watchlistRepository.FindWatchlistByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	watchlistOutputs := make([]WatchlistOutputDTO, 0, len(items))
+	for _, item := range items {
+		watchlistOutputs = append(watchlistOutputs, WatchlistOutputDTO{
+			AuctionId: item.AuctionId,
+			Timestamp: item.Timestamp,
+		})
+	}
+
+	return watchlistOutputs, nil
+}
+
+// FindNotifications returns the latest notifications of a user, 50 unless
+// another limit up to 200 is asked.
+func (nu *NotificationUseCase) FindNotifications(
+	ctx context.Context, userId string, limit int) ([]NotificationOutputDTO, *internal_error.InternalError) {
+	if limit <= 0 {
+		limit = defaultNotificationLimit
+	} else if limit > maxNotificationLimit {
+		limit = maxNotificationLimit
+	}
+
+	notifications, err := nu.notificationRepository.FindNotificationsByUserId(ctx, userId, int64(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	notificationOutputs := make([]NotificationOutputDTO, 0, len(notifications))
+	for _, notification := range notifications {
+		notificationOutputs = append(notificationOutputs, NotificationOutputDTO{
+			Id:        notification.Id,
+			Type:      notification.Type,
+			AuctionId: notification.AuctionId,
+			Message:   notification.Message,
+			Amount:    notification.Amount,
+			Timestamp: notification.Timestamp,
+		})
+	}
+
+	return notificationOutputs, nil
+}
+
+// NotifyOutbid is registered as a bid listener. The leading bid tells
+// successive outbids apart.
+func (nu *NotificationUseCase) NotifyOutbid(event bid_entity.OutbidEvent) {
+	nu.enqueue(func(ctx context.Context) {
+		ctx = logger.WithAuctionId(ctx, event.AuctionId)
+
+		nu.notify(ctx, notification_entity.CreateNotification(
+			notification_entity.Outbid, event.UserId, event.AuctionId, event.LeadingBidId,
+			fmt.Sprintf("Your bid on %s was outbid, the current price is %.2f",
+				nu.productName(ctx, event.AuctionId), event.CurrentPrice),
+			event.CurrentPrice))
+	})
+}
+
+// NotifyAuctionClosed is registered as a settlement listener. Every bidder
+// of the auction learns whether they won it.
+func (nu *NotificationUseCase) NotifyAuctionClosed(event settlement_entity.AuctionClosedEvent) {
+	if event.Outcome == settlement_entity.NoBids {
+		return
+	}
+
+	nu.enqueue(func(ctx context.Context) {
+		ctx = logger.WithAuctionId(ctx, event.AuctionId)
+
+		bidderIds, err := nu.findBidderIds(ctx, event.AuctionId)
+		if err != nil {
+			return
+		}
+
+		productName := nu.productName(ctx, event.AuctionId)
+		for _, bidderId := range bidderIds {
+			if event.Outcome == settlement_entity.Sold && bidderId == event.WinnerId {
+				nu.notify(ctx, notification_entity.CreateNotification(
+					notification_entity.AuctionWon, bidderId, event.AuctionId, "",
+					fmt.Sprintf("You won %s for %.2f", productName, event.FinalPrice),
+					event.FinalPrice))
+				continue
+			}
+
+			message := fmt.Sprintf("%s closed and your bid did not win", productName)
+			if event.Outcome == settlement_entity.ReserveNotMet {
+				message = fmt.Sprintf("%s closed without reaching the reserve price", productName)
+			}
+
+			nu.notify(ctx, notification_entity.CreateNotification(
+				notification_entity.AuctionLost, bidderId, event.AuctionId, "", message, event.FinalPrice))
+		}
+	})
+}
+
+// Start runs the delivery worker and the ending soon scan until Shutdown.
+func (nu *NotificationUseCase) Start(ctx context.Context) {
+	nu.stopped.Add(2)
+
+	go func() {
+		defer nu.stopped.Done()
+
+		for job := range nu.jobs {
+			job(ctx)
+		}
+	}()
+
+	go func() {
+		defer nu.stopped.Done()
+
+		ticker := time.NewTicker(nu.scanInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-nu.stop:
+				return
+			case <-ticker.C:
+				nu.notifyEndingSoon(ctx)
+			}
+		}
+	}()
+}
+
+// Shutdown stops the scan and sends the notifications already queued, or
+// gives up when ctx is done.
+func (nu *NotificationUseCase) Shutdown(ctx context.Context) error {
+	nu.jobsMutex.Lock()
+	if !nu.closed {
+		nu.closed = true
+		close(nu.stop)
+		close(nu.jobs)
+	}
+	nu.jobsMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		nu.stopped.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue drops the job when the queue is full rather than slowing down the
+// caller; the notification is lost, not the event that caused it.
+func (nu *NotificationUseCase) enqueue(job func(ctx context.Context)) {
+	nu.jobsMutex.RLock()
+	defer nu.jobsMutex.RUnlock()
+
+	if nu.closed {
+		return
+	}
+
+	select {
+	case nu.jobs <- job:
+	default:
+		logger.Info("Notification queue is full, dropping notification")
+	}
+}
+
+// notifyEndingSoon notifies the watchers and bidders of the active auctions
+// ending within the window, once per auction and user.
+func (nu *NotificationUseCase) notifyEndingSoon(ctx context.Context) {
+	status := auction_entity.Active
+	endingBefore := time.Now().Add(nu.endingSoonWindow)
+	filter := auction_entity.AuctionFilter{
+		Status:       &status,
+		EndingBefore: &endingBefore,
+		SortBy:       auction_entity.SortByEndTime,
+		Limit:        endingSoonPageSize,
+	}
+
+	for {
+		page, err := nu.auctionRepository.FindAuctions(ctx, filter)
+		if err != nil {
+			return
+		}
+
+		for _, auctionEntity := range page.Auctions {
+			nu.notifyAuctionEndingSoon(logger.WithAuctionId(ctx, auctionEntity.Id), &auctionEntity)
+		}
+
+		if page.NextCursor == "" {
+			return
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+func (nu *NotificationUseCase) notifyAuctionEndingSoon(
+	ctx context.Context, auctionEntity *auction_entity.Auction) {
+	watcherIds, err := nu.watchlistRepository.FindWatchersByAuctionId(ctx, auctionEntity.Id)
+	if err != nil {
+		return
+	}
+
+	bidderIds, err := nu.findBidderIds(ctx, auctionEntity.Id)
+	if err != nil {
+		return
+	}
+
+	// The leading bid of a sealed auction stays hidden from its watchers.
+	price := auctionEntity.AskingPrice(time.Now())
+	if auctionEntity.BidsSealed() {
+		price = 0
+	}
+
+	for _, userId := range distinct(append(watcherIds, bidderIds...)) {
+		nu.notify(ctx, notification_entity.CreateNotification(
+			notification_entity.EndingSoon, userId, auctionEntity.Id, "",
+			fmt.Sprintf("%s ends at %s", auctionEntity.ProductName,
+				auctionEntity.EndTime.UTC().Format("2006-01-02 15:04:05 UTC")),
+			price))
+	}
+}
+
+// notify records the notification and sends it only if it is new, so a
+// notification raised twice is delivered once.
+func (nu *NotificationUseCase) notify(ctx context.Context, notification *notification_entity.Notification) {
+	created, err := nu.notificationRepository.CreateNotification(ctx, notification)
+	if err != nil || !created {
+		return
+	}
+
+	userEntity, err := nu.userRepository.FindUserById(ctx, notification.UserId)
+	if err != nil {
+		return
+	}
+
+	if !userEntity.Active {
+		return
+	}
+
+	recipient := notification_entity.Recipient{
+		UserId: userEntity.Id,
+		Name:   userEntity.Name,
+		Email:  userEntity.Email,
+	}
+
+	for _, channel := range nu.channels {
+		if err := channel.Send(ctx, recipient, notification); err != nil {
+			logger.ErrorContext(ctx, "error trying to send notification", err,
+				zap.String("channel", channel.Name()),
+				zap.String("notification_id", notification.Id))
+		}
+	}
+}
+
+func (nu *NotificationUseCase) findBidderIds(
+	ctx context.Context, auctionId string) ([]string, *internal_error.InternalError) {
+	bids, err := nu.bidRepository.FindBidByAuctionId(ctx, auctionId)
+	if err != nil && err.Code != internal_error.NotFound {
+		return nil, err
+	}
+
+	bidderIds := make([]string, 0, len(bids))
+	for _, bid := range bids {
+		bidderIds = append(bidderIds, bid.UserId)
+	}
+
+	return distinct(bidderIds), nil
+}
+
+func (nu *NotificationUseCase) productName(ctx context.Context, auctionId string) string {
+	auctionEntity, err := nu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return "the auction"
+	}
+
+	return auctionEntity.ProductName
+}
+
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+
+	sort.Strings(unique)
+	return unique
+}
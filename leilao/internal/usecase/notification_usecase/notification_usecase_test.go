@@ -0,0 +1,335 @@
+package notification_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/bid_entity"
+	"fullcycle-auction_go/internal/entity/notification_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/entity/watchlist_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"testing"
+	"time"
+)
+
+type fakeAuctionRepository struct {
+	auction_entity.AuctionRepositoryInterface
+	auctions []auction_entity.Auction
+}
+
+func (f *fakeAuctionRepository) FindAuctionById(
+	ctx context.Context, id string) (*auction_entity.Auction, *internal_error.InternalError) {
+	for i := range f.auctions {
+		if f.auctions[i].Id == id {
+			return &f.auctions[i], nil
+		}
+	}
+	return nil, internal_error.NewNotFoundError("auction not found")
+}
+
+func (f *fakeAuctionRepository) FindAuctions(
+	ctx context.Context, filter auction_entity.AuctionFilter) (*auction_entity.AuctionPage, *internal_error.InternalError) {
+	return &auction_entity.AuctionPage{Auctions: f.auctions, Total: int64(len(f.auctions))}, nil
+}
+
+type fakeBidRepository struct {
+	bid_entity.BidEntityRepository
+	bids []bid_entity.Bid
+}
+
+func (f *fakeBidRepository) FindBidByAuctionId(
+	ctx context.Context, auctionId string) ([]bid_entity.Bid, *internal_error.InternalError) {
+	var bids []bid_entity.Bid
+	for _, bid := range f.bids {
+		if bid.AuctionId == auctionId {
+			bids = append(bids, bid)
+		}
+	}
+	if len(bids) == 0 {
+		return nil, internal_error.NewNotFoundError("bids not found")
+	}
+	return bids, nil
+}
+
+type fakeUserRepository struct {
+	user_entity.UserRepositoryInterface
+	deactivated map[string]bool
+}
+
+func (f *fakeUserRepository) FindUserById(
+	ctx context.Context, userId string) (*user_entity.User, *internal_error.InternalError) {
+	return &user_entity.User{
+		Id:     userId,
+		Name:   userId,
+		Email:  userId + "@example.com",
+		Active: !f.deactivated[userId],
+	}, nil
+}
+
+type fakeWatchlistRepository struct {
+	watchlist_entity.WatchlistRepositoryInterface
+	watchers []string
+}
+
+func (f *fakeWatchlistRepository) FindWatchersByAuctionId(
+	ctx context.Context, auctionId string) ([]string, *internal_error.InternalError) {
+	return f.watchers, nil
+}
+
+type fakeNotificationRepository struct {
+	notification_entity.NotificationRepositoryInterface
+	stored map[string]bool
+}
+
+func (f *fakeNotificationRepository) CreateNotification(
+	ctx context.Context, notification *notification_entity.Notification) (bool, *internal_error.InternalError) {
+	if f.stored[notification.Id] {
+		return false, nil
+	}
+	f.stored[notification.Id] = true
+	return true, nil
+}
+
+type fakeChannel struct {
+	sent []sentNotification
+}
+
+type sentNotification struct {
+	userId       string
+	notification *notification_entity.Notification
+}
+
+func (f *fakeChannel) Name() string {
+	return "fake"
+}
+
+func (f *fakeChannel) Send(
+	ctx context.Context,
+	recipient notification_entity.Recipient, notification *notification_entity.Notification) error {
+	f.sent = append(f.sent, sentNotification{userId: recipient.UserId, notification: notification})
+	return nil
+}
+
+type testRepositories struct {
+	auctions  *fakeAuctionRepository
+	bids      *fakeBidRepository
+	users     *fakeUserRepository
+	watchlist *fakeWatchlistRepository
+	channel   *fakeChannel
+}
+
+func newTestUseCase(queueSize int) (*NotificationUseCase, *testRepositories) {
+	repositories := &testRepositories{
+		auctions: &fakeAuctionRepository{
+			auctions: []auction_entity.Auction{{
+				Id:           "auction",
+				ProductName:  "Guitar",
+				Format:       auction_entity.English,
+				EndTime:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+				CurrentPrice: 150,
+			}},
+		},
+		bids:      &fakeBidRepository{},
+		users:     &fakeUserRepository{deactivated: map[string]bool{}},
+		watchlist: &fakeWatchlistRepository{},
+		channel:   &fakeChannel{},
+	}
+
+	notificationUseCase := NewNotificationUseCase(
+		repositories.auctions, repositories.bids, repositories.users, repositories.watchlist,
+		&fakeNotificationRepository{stored: map[string]bool{}},
+		[]notification_entity.Channel{repositories.channel},
+		Options{ScanInterval: time.Hour, EndingSoonWindow: time.Hour, QueueSize: queueSize})
+
+	return notificationUseCase, repositories
+}
+
+// drain runs the queued jobs and waits for them.
+func drain(t *testing.T, notificationUseCase *NotificationUseCase) {
+	t.Helper()
+
+	notificationUseCase.Start(context.Background())
+	if err := notificationUseCase.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestNotifyOutbidIsDeliveredOnce(t *testing.T) {
+	notificationUseCase, repositories := newTestUseCase(10)
+
+	event := bid_entity.OutbidEvent{
+		AuctionId: "auction", UserId: "alice", LeadingBidId: "bid-2", CurrentPrice: 150,
+	}
+	notificationUseCase.NotifyOutbid(event)
+	notificationUseCase.NotifyOutbid(event)
+	notificationUseCase.NotifyOutbid(bid_entity.OutbidEvent{
+		AuctionId: "auction", UserId: "alice", LeadingBidId: "bid-3", CurrentPrice: 160,
+	})
+	drain(t, notificationUseCase)
+
+	if len(repositories.channel.sent) != 2 {
+		t.Fatalf("Expected the repeated outbid to be sent once, got %d notifications", len(repositories.channel.sent))
+	}
+
+	notification := repositories.channel.sent[0].notification
+	if notification.Type != notification_entity.Outbid ||
+		notification.Message != "Your bid on Guitar was outbid, the current price is 150.00" {
+		t.Errorf("Unexpected notification %+v", notification)
+	}
+}
+
+func TestNotifyAuctionClosed(t *testing.T) {
+	bids := []bid_entity.Bid{
+		{Id: "1", AuctionId: "auction", UserId: "alice", Amount: 100},
+		{Id: "2", AuctionId: "auction", UserId: "bob", Amount: 120},
+		{Id: "3", AuctionId: "auction", UserId: "alice", Amount: 130},
+	}
+
+	tests := []struct {
+		name     string
+		event    settlement_entity.AuctionClosedEvent
+		expected map[string]string
+	}{
+		{
+			name: "sold",
+			event: settlement_entity.AuctionClosedEvent{
+				AuctionId: "auction", Outcome: settlement_entity.Sold, WinnerId: "alice", FinalPrice: 130,
+			},
+			expected: map[string]string{
+				"alice": "You won Guitar for 130.00",
+				"bob":   "Guitar closed and your bid did not win",
+			},
+		},
+		{
+			name: "reserve not met",
+			event: settlement_entity.AuctionClosedEvent{
+				AuctionId: "auction", Outcome: settlement_entity.ReserveNotMet, FinalPrice: 130,
+			},
+			expected: map[string]string{
+				"alice": "Guitar closed without reaching the reserve price",
+				"bob":   "Guitar closed without reaching the reserve price",
+			},
+		},
+		{
+			name: "no bids",
+			event: settlement_entity.AuctionClosedEvent{
+				AuctionId: "auction", Outcome: settlement_entity.NoBids,
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notificationUseCase, repositories := newTestUseCase(10)
+			repositories.bids.bids = bids
+
+			notificationUseCase.NotifyAuctionClosed(tt.event)
+			drain(t, notificationUseCase)
+
+			if len(repositories.channel.sent) != len(tt.expected) {
+				t.Fatalf("Expected %d notifications, got %d", len(tt.expected), len(repositories.channel.sent))
+			}
+			for _, sent := range repositories.channel.sent {
+				if sent.notification.Message != tt.expected[sent.userId] {
+					t.Errorf("Expected %s to get %q, got %q",
+						sent.userId, tt.expected[sent.userId], sent.notification.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestNotifySkipsInactiveUsers(t *testing.T) {
+	notificationUseCase, repositories := newTestUseCase(10)
+	repositories.bids.bids = []bid_entity.Bid{
+		{Id: "1", AuctionId: "auction", UserId: "alice", Amount: 100},
+		{Id: "2", AuctionId: "auction", UserId: "bob", Amount: 120},
+	}
+	repositories.users.deactivated["alice"] = true
+
+	notificationUseCase.NotifyAuctionClosed(settlement_entity.AuctionClosedEvent{
+		AuctionId: "auction", Outcome: settlement_entity.Sold, WinnerId: "bob", FinalPrice: 120,
+	})
+	drain(t, notificationUseCase)
+
+	if len(repositories.channel.sent) != 1 || repositories.channel.sent[0].userId != "bob" {
+		t.Errorf("Expected only the active bidder to be notified, got %+v", repositories.channel.sent)
+	}
+}
+
+func TestEnqueueDropsJobsWhenTheQueueIsFull(t *testing.T) {
+	notificationUseCase, repositories := newTestUseCase(1)
+
+	for _, leadingBidId := range []string{"bid-1", "bid-2", "bid-3"} {
+		notificationUseCase.NotifyOutbid(bid_entity.OutbidEvent{
+			AuctionId: "auction", UserId: "alice", LeadingBidId: leadingBidId, CurrentPrice: 150,
+		})
+	}
+	if len(notificationUseCase.jobs) != 1 {
+		t.Fatalf("Expected the queue to hold a single job, got %d", len(notificationUseCase.jobs))
+	}
+	drain(t, notificationUseCase)
+
+	if len(repositories.channel.sent) != 1 {
+		t.Errorf("Expected only the queued notification to be sent, got %d", len(repositories.channel.sent))
+	}
+
+	notificationUseCase.NotifyOutbid(bid_entity.OutbidEvent{AuctionId: "auction", UserId: "alice"})
+	if len(notificationUseCase.jobs) != 0 {
+		t.Error("Expected no job to be queued after Shutdown")
+	}
+}
+
+func TestNotifyEndingSoonNotifiesWatchersAndBiddersOnce(t *testing.T) {
+	notificationUseCase, repositories := newTestUseCase(10)
+	repositories.watchlist.watchers = []string{"alice", "carol"}
+	repositories.bids.bids = []bid_entity.Bid{
+		{Id: "1", AuctionId: "auction", UserId: "alice", Amount: 100},
+		{Id: "2", AuctionId: "auction", UserId: "bob", Amount: 150},
+	}
+
+	notificationUseCase.notifyEndingSoon(context.Background())
+	notificationUseCase.notifyEndingSoon(context.Background())
+
+	var userIds []string
+	for _, sent := range repositories.channel.sent {
+		userIds = append(userIds, sent.userId)
+		if sent.notification.Message != "Guitar ends at 2026-01-02 15:04:05 UTC" {
+			t.Errorf("Unexpected message %q", sent.notification.Message)
+		}
+	}
+	if len(userIds) != 3 || userIds[0] != "alice" || userIds[1] != "bob" || userIds[2] != "carol" {
+		t.Errorf("Expected alice, bob and carol to be notified once, got %v", userIds)
+	}
+}
+
+func TestNotifyEndingSoonHidesTheLeadingBidOfSealedAuctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   auction_entity.AuctionFormat
+		expected float64
+	}{
+		{"english", auction_entity.English, 150},
+		{"sealed first price", auction_entity.SealedFirstPrice, 0},
+		{"sealed second price", auction_entity.SealedSecondPrice, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notificationUseCase, repositories := newTestUseCase(10)
+			repositories.auctions.auctions[0].Format = tt.format
+			repositories.watchlist.watchers = []string{"alice"}
+
+			notificationUseCase.notifyEndingSoon(context.Background())
+
+			if len(repositories.channel.sent) != 1 {
+				t.Fatalf("Expected one notification, got %d", len(repositories.channel.sent))
+			}
+			if amount := repositories.channel.sent[0].notification.Amount; amount != tt.expected {
+				t.Errorf("Expected the amount %.2f, got %.2f", tt.expected, amount)
+			}
+		})
+	}
+}
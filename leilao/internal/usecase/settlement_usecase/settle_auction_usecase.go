@@ -0,0 +1,237 @@
+package settlement_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/auction_entity"
+	"fullcycle-auction_go/internal/entity/settlement_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("fullcycle-auction_go/internal/usecase/settlement_usecase")
+
+type SettlementOutputDTO struct {
+	AuctionId    string                              `json:"auction_id"`
+	Outcome      settlement_entity.SettlementOutcome `json:"outcome"`
+	WinnerId     string                              `json:"winner_id,omitempty"`
+	WinningBidId string                              `json:"winning_bid_id,omitempty"`
+	FinalPrice   float64                             `json:"final_price"`
+	ReserveMet   bool                                `json:"reserve_met"`
+	BidCount     int64                               `json:"bid_count"`
+	ClosedAt     time.Time                           `json:"closed_at" time_format:"2006-01-02 15:04:05"`
+	SettledAt    time.Time                           `json:"settled_at" time_format:"2006-01-02 15:04:05"`
+}
+
+type SettlementFilterInputDTO struct {
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type WonAuctionOutputDTO struct {
+	AuctionId    string    `json:"auction_id"`
+	ProductName  string    `json:"product_name"`
+	Category     string    `json:"category"`
+	WinningBidId string    `json:"winning_bid_id"`
+	FinalPrice   float64   `json:"final_price"`
+	BidCount     int64     `json:"bid_count"`
+	ClosedAt     time.Time `json:"closed_at" time_format:"2006-01-02 15:04:05"`
+}
+
+type WonAuctionStatsOutputDTO struct {
+	Count        int64   `json:"count"`
+	TotalSpent   float64 `json:"total_spent"`
+	AveragePrice float64 `json:"average_price"`
+	HighestPrice float64 `json:"highest_price"`
+}
+
+type WonAuctionPageOutputDTO struct {
+	Items      []WonAuctionOutputDTO    `json:"items"`
+	Stats      WonAuctionStatsOutputDTO `json:"stats"`
+	NextCursor string                   `json:"next_cursor,omitempty"`
+}
+
+type SettlementUseCaseInterface interface {
+	SettleAuction(
+		ctx context.Context, auctionId string) (*SettlementOutputDTO, *internal_error.InternalError)
+
+	FindAuctionResult(
+		ctx context.Context, auctionId string) (*SettlementOutputDTO, *internal_error.InternalError)
+
+	FindAuctionsWonByUserId(
+		ctx context.Context,
+		userId string, filterInput SettlementFilterInputDTO) (*WonAuctionPageOutputDTO, *internal_error.InternalError)
+
+	OnAuctionClosed(listener func(event settlement_entity.AuctionClosedEvent))
+}
+
+type SettlementUseCase struct {
+	auctionRepositoryInterface    auction_entity.AuctionRepositoryInterface
+	settlementRepositoryInterface settlement_entity.SettlementRepositoryInterface
+	closedListeners               []func(event settlement_entity.AuctionClosedEvent)
+	closedListenersMutex          *sync.RWMutex
+}
+
+func NewSettlementUseCase(
+	auctionRepositoryInterface auction_entity.AuctionRepositoryInterface,
+	settlementRepositoryInterface settlement_entity.SettlementRepositoryInterface) SettlementUseCaseInterface {
+	return &SettlementUseCase{
+		auctionRepositoryInterface:    auctionRepositoryInterface,
+		settlementRepositoryInterface: settlementRepositoryInterface,
+		closedListenersMutex:          &sync.RWMutex{},
+	}
+}
+
+// SettleAuction records the result of a completed auction. Settling twice is
+// harmless: the stored settlement is returned and no event is emitted again.
+func (su *SettlementUseCase) SettleAuction(
+	ctx context.Context, auctionId string) (*SettlementOutputDTO, *internal_error.InternalError) {
+	ctx, span := tracer.Start(ctx, "SettlementUseCase.SettleAuction",
+		trace.WithAttributes(attribute.String("auction.id", auctionId)))
+	defer span.End()
+
+	settlement, err := su.settleAuction(ctx, auctionId)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("settlement.outcome", string(settlement.Outcome)))
+	return settlement, nil
+}
+
+func (su *SettlementUseCase) settleAuction(
+	ctx context.Context, auctionId string) (*SettlementOutputDTO, *internal_error.InternalError) {
+	auctionEntity, err := su.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	settlementEntity, err := settlement_entity.Settle(auctionEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := su.settlementRepositoryInterface.CreateSettlement(ctx, settlementEntity)
+	if err != nil {
+		return nil, err
+	}
+
+	if !created {
+		if settlementEntity, err = su.settlementRepositoryInterface.FindSettlementByAuctionId(
+			ctx, auctionId); err != nil {
+			return nil, err
+		}
+
+		return toSettlementOutputDTO(settlementEntity), nil
+	}
+
+	su.emitAuctionClosed(settlementEntity.ClosedEvent())
+
+	return toSettlementOutputDTO(settlementEntity), nil
+}
+
+// FindAuctionResult settles completed auctions on demand, which covers
+// auctions whose close was not observed by this process.
+func (su *SettlementUseCase) FindAuctionResult(
+	ctx context.Context, auctionId string) (*SettlementOutputDTO, *internal_error.InternalError) {
+	settlementEntity, err := su.settlementRepositoryInterface.FindSettlementByAuctionId(ctx, auctionId)
+	if err == nil {
+		return toSettlementOutputDTO(settlementEntity), nil
+	}
+	if err.Code != internal_error.NotFound {
+		return nil, err
+	}
+
+	auctionEntity, err := su.auctionRepositoryInterface.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auctionEntity.Status != auction_entity.Completed {
+		return nil, internal_error.NewNotFoundError(
+			"Auction result is only available after the auction is completed")
+	}
+
+	return su.SettleAuction(ctx, auctionId)
+}
+
+// FindAuctionsWonByUserId pages through the sold auctions won by the user,
+// latest closed first.
+func (su *SettlementUseCase) FindAuctionsWonByUserId(
+	ctx context.Context,
+	userId string, filterInput SettlementFilterInputDTO) (*WonAuctionPageOutputDTO, *internal_error.InternalError) {
+	settlementPage, err := su.settlementRepositoryInterface.FindSettlements(ctx, settlement_entity.SettlementFilter{
+		WinnerId: userId,
+		Limit:    filterInput.Limit,
+		Cursor:   filterInput.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wonAuctions := make([]WonAuctionOutputDTO, 0, len(settlementPage.Settlements))
+	for _, settlementEntity := range settlementPage.Settlements {
+		auctionEntity, err := su.auctionRepositoryInterface.FindAuctionById(ctx, settlementEntity.AuctionId)
+		if err != nil {
+			return nil, err
+		}
+
+		wonAuctions = append(wonAuctions, WonAuctionOutputDTO{
+			AuctionId:    settlementEntity.AuctionId,
+			ProductName:  auctionEntity.ProductName,
+			Category:     auctionEntity.Category,
+			WinningBidId: settlementEntity.WinningBidId,
+			FinalPrice:   settlementEntity.FinalPrice,
+			BidCount:     settlementEntity.BidCount,
+			ClosedAt:     settlementEntity.ClosedAt,
+		})
+	}
+
+	return &WonAuctionPageOutputDTO{
+		Items: wonAuctions,
+		Stats: WonAuctionStatsOutputDTO{
+			Count:        settlementPage.Stats.Count,
+			TotalSpent:   settlementPage.Stats.TotalPrice,
+			AveragePrice: settlementPage.Stats.AveragePrice,
+			HighestPrice: settlementPage.Stats.HighestPrice,
+		},
+		NextCursor: settlementPage.NextCursor,
+	}, nil
+}
+
+func (su *SettlementUseCase) OnAuctionClosed(listener func(event settlement_entity.AuctionClosedEvent)) {
+	su.closedListenersMutex.Lock()
+	defer su.closedListenersMutex.Unlock()
+
+	su.closedListeners = append(su.closedListeners, listener)
+}
+
+func (su *SettlementUseCase) emitAuctionClosed(event settlement_entity.AuctionClosedEvent) {
+	su.closedListenersMutex.RLock()
+	defer su.closedListenersMutex.RUnlock()
+
+	for _, listener := range su.closedListeners {
+		listener(event)
+	}
+}
+
+func toSettlementOutputDTO(settlementEntity *settlement_entity.Settlement) *SettlementOutputDTO {
+	return &SettlementOutputDTO{
+		AuctionId:    settlementEntity.AuctionId,
+		Outcome:      settlementEntity.Outcome,
+		WinnerId:     settlementEntity.WinnerId,
+		WinningBidId: settlementEntity.WinningBidId,
+		FinalPrice:   settlementEntity.FinalPrice,
+		ReserveMet:   settlementEntity.Outcome == settlement_entity.Sold,
+		BidCount:     settlementEntity.BidCount,
+		ClosedAt:     settlementEntity.ClosedAt,
+		SettledAt:    settlementEntity.SettledAt,
+	}
+}
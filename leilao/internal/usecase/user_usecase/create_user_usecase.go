@@ -0,0 +1,79 @@
+package user_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/user_entity"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+type UserInputDTO struct {
+	Name     string `json:"name" binding:"required,min=2,max=100"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Role     string `json:"role" binding:"omitempty,oneof=bidder seller admin"`
+}
+
+type UserUpdateInputDTO struct {
+	Name  string `json:"name" binding:"omitempty,min=2,max=100"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=bidder seller admin"`
+}
+
+func (u *UserUseCase) CreateUser(
+	ctx context.Context,
+	userInput UserInputDTO) (*UserOutputDTO, *internal_error.InternalError) {
+	userEntity, err := user_entity.CreateUser(
+		userInput.Name, userInput.Email, userInput.Password, user_entity.UserRole(userInput.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.UserRepository.CreateUser(ctx, userEntity); err != nil {
+		return nil, err
+	}
+
+	return &UserOutputDTO{
+		Id:        userEntity.Id,
+		Name:      userEntity.Name,
+		Email:     userEntity.Email,
+		Role:      string(userEntity.Role),
+		Active:    userEntity.Active,
+		Timestamp: userEntity.Timestamp,
+	}, nil
+}
+
+func (u *UserUseCase) UpdateUser(
+	ctx context.Context,
+	id string, userInput UserUpdateInputDTO) (*UserOutputDTO, *internal_error.InternalError) {
+	userEntity, err := u.UserRepository.FindUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !userEntity.Active {
+		return nil, internal_error.NewConflictError("User is deactivated")
+	}
+
+	if err := userEntity.Update(
+		userInput.Name, userInput.Email, user_entity.UserRole(userInput.Role)); err != nil {
+		return nil, err
+	}
+
+	if err := u.UserRepository.UpdateUser(ctx, userEntity); err != nil {
+		return nil, err
+	}
+
+	return &UserOutputDTO{
+		Id:        userEntity.Id,
+		Name:      userEntity.Name,
+		Email:     userEntity.Email,
+		Role:      string(userEntity.Role),
+		Active:    userEntity.Active,
+		Timestamp: userEntity.Timestamp,
+	}, nil
+}
+
+func (u *UserUseCase) DeactivateUser(
+	ctx context.Context, id string) *internal_error.InternalError {
+	return u.UserRepository.DeactivateUser(ctx, id)
+}
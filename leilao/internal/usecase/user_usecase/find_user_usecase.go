@@ -4,6 +4,7 @@ import (
 	"context"
 	"fullcycle-auction_go/internal/entity/user_entity"
 	"fullcycle-auction_go/internal/internal_error"
+	"time"
 )
 
 func NewUserUseCase(userRepository user_entity.UserRepositoryInterface) UserUseCaseInterface {
@@ -17,14 +18,33 @@ type UserUseCase struct {
 }
 
 type UserOutputDTO struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Active    bool      `json:"active"`
+	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
 }
 
 type UserUseCaseInterface interface {
 	FindUserById(
 		ctx context.Context,
 		id string) (*UserOutputDTO, *internal_error.InternalError)
+
+	FindUsers(
+		ctx context.Context,
+		onlyActive bool) ([]UserOutputDTO, *internal_error.InternalError)
+
+	CreateUser(
+		ctx context.Context,
+		userInput UserInputDTO) (*UserOutputDTO, *internal_error.InternalError)
+
+	UpdateUser(
+		ctx context.Context,
+		id string, userInput UserUpdateInputDTO) (*UserOutputDTO, *internal_error.InternalError)
+
+	DeactivateUser(
+		ctx context.Context, id string) *internal_error.InternalError
 }
 
 func (u *UserUseCase) FindUserById(
@@ -35,7 +55,33 @@ func (u *UserUseCase) FindUserById(
 	}
 
 	return &UserOutputDTO{
-		Id:   userEntity.Id,
-		Name: userEntity.Name,
+		Id:        userEntity.Id,
+		Name:      userEntity.Name,
+		Email:     userEntity.Email,
+		Role:      string(userEntity.Role),
+		Active:    userEntity.Active,
+		Timestamp: userEntity.Timestamp,
 	}, nil
 }
+
+func (u *UserUseCase) FindUsers(
+	ctx context.Context, onlyActive bool) ([]UserOutputDTO, *internal_error.InternalError) {
+	userEntities, err := u.UserRepository.FindUsers(ctx, onlyActive)
+	if err != nil {
+		return nil, err
+	}
+
+	var userOutputs []UserOutputDTO
+	for _, value := range userEntities {
+		userOutputs = append(userOutputs, UserOutputDTO{
+			Id:        value.Id,
+			Name:      value.Name,
+			Email:     value.Email,
+			Role:      string(value.Role),
+			Active:    value.Active,
+			Timestamp: value.Timestamp,
+		})
+	}
+
+	return userOutputs, nil
+}
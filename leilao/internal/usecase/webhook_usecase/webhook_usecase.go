@@ -0,0 +1,150 @@
+package webhook_usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity/event_entity"
+	"fullcycle-auction_go/internal/entity/webhook_entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+const deadLetterListLimit = 100
+
+type WebhookInputDTO struct {
+	Url    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=auction.created bid.accepted auction.closed"`
+}
+
+type WebhookOutputDTO struct {
+	Id        string    `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+}
+
+type DeliveryOutputDTO struct {
+	Id             string    `json:"id"`
+	WebhookId      string    `json:"webhook_id"`
+	EventId        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	LastError      string    `json:"last_error,omitempty"`
+	LastStatusCode int       `json:"last_status_code,omitempty"`
+	Timestamp      time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+}
+
+type WebhookUseCaseInterface interface {
+	CreateWebhook(
+		ctx context.Context, webhookInput WebhookInputDTO) (*WebhookOutputDTO, *internal_error.InternalError)
+
+	FindWebhooks(ctx context.Context) ([]WebhookOutputDTO, *internal_error.InternalError)
+
+	DeleteWebhook(ctx context.Context, webhookId string) *internal_error.InternalError
+
+	FindDeadLetters(ctx context.Context) ([]DeliveryOutputDTO, *internal_error.InternalError)
+
+	RetryDelivery(ctx context.Context, deliveryId string) *internal_error.InternalError
+}
+
+type WebhookUseCase struct {
+	subscriptionRepositoryInterface webhook_entity.SubscriptionRepositoryInterface
+	deliveryRepositoryInterface     webhook_entity.DeliveryRepositoryInterface
+}
+
+func NewWebhookUseCase(
+	subscriptionRepositoryInterface webhook_entity.SubscriptionRepositoryInterface,
+	deliveryRepositoryInterface webhook_entity.DeliveryRepositoryInterface) WebhookUseCaseInterface {
+	return &WebhookUseCase{
+		subscriptionRepositoryInterface: subscriptionRepositoryInterface,
+		deliveryRepositoryInterface:     deliveryRepositoryInterface,
+	}
+}
+
+// CreateWebhook is the only call that returns the signing secret.
+func (wu *WebhookUseCase) CreateWebhook(
+	ctx context.Context, webhookInput WebhookInputDTO) (*WebhookOutputDTO, *internal_error.InternalError) {
+	var eventTypes []event_entity.EventType
+	for _, eventType := range webhookInput.Events {
+		eventTypes = append(eventTypes, event_entity.EventType(eventType))
+	}
+
+	subscription, err := webhook_entity.CreateSubscription(webhookInput.Url, eventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wu.subscriptionRepositoryInterface.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	webhookOutput := toWebhookOutputDTO(*subscription)
+	webhookOutput.Secret = subscription.Secret
+	return &webhookOutput, nil
+}
+
+func (wu *WebhookUseCase) FindWebhooks(
+	ctx context.Context) ([]WebhookOutputDTO, *internal_error.InternalError) {
+	subscriptions, err := wu.subscriptionRepositoryInterface.FindSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookOutputs := []WebhookOutputDTO{}
+	for _, subscription := range subscriptions {
+		webhookOutputs = append(webhookOutputs, toWebhookOutputDTO(subscription))
+	}
+
+	return webhookOutputs, nil
+}
+
+func (wu *WebhookUseCase) DeleteWebhook(
+	ctx context.Context, webhookId string) *internal_error.InternalError {
+	return wu.subscriptionRepositoryInterface.DeleteSubscription(ctx, webhookId)
+}
+
+func (wu *WebhookUseCase) FindDeadLetters(
+	ctx context.Context) ([]DeliveryOutputDTO, *internal_error.InternalError) {
+	deliveries, err := wu.deliveryRepositoryInterface.FindDeliveriesByStatus(
+		ctx, webhook_entity.Dead, deadLetterListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryOutputs := []DeliveryOutputDTO{}
+	for _, delivery := range deliveries {
+		deliveryOutputs = append(deliveryOutputs, DeliveryOutputDTO{
+			Id:             delivery.Id,
+			WebhookId:      delivery.SubscriptionId,
+			EventId:        delivery.EventId,
+			EventType:      string(delivery.EventType),
+			Status:         string(delivery.Status),
+			Attempts:       delivery.Attempts,
+			LastError:      delivery.LastError,
+			LastStatusCode: delivery.LastStatusCode,
+			Timestamp:      delivery.Timestamp,
+		})
+	}
+
+	return deliveryOutputs, nil
+}
+
+func (wu *WebhookUseCase) RetryDelivery(
+	ctx context.Context, deliveryId string) *internal_error.InternalError {
+	return wu.deliveryRepositoryInterface.RetryDeadDelivery(ctx, deliveryId)
+}
+
+func toWebhookOutputDTO(subscription webhook_entity.Subscription) WebhookOutputDTO {
+	var events []string
+	for _, eventType := range subscription.EventTypes {
+		events = append(events, string(eventType))
+	}
+
+	return WebhookOutputDTO{
+		Id:        subscription.Id,
+		Url:       subscription.Url,
+		Events:    events,
+		Timestamp: subscription.Timestamp,
+	}
+}